@@ -1,7 +1,10 @@
 package adb
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -9,11 +12,16 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 // Manager encapsulates ADB operations and path configuration.
 type Manager struct {
 	Path string
+
+	rootMu    sync.Mutex
+	rootModes map[string]RootMethod // per-serial root file browsing mode
 }
 
 func NewManager(path string) *Manager {
@@ -61,17 +69,81 @@ func (m *Manager) ExecSerial(serial string, args ...string) (string, error) {
 	return m.Exec(args...)
 }
 
-// ExecFastboot runs a fastboot command.
+// fastbootQueryTimeout bounds fastboot commands that only query or reboot the
+// device. Given a serial that is not in fastboot, fastboot waits for it forever.
+const fastbootQueryTimeout = 30 * time.Second
+
+// fastbootdSwitchTimeout bounds "reboot fastboot", which does not return
+// until fastbootd has booted and re-enumerated.
+const fastbootdSwitchTimeout = 3 * time.Minute
+
+// fastbootTimeout returns the time limit for a fastboot command, or 0 for
+// commands such as flash or update that may legitimately run for minutes.
+func fastbootTimeout(args []string) time.Duration {
+	for i, a := range args {
+		if strings.HasPrefix(a, "-") {
+			continue
+		}
+		switch a {
+		case "devices", "getvar", "set_active", "reboot-bootloader", "continue":
+			return fastbootQueryTimeout
+		case "reboot":
+			if i+1 < len(args) && args[i+1] == "fastboot" {
+				return fastbootdSwitchTimeout
+			}
+			return fastbootQueryTimeout
+		case "oem":
+			if i+1 < len(args) && args[i+1] == "device-info" {
+				return fastbootQueryTimeout
+			}
+		}
+		return 0
+	}
+	return 0
+}
+
+// ExecFastboot runs a fastboot command. Query and reboot commands are killed
+// after fastbootQueryTimeout.
 func (m *Manager) ExecFastboot(serial string, args ...string) (string, error) {
+	ctx := context.Background()
+	if d := fastbootTimeout(args); d > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
+	return m.ExecFastbootContext(ctx, serial, args...)
+}
+
+// NotInFastbootError is returned for a command aimed at a serial that is not
+// listed by "fastboot devices", typically a device still in adb mode.
+type NotInFastbootError struct {
+	Serial string
+}
+
+func (e *NotInFastbootError) Error() string {
+	return e.Serial + ": device is not in fastboot mode"
+}
+
+// ExecFastbootContext runs a fastboot command that is killed when ctx is done.
+// With a serial, the device must be listed by "fastboot devices" first, so the
+// command cannot block waiting for a device that is in adb mode.
+func (m *Manager) ExecFastbootContext(ctx context.Context, serial string, args ...string) (string, error) {
 	// Fastboot may not be in the same directory as adb, so we look for it in the path.
 	bin, err := exec.LookPath("fastboot")
 	if err != nil {
 		return "", errors.New("fastboot executable not found in PATH")
 	}
 	if strings.TrimSpace(serial) != "" {
+		ok, err := m.InFastboot(serial)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			return "", &NotInFastbootError{Serial: serial}
+		}
 		args = append([]string{"-s", serial}, args...)
 	}
-	cmd := exec.Command(bin, args...)
+	cmd := exec.CommandContext(ctx, bin, args...)
 	cmd.Env = os.Environ()
 
 	// 在Windows下隐藏CMD窗口
@@ -80,6 +152,9 @@ func (m *Manager) ExecFastboot(serial string, args ...string) (string, error) {
 	}
 
 	out, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		return string(out), fmt.Errorf("fastboot %s: %w", strings.Join(args, " "), ctx.Err())
+	}
 	return string(out), err
 }
 
@@ -291,20 +366,21 @@ func (m *Manager) ListDir(serial, path string) ([]FileEntry, string, error) {
 	}
 	// Try a detailed listing first to obtain metadata (toybox/busybox compatible).
 	// Use -ll (long with nanoseconds) to get more precise time information.
-	out, err := m.ExecSerial(serial, "shell", "ls", "-llAp", "--", path)
+	// In root mode the listing runs through su (see ShellAs).
+	out, err := m.ShellAs(serial, false, "ls -llAp -- "+shellQuote(path))
 	if err != nil || strings.Contains(out, "Unknown option") || strings.Contains(out, "bad -") {
 		// Fallback to standard long format
-		out, err = m.ExecSerial(serial, "shell", "ls", "-lAp", "--", path)
+		out, err = m.ShellAs(serial, false, "ls -lAp -- "+shellQuote(path))
 	}
 	if err != nil || strings.Contains(out, "Unknown option") || strings.Contains(out, "bad -") {
-		out, err = m.ExecSerial(serial, "shell", "ls", "-lA", "--", path)
+		out, err = m.ShellAs(serial, false, "ls -lA -- "+shellQuote(path))
 	}
 
 	// Debug: log the actual command output for troubleshooting (disabled)
 	// log.Printf("[DEBUG] ADB ls command output for path %s:\n%s", path, out)
 	if err != nil {
 		// Final fallback: names only
-		out, err2 := m.ExecSerial(serial, "shell", "ls", "-1p", "--", path)
+		out, err2 := m.ShellAs(serial, false, "ls -1p -- "+shellQuote(path))
 		if err2 != nil {
 			return nil, out, err
 		}
@@ -447,44 +523,7 @@ func (m *Manager) GetProps(serial string) (map[string]string, string, error) {
 	if err != nil {
 		return nil, out, err
 	}
-	props := make(map[string]string)
-	re := regexp.MustCompile(`^\[([^\]]+)\]: \[([^\]]*)\]$`)
-	for _, ln := range strings.Split(out, "\n") {
-		ln = strings.TrimSpace(ln)
-		if ln == "" {
-			continue
-		}
-		mm := re.FindStringSubmatch(ln)
-		if len(mm) == 3 {
-			props[mm[1]] = mm[2]
-		}
-	}
-	return props, out, nil
-}
-
-// GetVarAll returns all fastboot variables as a map.
-func (m *Manager) GetVarAll(serial string) (map[string]string, string, error) {
-	out, err := m.ExecFastboot(serial, "getvar", "all")
-	if err != nil {
-		return nil, out, err
-	}
-	vars := make(map[string]string)
-	// Output is line-by-line, but multiline values are indented.
-	// Example:
-	// (bootloader) C:\fakepath\NON-HLOS.bin:raw data download...
-	// (bootloader) partition-type:modem:raw
-	// (bootloader) partition-size:modem: 0x0...
-	re := regexp.MustCompile(`^\(bootloader\) (.+?):(.*)`)
-	for _, line := range strings.Split(out, "\n") {
-		line = strings.TrimSpace(line)
-		match := re.FindStringSubmatch(line)
-		if len(match) == 3 {
-			key := strings.TrimSpace(match[1])
-			val := strings.TrimSpace(match[2])
-			vars[key] = val
-		}
-	}
-	return vars, out, nil
+	return parseGetprop(out), out, nil
 }
 
 // InstalledPackagesForUser returns installed package names for a specific user.
@@ -831,12 +870,77 @@ func (m *Manager) ExecSerialRaw(serial string, args ...string) ([]byte, error) {
 	return m.ExecRaw(args...)
 }
 
+// ExecSerialStdout runs adb with -s <serial> and returns stdout only, so that
+// binary output (images, file contents) is not mixed with stderr noise.
+// On failure the stderr text is folded into the returned error.
+func (m *Manager) ExecSerialStdout(serial string, args ...string) ([]byte, error) {
+	if strings.TrimSpace(serial) != "" {
+		args = append([]string{"-s", serial}, args...)
+	}
+	bin := m.Path
+	if bin == "" {
+		bin = "adb"
+	}
+	cmd := exec.Command(bin, args...)
+	cmd.Env = os.Environ()
+
+	// 在Windows下隐藏CMD窗口
+	if runtime.GOOS == "windows" {
+		hideWindowsWindow(cmd)
+	}
+
+	out, err := cmd.Output()
+	var ee *exec.ExitError
+	if errors.As(err, &ee) && len(ee.Stderr) > 0 {
+		err = errors.New(strings.TrimSpace(string(ee.Stderr)))
+	}
+	return out, err
+}
+
+// commandSerial prepares (but does not start) an adb command for a serial,
+// for callers that need to wire stdin/stdout themselves.
+func (m *Manager) commandSerial(ctx context.Context, serial string, args ...string) *exec.Cmd {
+	if strings.TrimSpace(serial) != "" {
+		args = append([]string{"-s", serial}, args...)
+	}
+	bin := m.Path
+	if bin == "" {
+		bin = "adb"
+	}
+	cmd := exec.CommandContext(ctx, bin, args...)
+	cmd.Env = os.Environ()
+
+	// 在Windows下隐藏CMD窗口
+	if runtime.GOOS == "windows" {
+		hideWindowsWindow(cmd)
+	}
+	return cmd
+}
+
+// StartSerial starts a long-running adb command for a serial (logcat, find, ...)
+// and returns its stdout pipe. Cancelling ctx kills the adb process; the caller
+// must drain the pipe and then call cmd.Wait.
+func (m *Manager) StartSerial(ctx context.Context, serial string, args ...string) (*exec.Cmd, io.ReadCloser, error) {
+	cmd := m.commandSerial(ctx, serial, args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, nil, err
+	}
+	return cmd, stdout, nil
+}
+
 // Push uploads one local file to a remote directory on the device.
 func (m *Manager) Push(serial, localPath, remoteDir string) (string, error) {
 	if strings.TrimSpace(localPath) == "" || strings.TrimSpace(remoteDir) == "" {
 		return "", errors.New("invalid push arguments")
 	}
 	// Ensure destination is treated as a directory by adb
+	if m.useSu(serial) {
+		return m.pushAsRoot(serial, localPath, strings.TrimSuffix(remoteDir, "/"))
+	}
 	if !strings.HasSuffix(remoteDir, "/") {
 		remoteDir += "/"
 	}
@@ -869,6 +973,9 @@ func (m *Manager) Pull(serial, remotePath, localDir string, preserve bool) (stri
 	if err := os.MkdirAll(localDir, 0o755); err != nil {
 		return "", err
 	}
+	if m.useSu(serial) {
+		return m.pullAsRoot(serial, remotePath, localDir, preserve)
+	}
 	args := []string{"pull"}
 	if preserve {
 		args = append(args, "-a")
@@ -939,7 +1046,7 @@ func (m *Manager) Delete(serial, remotePath string) (string, error) {
 	if strings.TrimSpace(remotePath) == "" {
 		return "", errors.New("invalid delete arguments")
 	}
-	return m.ExecSerial(serial, "shell", "rm", "-rf", remotePath)
+	return m.ShellAs(serial, false, "rm -rf -- "+shellQuote(remotePath))
 }
 
 // DeleteMultiple deletes multiple remote files or directories.
@@ -958,3 +1065,9 @@ func (m *Manager) DeleteMultiple(serial string, remotePaths []string) (string, e
 	}
 	return strings.Join(outs, "\n"), firstErr
 }
+
+// shellQuote wraps s in single quotes for the device shell so paths with
+// spaces or metacharacters survive "adb shell" argument joining.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
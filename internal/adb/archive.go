@@ -0,0 +1,315 @@
+package adb
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ArchiveFormat is a local archive type for archive transfers. The device side
+// always speaks plain tar; compression and zip conversion happen on the host.
+type ArchiveFormat string
+
+const (
+	ArchiveTar   ArchiveFormat = "tar"
+	ArchiveTarGz ArchiveFormat = "tar.gz"
+	ArchiveZip   ArchiveFormat = "zip"
+)
+
+// ArchiveFormatFor guesses the archive format from a local file name.
+func ArchiveFormatFor(name string) (ArchiveFormat, bool) {
+	lo := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lo, ".tar.gz"), strings.HasSuffix(lo, ".tgz"):
+		return ArchiveTarGz, true
+	case strings.HasSuffix(lo, ".tar"):
+		return ArchiveTar, true
+	case strings.HasSuffix(lo, ".zip"):
+		return ArchiveZip, true
+	}
+	return "", false
+}
+
+// RemoteSize returns the total size in bytes of the given remote paths using "du -k -s".
+// It is only an estimate used for progress reporting; 0 means unknown.
+func (m *Manager) RemoteSize(serial string, paths []string) int64 {
+	if len(paths) == 0 {
+		return 0
+	}
+	var quoted []string
+	for _, p := range paths {
+		quoted = append(quoted, shellQuote(p))
+	}
+	out, _ := m.ShellAs(serial, false, "du -k -s -- "+strings.Join(quoted, " ")+" 2>/dev/null")
+	var total int64
+	for _, ln := range strings.Split(out, "\n") {
+		if f := strings.Fields(ln); len(f) >= 2 {
+			kb, _ := strconv.ParseInt(f[0], 10, 64)
+			total += kb * 1024
+		}
+	}
+	return total
+}
+
+// countingReader reports the running byte count after every read.
+type countingReader struct {
+	r        io.Reader
+	n        int64
+	progress func(int64)
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	if c.progress != nil && n > 0 {
+		c.progress(c.n)
+	}
+	return n, err
+}
+
+// DownloadArchive streams "tar -c" of names (relative to dir) from the device
+// through "exec-out" and writes it to w as format, without buffering the archive
+// in memory. progress receives the number of tar bytes read so far.
+// Modification times and permission bits are kept in all formats.
+func (m *Manager) DownloadArchive(ctx context.Context, serial, dir string, names []string, format ArchiveFormat, w io.Writer, progress func(int64)) error {
+	if strings.TrimSpace(dir) == "" || len(names) == 0 {
+		return errors.New("invalid archive arguments")
+	}
+	var quoted []string
+	for _, n := range names {
+		quoted = append(quoted, shellQuote(n))
+	}
+	// exec-out has no separate stderr channel, so tar warnings must not reach the stream
+	script := "tar -cf - -C " + shellQuote(dir) + " -- " + strings.Join(quoted, " ") + " 2>/dev/null"
+	args := []string{"exec-out", script}
+	if m.useSu(serial) {
+		args = []string{"exec-out", "su", "-c", shellQuote(script)}
+	}
+	cmd, stdout, err := m.StartSerial(ctx, serial, args...)
+	if err != nil {
+		return err
+	}
+	src := &countingReader{r: stdout, progress: progress}
+	var werr error
+	switch format {
+	case ArchiveTarGz:
+		gz := gzip.NewWriter(w)
+		_, werr = io.Copy(gz, src)
+		if cerr := gz.Close(); werr == nil {
+			werr = cerr
+		}
+	case ArchiveZip:
+		werr = tarToZip(src, w)
+	default:
+		_, werr = io.Copy(w, src)
+	}
+	// Drain so adb is not blocked on a full pipe if conversion stopped early
+	_, _ = io.Copy(io.Discard, stdout)
+	err = cmd.Wait()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if werr != nil {
+		return werr
+	}
+	if src.n == 0 {
+		return fmt.Errorf("device returned an empty archive (tar missing or paths unreadable)")
+	}
+	return err
+}
+
+// tarToZip re-packs a tar stream as a zip archive, keeping names, modes and mtimes.
+func tarToZip(r io.Reader, w io.Writer) error {
+	tr := tar.NewReader(r)
+	zw := zip.NewWriter(w)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			zw.Close()
+			return err
+		}
+		switch hdr.Typeflag {
+		case tar.TypeReg, tar.TypeDir, tar.TypeSymlink:
+		default:
+			// Devices, fifos and hard links have no zip equivalent
+			continue
+		}
+		zh, err := zip.FileInfoHeader(hdr.FileInfo())
+		if err != nil {
+			zw.Close()
+			return err
+		}
+		zh.Name = strings.TrimPrefix(hdr.Name, "./")
+		if hdr.Typeflag == tar.TypeDir {
+			zh.Name = strings.TrimSuffix(zh.Name, "/") + "/"
+			zh.Method = zip.Store
+		} else if hdr.Typeflag == tar.TypeReg {
+			zh.Method = zip.Deflate
+		}
+		fw, err := zw.CreateHeader(zh)
+		if err != nil {
+			zw.Close()
+			return err
+		}
+		switch hdr.Typeflag {
+		case tar.TypeReg:
+			if _, err := io.Copy(fw, tr); err != nil {
+				zw.Close()
+				return err
+			}
+		case tar.TypeSymlink:
+			if _, err := io.WriteString(fw, hdr.Linkname); err != nil {
+				zw.Close()
+				return err
+			}
+		}
+	}
+	return zw.Close()
+}
+
+// UploadArchive streams a local .tar, .tar.gz or .zip archive into "tar -x" on the
+// device through "exec-in", extracting it into remoteDir. progress receives the
+// number of bytes of the local archive file read so far.
+func (m *Manager) UploadArchive(ctx context.Context, serial, localArchive, remoteDir string, progress func(int64)) (string, error) {
+	if strings.TrimSpace(localArchive) == "" || strings.TrimSpace(remoteDir) == "" {
+		return "", errors.New("invalid archive arguments")
+	}
+	format, ok := ArchiveFormatFor(localArchive)
+	if !ok {
+		return "", fmt.Errorf("unsupported archive: %s", path.Base(localArchive))
+	}
+	f, err := os.Open(localArchive)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	src := &countingReader{r: f, progress: progress}
+
+	// The device always receives plain tar
+	var tarStream io.Reader
+	switch format {
+	case ArchiveTarGz:
+		gz, err := gzip.NewReader(src)
+		if err != nil {
+			return "", err
+		}
+		defer gz.Close()
+		tarStream = gz
+	case ArchiveZip:
+		st, err := f.Stat()
+		if err != nil {
+			return "", err
+		}
+		zr, err := zip.NewReader(&readerAtCounter{f: f, progress: progress}, st.Size())
+		if err != nil {
+			return "", err
+		}
+		pr, pw := io.Pipe()
+		go func() {
+			pw.CloseWithError(zipToTar(zr, pw))
+		}()
+		defer pr.Close()
+		tarStream = pr
+	default:
+		tarStream = src
+	}
+
+	script := "mkdir -p " + shellQuote(remoteDir) + " && tar -xpf - -C " + shellQuote(remoteDir)
+	args := []string{"exec-in", script}
+	if m.useSu(serial) {
+		args = []string{"exec-in", "su", "-c", shellQuote(script)}
+	}
+	cmd := m.commandSerial(ctx, serial, args...)
+	cmd.Stdin = tarStream
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err = cmd.Run()
+	if ctx.Err() != nil {
+		return out.String(), ctx.Err()
+	}
+	return out.String(), err
+}
+
+// readerAtCounter reports the furthest offset read from a zip file as progress.
+type readerAtCounter struct {
+	f        *os.File
+	max      int64
+	progress func(int64)
+}
+
+func (r *readerAtCounter) ReadAt(p []byte, off int64) (int, error) {
+	n, err := r.f.ReadAt(p, off)
+	if end := off + int64(n); end > r.max {
+		r.max = end
+		if r.progress != nil {
+			r.progress(r.max)
+		}
+	}
+	return n, err
+}
+
+// zipToTar writes the entries of a zip archive as a tar stream, keeping modes and mtimes.
+func zipToTar(zr *zip.Reader, w io.Writer) error {
+	tw := tar.NewWriter(w)
+	for _, zf := range zr.File {
+		fi := zf.FileInfo()
+		var link string
+		if fi.Mode()&os.ModeSymlink != 0 {
+			rc, err := zf.Open()
+			if err != nil {
+				return err
+			}
+			b, err := io.ReadAll(io.LimitReader(rc, 4096))
+			rc.Close()
+			if err != nil {
+				return err
+			}
+			link = string(b)
+		}
+		hdr, err := tar.FileInfoHeader(fi, link)
+		if err != nil {
+			return err
+		}
+		hdr.Name = zf.Name
+		hdr.ModTime = zf.Modified
+		if fi.IsDir() {
+			hdr.Name = strings.TrimSuffix(hdr.Name, "/") + "/"
+		}
+		// Zips created on Windows carry no Unix mode bits
+		if hdr.Mode&0777 == 0 {
+			if fi.IsDir() {
+				hdr.Mode |= 0755
+			} else {
+				hdr.Mode |= 0644
+			}
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if fi.Mode().IsRegular() {
+			rc, err := zf.Open()
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(tw, rc)
+			rc.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return tw.Close()
+}
@@ -0,0 +1,117 @@
+package adb
+
+import (
+	"context"
+	"errors"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MagiskDir is where Magisk writes patched boot images.
+const MagiskDir = "/sdcard/Download"
+
+// MagiskPackage is the package name of the Magisk app (unless it was hidden).
+const MagiskPackage = "com.topjohnwu.magisk"
+
+// PatchedImage is a magisk_patched-*.img file on the device.
+type PatchedImage struct {
+	Path     string
+	Size     int64
+	Modified time.Time
+}
+
+// BootPartitionFor guesses the partition a boot image belongs to from its
+// file name.
+func BootPartitionFor(name string) string {
+	base := strings.ToLower(path.Base(strings.ReplaceAll(name, "\\", "/")))
+	switch {
+	case strings.Contains(base, "init_boot"):
+		return "init_boot"
+	case strings.Contains(base, "vendor_boot"):
+		return "vendor_boot"
+	}
+	return "boot"
+}
+
+// DeviceTime returns the device clock, which is what file times on the
+// device are compared against.
+func (m *Manager) DeviceTime(serial string) (time.Time, error) {
+	out, err := m.ShellAs(serial, false, "date +%s")
+	if err != nil {
+		return time.Time{}, err
+	}
+	sec, err := strconv.ParseInt(strings.TrimSpace(out), 10, 64)
+	if err != nil {
+		return time.Time{}, errors.New("unexpected date output: " + strings.TrimSpace(out))
+	}
+	return time.Unix(sec, 0), nil
+}
+
+// FindPatchedImages lists magisk_patched-*.img in dir modified at or after
+// since, newest first.
+func (m *Manager) FindPatchedImages(serial, dir string, since time.Time) ([]PatchedImage, error) {
+	script := "for f in " + shellQuote(strings.TrimSuffix(dir, "/")) + "/magisk_patched-*.img; do " +
+		`[ -f "$f" ] && stat -c '%Y %s %n' "$f"; done; true`
+	out, err := m.ShellAs(serial, false, script)
+	if err != nil {
+		return nil, err
+	}
+	var res []PatchedImage
+	for _, ln := range strings.Split(strings.ReplaceAll(out, "\r\n", "\n"), "\n") {
+		f := strings.SplitN(strings.TrimSpace(ln), " ", 3)
+		if len(f) != 3 {
+			continue
+		}
+		mtime, err1 := strconv.ParseInt(f[0], 10, 64)
+		size, err2 := strconv.ParseInt(f[1], 10, 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		img := PatchedImage{Path: f[2], Size: size, Modified: time.Unix(mtime, 0)}
+		if !img.Modified.Before(since) {
+			res = append(res, img)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Modified.After(res[j].Modified) })
+	return res, nil
+}
+
+// WaitPatchedImage polls dir until a patched image newer than since appears
+// and its size has stopped changing.
+func (m *Manager) WaitPatchedImage(ctx context.Context, serial, dir string, since time.Time) (PatchedImage, error) {
+	var last PatchedImage
+	for {
+		imgs, err := m.FindPatchedImages(serial, dir, since)
+		if err != nil {
+			return PatchedImage{}, err
+		}
+		if len(imgs) > 0 {
+			if imgs[0].Path == last.Path && imgs[0].Size == last.Size && imgs[0].Size > 0 {
+				return imgs[0], nil
+			}
+			last = imgs[0]
+		}
+		select {
+		case <-ctx.Done():
+			return PatchedImage{}, ctx.Err()
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
+
+// OpenMagisk starts the Magisk app on the device.
+func (m *Manager) OpenMagisk(serial string) (string, error) {
+	out, err := m.ShellAs(serial, false, "monkey -p "+MagiskPackage+" -c android.intent.category.LAUNCHER 1")
+	if err == nil && strings.Contains(out, "No activities found") {
+		err = errors.New("magisk app not found")
+	}
+	return out, err
+}
+
+// BootImage boots an image once without flashing it ("fastboot boot").
+func (m *Manager) BootImage(serial, image string) (string, error) {
+	return m.ExecFastboot(serial, "boot", image)
+}
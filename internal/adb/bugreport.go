@@ -0,0 +1,254 @@
+package adb
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BugreportMeta is stored as "<zip>.json" next to a captured bugreport.
+type BugreportMeta struct {
+	Serial      string    `json:"serial"`
+	Model       string    `json:"model"`
+	Fingerprint string    `json:"fingerprint"`
+	SDK         string    `json:"sdk"`
+	Captured    time.Time `json:"captured"`
+	Duration    string    `json:"duration"`
+	Size        int64     `json:"size"`
+}
+
+// CaptureBugreport runs "bugreportz -p", reporting progress from its
+// "PROGRESS:cur/max" lines, pulls the finished zip to localZip and writes the
+// metadata file next to it.
+func (m *Manager) CaptureBugreport(ctx context.Context, serial, localZip string, onProgress func(cur, max int)) (string, error) {
+	meta := BugreportMeta{Serial: serial, Captured: time.Now()}
+	if props, _, err := m.GetProps(serial); err == nil {
+		meta.Model = props["ro.product.model"]
+		meta.Fingerprint = props["ro.build.fingerprint"]
+		meta.SDK = props["ro.build.version.sdk"]
+	}
+
+	cmd, stdout, err := m.StartSerial(ctx, serial, "shell", "bugreportz", "-p")
+	if err != nil {
+		return "", err
+	}
+	var remote, failure string
+	var log strings.Builder
+	sc := bufio.NewScanner(stdout)
+	for sc.Scan() {
+		ln := strings.TrimSpace(sc.Text())
+		key, val, _ := strings.Cut(ln, ":")
+		switch key {
+		case "PROGRESS":
+			a, b, _ := strings.Cut(val, "/")
+			cur, _ := strconv.Atoi(a)
+			total, _ := strconv.Atoi(b)
+			if onProgress != nil {
+				onProgress(cur, total)
+			}
+		case "OK":
+			remote = val
+		case "FAIL":
+			failure = val
+		case "BEGIN":
+		default:
+			log.WriteString(ln + "\n")
+		}
+	}
+	werr := cmd.Wait()
+	if ctx.Err() != nil {
+		return log.String(), ctx.Err()
+	}
+	if failure != "" {
+		return log.String(), errors.New(failure)
+	}
+	if remote == "" {
+		if werr == nil {
+			werr = errors.New("bugreportz did not report a file (Android 7.0+ is required)")
+		}
+		return log.String(), werr
+	}
+
+	out, err := m.ExecSerial(serial, "pull", remote, localZip)
+	log.WriteString(out)
+	if err != nil {
+		return log.String(), err
+	}
+	meta.Duration = time.Since(meta.Captured).Round(time.Second).String()
+	if st, err := os.Stat(localZip); err == nil {
+		meta.Size = st.Size()
+	}
+	data, _ := json.MarshalIndent(meta, "", "  ")
+	if err := os.WriteFile(localZip+".json", data, 0o644); err != nil {
+		return log.String(), err
+	}
+	return log.String(), nil
+}
+
+// ReadBugreportMeta loads the metadata saved next to a bugreport zip.
+func ReadBugreportMeta(zipPath string) (BugreportMeta, error) {
+	var meta BugreportMeta
+	data, err := os.ReadFile(zipPath + ".json")
+	if err != nil {
+		return meta, err
+	}
+	err = json.Unmarshal(data, &meta)
+	return meta, err
+}
+
+// Bugreport section categories.
+const (
+	SectionDumpsys   = "dumpsys"
+	SectionLogcat    = "logcat"
+	SectionANR       = "anr"
+	SectionTombstone = "tombstone"
+	SectionOther     = "other"
+)
+
+// BugreportSection is one browsable part of a bugreport.
+type BugreportSection struct {
+	Title    string
+	Category string
+	Lines    []string
+}
+
+// Bugreport is a parsed bugreport zip.
+type Bugreport struct {
+	Path     string
+	Sections []BugreportSection
+}
+
+var (
+	sectionHeaderRe = regexp.MustCompile(`^------ (.+?) ------$`)
+	serviceHeaderRe = regexp.MustCompile(`^DUMP OF SERVICE (?:[A-Z]+ )?([^:]+):$`)
+)
+
+// OpenBugreport reads a bugreport zip from disk. The main text is split at its
+// "------ TITLE ------" and "DUMP OF SERVICE x:" headers, and ANR traces and
+// tombstones stored as separate files become their own sections.
+func OpenBugreport(zipPath string) (*Bugreport, error) {
+	zr, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	br := &Bugreport{Path: zipPath}
+
+	// main_entry.txt names the main text file; otherwise take the largest bugreport*.txt
+	var main *zip.File
+	mainName := ""
+	for _, f := range zr.File {
+		if f.Name == "main_entry.txt" {
+			if rc, err := f.Open(); err == nil {
+				b, _ := io.ReadAll(io.LimitReader(rc, 1024))
+				rc.Close()
+				mainName = strings.TrimSpace(string(b))
+			}
+		}
+	}
+	for _, f := range zr.File {
+		base := path.Base(f.Name)
+		if f.Name == mainName || (mainName == "" && strings.HasPrefix(base, "bugreport") && strings.HasSuffix(base, ".txt") &&
+			(main == nil || f.UncompressedSize64 > main.UncompressedSize64)) {
+			main = f
+		}
+	}
+	if main != nil {
+		if err := br.parseMain(main); err != nil {
+			return nil, err
+		}
+	}
+
+	var extra []BugreportSection
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		var cat string
+		switch {
+		case strings.Contains(f.Name, "/anr/"):
+			cat = SectionANR
+		case strings.Contains(f.Name, "/tombstones/"):
+			cat = SectionTombstone
+		default:
+			continue
+		}
+		lines, err := readZipLines(f)
+		if err != nil {
+			continue
+		}
+		extra = append(extra, BugreportSection{Title: f.Name, Category: cat, Lines: lines})
+	}
+	sort.Slice(extra, func(i, j int) bool { return extra[i].Title < extra[j].Title })
+	br.Sections = append(br.Sections, extra...)
+	if len(br.Sections) == 0 {
+		return nil, fmt.Errorf("%s does not look like a bugreport", path.Base(zipPath))
+	}
+	return br, nil
+}
+
+func readZipLines(f *zip.File) ([]string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	var lines []string
+	sc := bufio.NewScanner(rc)
+	sc.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+	return lines, sc.Err()
+}
+
+func (br *Bugreport) parseMain(f *zip.File) error {
+	lines, err := readZipLines(f)
+	if err != nil {
+		return err
+	}
+	cur := BugreportSection{Title: "header", Category: SectionOther}
+	flush := func() {
+		if len(cur.Lines) > 0 {
+			br.Sections = append(br.Sections, cur)
+		}
+	}
+	for _, ln := range lines {
+		if mm := sectionHeaderRe.FindStringSubmatch(ln); mm != nil && !strings.Contains(mm[1], "was the duration of") {
+			flush()
+			cur = BugreportSection{Title: mm[1], Category: sectionCategory(mm[1])}
+		} else if mm := serviceHeaderRe.FindStringSubmatch(ln); mm != nil {
+			flush()
+			cur = BugreportSection{Title: "dumpsys " + mm[1], Category: SectionDumpsys}
+		}
+		cur.Lines = append(cur.Lines, ln)
+	}
+	flush()
+	return nil
+}
+
+func sectionCategory(title string) string {
+	lo := strings.ToLower(title)
+	switch {
+	case strings.Contains(lo, "logcat"):
+		return SectionLogcat
+	case strings.Contains(lo, "anr") || strings.Contains(lo, "vm traces"):
+		return SectionANR
+	case strings.Contains(lo, "tombstone"):
+		return SectionTombstone
+	case strings.Contains(lo, "dumpsys"):
+		return SectionDumpsys
+	}
+	return SectionOther
+}
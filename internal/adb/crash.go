@@ -0,0 +1,292 @@
+package adb
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Crash kinds.
+const (
+	CrashJava   = "crash"
+	CrashNative = "native"
+	CrashANR    = "anr"
+)
+
+// maxCrashTrace bounds how much of a report (ANR traces can be huge) is kept.
+const maxCrashTrace = 64 * 1024
+
+// CrashReport is one crash or ANR occurrence on a device.
+type CrashReport struct {
+	Serial     string
+	Kind       string // CrashJava, CrashNative or CrashANR
+	Package    string
+	PID        int
+	Time       time.Time
+	AppVersion string // from the dropbox "Package:" header when available
+	Title      string // exception class, signal or ANR reason
+	Signature  string // stable hash of Title and the top frames, used for grouping
+	Trace      string
+}
+
+// Key identifies an occurrence so repeated scans and the two sources (crash
+// buffer and dropbox) do not count the same crash twice.
+func (r CrashReport) Key() string {
+	return r.Serial + "|" + strconv.Itoa(r.PID) + "|" + r.Signature
+}
+
+// CollectCrashes reads the crash log buffer and the dropbox app crash/ANR entries.
+func (m *Manager) CollectCrashes(serial string) ([]CrashReport, error) {
+	var res []CrashReport
+	var errs []string
+	now := time.Now()
+	if out, err := m.ExecSerial(serial, "logcat", "-b", "crash", "-d", "-v", "threadtime"); err == nil {
+		res = append(res, parseCrashBuffer(out, serial, now)...)
+	} else {
+		errs = append(errs, strings.TrimSpace(out))
+	}
+	for _, tag := range []string{"data_app_crash", "data_app_native_crash", "data_app_anr"} {
+		out, err := m.ExecSerial(serial, "shell", "dumpsys", "dropbox", "--print", tag)
+		if err != nil {
+			errs = append(errs, strings.TrimSpace(out))
+			continue
+		}
+		res = append(res, parseDropbox(out, serial)...)
+	}
+	if len(res) == 0 && len(errs) > 0 {
+		return nil, errors.New(strings.Join(errs, "\n"))
+	}
+	// Same crash from both sources: keep the first (crash buffer) but take the version from dropbox
+	seen := map[string]int{}
+	var uniq []CrashReport
+	for _, r := range res {
+		if i, ok := seen[r.Key()]; ok {
+			if uniq[i].AppVersion == "" {
+				uniq[i].AppVersion = r.AppVersion
+			}
+			continue
+		}
+		seen[r.Key()] = len(uniq)
+		uniq = append(uniq, r)
+	}
+	return uniq, nil
+}
+
+var (
+	javaExceptionRe = regexp.MustCompile(`^([\w$.]+(?:Exception|Error|Throwable)[\w$]*)(?::|$)`)
+	frameLocationRe = regexp.MustCompile(`\([^)]*\)$`)
+	nativeFrameRe   = regexp.MustCompile(`^#\d+\s+pc\s+[0-9a-f]+\s+(.*)$`)
+	nativePkgRe     = regexp.MustCompile(`>>> ([^ ]+) <<<`)
+	nativePidRe     = regexp.MustCompile(`^pid: (\d+), tid`)
+	signalRe        = regexp.MustCompile(`signal \d+ \(\w+\)`)
+	digitsRe        = regexp.MustCompile(`\d+`)
+)
+
+// parseCrashBuffer groups "logcat -b crash" lines into reports. A report starts at
+// "FATAL EXCEPTION" (Java) or the "*** *** ***" banner (native) or when the PID changes.
+func parseCrashBuffer(out, serial string, now time.Time) []CrashReport {
+	var res []CrashReport
+	var cur *CrashReport
+	var lines []string
+	flush := func() {
+		if cur != nil && len(lines) > 0 {
+			cur.Trace = strings.Join(lines, "\n")
+			finishCrash(cur)
+			if cur.Title != "" {
+				res = append(res, *cur)
+			}
+		}
+		cur, lines = nil, nil
+	}
+	for _, ln := range strings.Split(out, "\n") {
+		e := ParseThreadtime(ln)
+		if e.Level == 0 {
+			continue
+		}
+		msg := e.Message
+		starts := strings.HasPrefix(msg, "FATAL EXCEPTION") || strings.HasPrefix(msg, "*** *** ***")
+		if cur == nil || starts || e.PID != cur.PID {
+			flush()
+			kind := CrashJava
+			if e.Tag != "AndroidRuntime" {
+				kind = CrashNative
+			}
+			t, _ := time.ParseInLocation("2006-01-02 15:04:05.000", strconv.Itoa(now.Year())+"-"+e.Time, time.Local)
+			if t.After(now.Add(24 * time.Hour)) {
+				// Entries from December read in January
+				t = t.AddDate(-1, 0, 0)
+			}
+			cur = &CrashReport{Serial: serial, Kind: kind, PID: e.PID, Time: t}
+		}
+		lines = append(lines, msg)
+	}
+	flush()
+	return res
+}
+
+// parseDropbox parses "dumpsys dropbox --print" output: entries separated by a line
+// of '=', each with a "<date> <time> <tag> (...)" line, header fields, a blank line
+// and the body.
+func parseDropbox(out, serial string) []CrashReport {
+	var res []CrashReport
+	for _, block := range strings.Split(out, "========================================") {
+		block = strings.TrimLeft(block, "\r\n")
+		first, rest, _ := strings.Cut(block, "\n")
+		f := strings.Fields(first)
+		if len(f) < 3 {
+			continue
+		}
+		r := CrashReport{Serial: serial}
+		switch f[2] {
+		case "data_app_crash":
+			r.Kind = CrashJava
+		case "data_app_native_crash":
+			r.Kind = CrashNative
+		case "data_app_anr":
+			r.Kind = CrashANR
+		default:
+			continue
+		}
+		r.Time, _ = time.ParseInLocation("2006-01-02 15:04:05", f[0]+" "+f[1], time.Local)
+		header, body, _ := strings.Cut(strings.ReplaceAll(rest, "\r", ""), "\n\n")
+		for _, h := range strings.Split(header, "\n") {
+			k, v, ok := strings.Cut(h, ":")
+			if !ok {
+				continue
+			}
+			v = strings.TrimSpace(v)
+			switch k {
+			case "Process":
+				r.Package = strings.SplitN(v, ":", 2)[0]
+			case "PID":
+				r.PID, _ = strconv.Atoi(v)
+			case "Package":
+				// "com.foo v123 (1.2.3)"
+				if pf := strings.SplitN(v, " ", 2); len(pf) == 2 {
+					r.AppVersion = pf[1]
+				}
+			case "Subject":
+				if r.Kind == CrashANR {
+					r.Title = "ANR: " + v
+				}
+			}
+		}
+		r.Trace = header + "\n\n" + body
+		finishCrash(&r)
+		if r.Title != "" {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// finishCrash fills Package, Title and Signature from the trace.
+func finishCrash(r *CrashReport) {
+	if len(r.Trace) > maxCrashTrace {
+		r.Trace = r.Trace[:maxCrashTrace] + "\n…"
+	}
+	var frames []string
+	for _, ln := range strings.Split(r.Trace, "\n") {
+		t := strings.TrimSpace(ln)
+		switch {
+		case r.Package == "" && strings.HasPrefix(t, "Process: "):
+			// "Process: com.foo, PID: 1234"
+			r.Package = strings.SplitN(strings.TrimSuffix(strings.SplitN(t[len("Process: "):], ",", 2)[0], " "), ":", 2)[0]
+		case r.Package == "" && nativePkgRe.MatchString(t):
+			r.Package = strings.SplitN(nativePkgRe.FindStringSubmatch(t)[1], ":", 2)[0]
+			// The crash buffer logs tombstones under debuggerd's PID; use the crashed process
+			if mm := nativePidRe.FindStringSubmatch(t); mm != nil {
+				r.PID, _ = strconv.Atoi(mm[1])
+			}
+		case r.Title == "" && r.Kind == CrashJava && javaExceptionRe.MatchString(t):
+			r.Title = javaExceptionRe.FindStringSubmatch(t)[1]
+		case r.Title == "" && r.Kind == CrashNative && signalRe.MatchString(t):
+			r.Title = signalRe.FindString(t)
+		case strings.HasPrefix(t, "at ") && len(frames) < 5:
+			// Line numbers change between builds; group by method only
+			frames = append(frames, frameLocationRe.ReplaceAllString(t, ""))
+		case nativeFrameRe.MatchString(t) && len(frames) < 5:
+			frames = append(frames, nativeFrameRe.FindStringSubmatch(t)[1])
+		}
+	}
+	if r.Title == "" && r.Kind == CrashNative && len(frames) > 0 {
+		r.Title = "native crash"
+	}
+	if r.Kind == CrashANR {
+		if r.Title == "" {
+			r.Title = "ANR"
+		}
+		// Reasons embed timings and ids; keep only the shape of the message
+		frames = append(frames[:0], digitsRe.ReplaceAllString(r.Title, "#"))
+	}
+	h := fnv.New64a()
+	h.Write([]byte(r.Kind + "\n" + r.Package + "\n" + r.Title + "\n" + strings.Join(frames, "\n")))
+	r.Signature = fmt.Sprintf("%016x", h.Sum64())
+}
+
+// AppVersion returns "versionName (versionCode)" of an installed package.
+func (m *Manager) AppVersion(serial, pkg string) string {
+	out, _ := m.ExecSerial(serial, "shell", "dumpsys", "package", shellQuote(pkg))
+	var name, code string
+	for _, ln := range strings.Split(out, "\n") {
+		t := strings.TrimSpace(ln)
+		if name == "" && strings.HasPrefix(t, "versionName=") {
+			name = strings.TrimPrefix(t, "versionName=")
+		}
+		if code == "" && strings.HasPrefix(t, "versionCode=") {
+			if f := strings.Fields(strings.TrimPrefix(t, "versionCode=")); len(f) > 0 {
+				code = f[0]
+			}
+		}
+	}
+	if name == "" && code == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s (%s)", name, code)
+}
+
+// ExportCrashBundle writes a zip with the trace, the device properties and the app version.
+func (m *Manager) ExportCrashBundle(r CrashReport, zipPath string) error {
+	f, err := os.Create(zipPath)
+	if err != nil {
+		return err
+	}
+	zw := zip.NewWriter(f)
+	add := func(name, content string) error {
+		w, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write([]byte(content))
+		return err
+	}
+	version := r.AppVersion
+	if version == "" && r.Package != "" {
+		version = m.AppVersion(r.Serial, r.Package)
+	}
+	props, _ := m.ExecSerial(r.Serial, "shell", "getprop")
+	summary := fmt.Sprintf("Device: %s\nKind: %s\nPackage: %s\nVersion: %s\nPID: %d\nTime: %s\nTitle: %s\nSignature: %s\n",
+		r.Serial, r.Kind, r.Package, version, r.PID, r.Time.Format(time.RFC3339), r.Title, r.Signature)
+	for _, e := range []struct{ name, content string }{
+		{"summary.txt", summary},
+		{"trace.txt", r.Trace},
+		{"getprop.txt", props},
+	} {
+		if err = add(e.name, e.content); err != nil {
+			break
+		}
+	}
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,339 @@
+package adb
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// AndroidInfo holds the "require name=a|b" lines of android-info.txt.
+type AndroidInfo struct {
+	Requires map[string][]string
+}
+
+func parseAndroidInfo(data string) AndroidInfo {
+	info := AndroidInfo{Requires: make(map[string][]string)}
+	for _, ln := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		rest, ok := strings.CutPrefix(strings.TrimSpace(ln), "require ")
+		if !ok {
+			continue
+		}
+		k, v, ok := strings.Cut(rest, "=")
+		if !ok {
+			continue
+		}
+		info.Requires[strings.TrimSpace(k)] = strings.Split(strings.TrimSpace(v), "|")
+	}
+	return info
+}
+
+// FactoryImage is a Google-style factory image (bootloader, radio and an
+// image zip) or a plain update zip, ready to be flashed with fastboot.
+type FactoryImage struct {
+	Source     string
+	Bootloader string // local paths; empty when the image has none
+	Radio      string
+	Update     string // image-*.zip, or the source itself for an update zip
+	Info       AndroidInfo
+	tmpDir     string
+}
+
+// factoryPart reports which part of a factory image a file name is.
+func factoryPart(name string) string {
+	base := strings.ToLower(path.Base(name))
+	switch {
+	case strings.HasPrefix(base, "bootloader-") && strings.HasSuffix(base, ".img"):
+		return "bootloader"
+	case strings.HasPrefix(base, "radio-") && strings.HasSuffix(base, ".img"):
+		return "radio"
+	case strings.HasPrefix(base, "image-") && strings.HasSuffix(base, ".zip"):
+		return "image"
+	}
+	return ""
+}
+
+func (f *FactoryImage) set(part, p string) {
+	switch part {
+	case "bootloader":
+		f.Bootloader = p
+	case "radio":
+		f.Radio = p
+	case "image":
+		f.Update = p
+	}
+}
+
+// OpenFactoryImage reads a factory image folder, a factory image zip (whose
+// parts are extracted to a temporary folder) or an update zip. Close removes
+// the extracted files.
+func OpenFactoryImage(src string, progress func(name string)) (*FactoryImage, error) {
+	f := &FactoryImage{Source: src}
+	st, err := os.Stat(src)
+	if err != nil {
+		return nil, err
+	}
+	if st.IsDir() {
+		entries, err := os.ReadDir(src)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if !e.IsDir() {
+				f.set(factoryPart(e.Name()), filepath.Join(src, e.Name()))
+			}
+		}
+		if f.Update == "" {
+			return nil, errors.New("no image-*.zip found in folder")
+		}
+	} else if err := f.openZip(src, progress); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.readInfo(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *FactoryImage) openZip(src string, progress func(string)) error {
+	zr, err := zip.OpenReader(src)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+	var parts []*zip.File
+	hasInfo, hasPayload := false, false
+	for _, zf := range zr.File {
+		switch {
+		case factoryPart(zf.Name) != "":
+			parts = append(parts, zf)
+		case zf.Name == "android-info.txt":
+			hasInfo = true
+		case zf.Name == "payload.bin":
+			hasPayload = true
+		}
+	}
+	switch {
+	case len(parts) > 0:
+	case hasInfo:
+		f.Update = src
+		return nil
+	case hasPayload:
+		return errors.New("this is an OTA package (payload.bin); use adb sideload or extract the images first")
+	default:
+		return errors.New("not a factory image or update zip")
+	}
+	if f.tmpDir, err = os.MkdirTemp("", "adb-gui-factory-"); err != nil {
+		return err
+	}
+	for _, zf := range parts {
+		if progress != nil {
+			progress(path.Base(zf.Name))
+		}
+		dst := filepath.Join(f.tmpDir, path.Base(zf.Name))
+		if err := extractZipFile(zf, dst); err != nil {
+			return err
+		}
+		f.set(factoryPart(zf.Name), dst)
+	}
+	if f.Update == "" {
+		return errors.New("no image-*.zip found in archive")
+	}
+	return nil
+}
+
+func extractZipFile(zf *zip.File, dst string) error {
+	rc, err := zf.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, rc); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// readInfo loads android-info.txt from the update zip.
+func (f *FactoryImage) readInfo() error {
+	zr, err := zip.OpenReader(f.Update)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+	for _, zf := range zr.File {
+		if zf.Name != "android-info.txt" {
+			continue
+		}
+		rc, err := zf.Open()
+		if err != nil {
+			return err
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+		f.Info = parseAndroidInfo(string(data))
+		return nil
+	}
+	return fmt.Errorf("android-info.txt not found in %s", filepath.Base(f.Update))
+}
+
+// Close removes files extracted from a factory image zip.
+func (f *FactoryImage) Close() error {
+	if f.tmpDir == "" {
+		return nil
+	}
+	err := os.RemoveAll(f.tmpDir)
+	f.tmpDir = ""
+	return err
+}
+
+// ImageCheck compares one android-info.txt requirement with the device.
+type ImageCheck struct {
+	Name string // "board", "version-bootloader", "version-baseband"
+	Want []string
+	Have string
+	OK   bool
+}
+
+// checkVars maps android-info.txt requirements to getvar variables.
+var checkVars = [][2]string{
+	{"board", "product"},
+	{"version-bootloader", "version-bootloader"},
+	{"version-baseband", "version-baseband"},
+}
+
+// Check compares the image requirements with the device's getvar output.
+func (f *FactoryImage) Check(g *GetVarInfo) []ImageCheck {
+	var res []ImageCheck
+	for _, cv := range checkVars {
+		want, ok := f.Info.Requires[cv[0]]
+		if !ok {
+			continue
+		}
+		c := ImageCheck{Name: cv[0], Want: want, Have: g.Vars[cv[1]]}
+		for _, v := range want {
+			if strings.EqualFold(v, c.Have) {
+				c.OK = true
+			}
+		}
+		res = append(res, c)
+	}
+	return res
+}
+
+// ProductMatches reports whether the device is a board the image is built for.
+// Images without a board requirement match any device.
+func (f *FactoryImage) ProductMatches(g *GetVarInfo) bool {
+	for _, c := range f.Check(g) {
+		if c.Name == "board" {
+			return c.OK
+		}
+	}
+	return true
+}
+
+// Flash step kinds.
+const (
+	StepFlash  = "flash"
+	StepReboot = "reboot"
+	StepUpdate = "update"
+)
+
+// FlashStep is one fastboot invocation of a flashing plan.
+type FlashStep struct {
+	Kind   string // StepFlash, StepReboot or StepUpdate
+	Target string // partition or file name shown to the user
+	Args   []string
+	Wait   bool // wait for the device to return to fastboot afterwards
+}
+
+// Command returns the step as a command line.
+func (s FlashStep) Command() string {
+	return "fastboot " + strings.Join(s.Args, " ")
+}
+
+// PlanOptions tune the generated plan.
+type PlanOptions struct {
+	Wipe        bool // pass -w to fastboot update
+	SkipCurrent bool // skip bootloader/radio already at the required version
+}
+
+// Plan builds the same sequence as flash-all.sh: bootloader, reboot, radio,
+// reboot, then update.
+func (f *FactoryImage) Plan(g *GetVarInfo, opt PlanOptions) []FlashStep {
+	current := map[string]bool{}
+	if g != nil {
+		for _, c := range f.Check(g) {
+			current[c.Name] = c.OK
+		}
+	}
+	var steps []FlashStep
+	for _, p := range [][3]string{
+		{"bootloader", f.Bootloader, "version-bootloader"},
+		{"radio", f.Radio, "version-baseband"},
+	} {
+		if p[1] == "" || (opt.SkipCurrent && current[p[2]]) {
+			continue
+		}
+		steps = append(steps,
+			FlashStep{Kind: StepFlash, Target: p[0], Args: []string{"flash", p[0], p[1]}},
+			FlashStep{Kind: StepReboot, Target: "bootloader", Args: []string{"reboot-bootloader"}, Wait: true},
+		)
+	}
+	args := []string{"update", f.Update}
+	if opt.Wipe {
+		args = append([]string{"-w"}, args...)
+	}
+	return append(steps, FlashStep{Kind: StepUpdate, Target: filepath.Base(f.Update), Args: args})
+}
+
+// RunFlashStep runs one step and, for reboots, waits for the device to return.
+func (m *Manager) RunFlashStep(serial string, s FlashStep) (string, error) {
+	out, err := m.ExecFastboot(serial, s.Args...)
+	if err == nil && s.Wait {
+		err = m.WaitFastboot(context.Background(), serial, 90*time.Second)
+	}
+	return out, err
+}
+
+// WaitFastboot waits until the device is listed by "fastboot devices" again,
+// the timeout expires or ctx is done.
+func (m *Manager) WaitFastboot(ctx context.Context, serial string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	// Give the device time to drop off the bus before polling.
+	delay := 3 * time.Second
+	for {
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return fmt.Errorf("device %s did not return to fastboot within %s", serial, timeout)
+			}
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay = time.Second
+		out, _ := m.ExecFastbootContext(ctx, "", "devices")
+		for _, d := range parseFastbootDevices(out) {
+			if serial == "" || d.Serial == serial {
+				return nil
+			}
+		}
+	}
+}
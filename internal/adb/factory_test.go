@@ -0,0 +1,147 @@
+package adb
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseAndroidInfo(t *testing.T) {
+	info := parseAndroidInfo("require board=oriole|raven\r\nrequire version-bootloader=slider-1.2\n# comment\nrequire-partition-size=x\nrequire  version-baseband = g5123b \n")
+	want := map[string][]string{
+		"board":              {"oriole", "raven"},
+		"version-bootloader": {"slider-1.2"},
+		"version-baseband":   {"g5123b"},
+	}
+	if !reflect.DeepEqual(info.Requires, want) {
+		t.Errorf("Requires = %v, want %v", info.Requires, want)
+	}
+}
+
+func testFactoryImage() *FactoryImage {
+	return &FactoryImage{
+		Bootloader: "/img/bootloader-oriole-slider-1.2.img",
+		Radio:      "/img/radio-oriole-g5123b.img",
+		Update:     "/img/image-oriole.zip",
+		Info: parseAndroidInfo("require board=oriole|raven\n" +
+			"require version-bootloader=slider-1.2\n" +
+			"require version-baseband=g5123b\n"),
+	}
+}
+
+func TestFactoryCheck(t *testing.T) {
+	f := testFactoryImage()
+	g := &GetVarInfo{Vars: map[string]string{"product": "Raven", "version-bootloader": "slider-1.2", "version-baseband": "g5000"}}
+	got := map[string]bool{}
+	for _, c := range f.Check(g) {
+		got[c.Name] = c.OK
+	}
+	want := map[string]bool{"board": true, "version-bootloader": true, "version-baseband": false}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Check = %v, want %v", got, want)
+	}
+	if !f.ProductMatches(g) {
+		t.Error("ProductMatches rejected a board listed in require board=a|b")
+	}
+	if f.ProductMatches(&GetVarInfo{Vars: map[string]string{"product": "bluejay"}}) {
+		t.Error("ProductMatches accepted another board")
+	}
+	if !(&FactoryImage{}).ProductMatches(g) {
+		t.Error("an image without a board requirement should match any device")
+	}
+}
+
+func TestFactoryPlan(t *testing.T) {
+	f := testFactoryImage()
+	current := &GetVarInfo{Vars: map[string]string{"product": "oriole", "version-bootloader": "slider-1.2", "version-baseband": "g5123b"}}
+	commands := func(steps []FlashStep) []string {
+		var res []string
+		for _, s := range steps {
+			res = append(res, s.Command())
+		}
+		return res
+	}
+	tests := []struct {
+		name string
+		f    *FactoryImage
+		g    *GetVarInfo
+		opt  PlanOptions
+		want []string
+	}{
+		{"full", f, current, PlanOptions{}, []string{
+			"fastboot flash bootloader /img/bootloader-oriole-slider-1.2.img",
+			"fastboot reboot-bootloader",
+			"fastboot flash radio /img/radio-oriole-g5123b.img",
+			"fastboot reboot-bootloader",
+			"fastboot update /img/image-oriole.zip",
+		}},
+		{"skip current", f, current, PlanOptions{SkipCurrent: true}, []string{
+			"fastboot update /img/image-oriole.zip",
+		}},
+		{"skip current, old radio", f, &GetVarInfo{Vars: map[string]string{"version-bootloader": "slider-1.2", "version-baseband": "g5000"}},
+			PlanOptions{SkipCurrent: true}, []string{
+				"fastboot flash radio /img/radio-oriole-g5123b.img",
+				"fastboot reboot-bootloader",
+				"fastboot update /img/image-oriole.zip",
+			}},
+		{"skip current without device info", f, nil, PlanOptions{SkipCurrent: true, Wipe: true}, []string{
+			"fastboot flash bootloader /img/bootloader-oriole-slider-1.2.img",
+			"fastboot reboot-bootloader",
+			"fastboot flash radio /img/radio-oriole-g5123b.img",
+			"fastboot reboot-bootloader",
+			"fastboot -w update /img/image-oriole.zip",
+		}},
+		{"wipe", f, current, PlanOptions{SkipCurrent: true, Wipe: true}, []string{
+			"fastboot -w update /img/image-oriole.zip",
+		}},
+		{"update zip", &FactoryImage{Update: "/img/oriole-img.zip"}, current, PlanOptions{Wipe: true}, []string{
+			"fastboot -w update /img/oriole-img.zip",
+		}},
+	}
+	for _, tt := range tests {
+		steps := tt.f.Plan(tt.g, tt.opt)
+		if got := commands(steps); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Plan = %q, want %q", tt.name, got, tt.want)
+			continue
+		}
+		for _, s := range steps {
+			if s.Wait != (s.Kind == StepReboot) {
+				t.Errorf("%s: step %q Wait = %v", tt.name, s.Command(), s.Wait)
+			}
+		}
+	}
+}
+
+func TestOpenFactoryImageUpdateZip(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "oriole-img.zip")
+	out, err := os.Create(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(out)
+	for name, data := range map[string]string{"android-info.txt": "require board=oriole\n", "boot.img": "boot"} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(data))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	out.Close()
+
+	f, err := OpenFactoryImage(p, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if f.Bootloader != "" || f.Radio != "" || f.Update != p {
+		t.Errorf("OpenFactoryImage = %+v", f)
+	}
+	if got := f.Info.Requires["board"]; !reflect.DeepEqual(got, []string{"oriole"}) {
+		t.Errorf("board = %v", got)
+	}
+}
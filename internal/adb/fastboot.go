@@ -0,0 +1,281 @@
+package adb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"adb-gui/internal/imgtool"
+)
+
+// Flash slot targets; SlotDefault leaves the choice to fastboot (the current
+// slot for A/B partitions).
+const (
+	SlotDefault = ""
+	SlotA       = "a"
+	SlotB       = "b"
+	SlotAll     = "all"
+)
+
+// Fastboot modes.
+const (
+	ModeBootloader = "bootloader"
+	ModeFastbootd  = "fastbootd"
+)
+
+// Mode reports whether the device runs the bootloader's fastboot or
+// userspace fastboot (fastbootd), from the is-userspace variable.
+func (g *GetVarInfo) Mode() string {
+	if g.Vars["is-userspace"] == "yes" {
+		return ModeFastbootd
+	}
+	return ModeBootloader
+}
+
+// Slot returns the state of the named slot, or nil when it is not reported.
+func (g *GetVarInfo) Slot(name string) *GetVarSlot {
+	for i := range g.Slots {
+		if g.Slots[i].Name == name {
+			return &g.Slots[i]
+		}
+	}
+	return nil
+}
+
+// Partition returns the named partition (without slot suffix), or nil.
+func (g *GetVarInfo) Partition(name string) *GetVarPartition {
+	for i := range g.Partitions {
+		if g.Partitions[i].Name == name {
+			return &g.Partitions[i]
+		}
+	}
+	return nil
+}
+
+// MaxDownloadSize is the largest image the device accepts in one transfer,
+// or 0 when not reported.
+func (g *GetVarInfo) MaxDownloadSize() int64 {
+	if g == nil {
+		return 0
+	}
+	n, _ := strconv.ParseInt(strings.TrimSpace(g.Vars["max-download-size"]), 0, 64)
+	return n
+}
+
+// SlotNames returns the reported slots, or a and b when the device reports a
+// current slot but no per-slot variables.
+func (g *GetVarInfo) SlotNames() []string {
+	if len(g.Slots) == 0 {
+		if g.CurrentSlot == "" {
+			return nil
+		}
+		return []string{SlotA, SlotB}
+	}
+	res := make([]string, len(g.Slots))
+	for i, s := range g.Slots {
+		res[i] = s.Name
+	}
+	return res
+}
+
+// Flash warning kinds.
+const (
+	WarnNotSlotted = "not_slotted"
+	WarnInactive   = "inactive"
+	WarnUnbootable = "unbootable"
+)
+
+// FlashWarning is a reason to confirm before flashing.
+type FlashWarning struct {
+	Kind    string // WarnNotSlotted, WarnInactive or WarnUnbootable
+	Subject string // the partition for WarnNotSlotted, otherwise the slot
+}
+
+// FlashWarnings lists reasons to confirm before flashing partition to slot.
+func (g *GetVarInfo) FlashWarnings(partition, slot string) []FlashWarning {
+	if g == nil || g.CurrentSlot == "" {
+		return nil
+	}
+	var res []FlashWarning
+	if p := g.Partition(partition); p != nil && !p.HasSlot {
+		if slot != SlotDefault {
+			res = append(res, FlashWarning{WarnNotSlotted, partition})
+		}
+		return res
+	}
+	targets := []string{slot}
+	switch slot {
+	case SlotDefault:
+		targets = []string{g.CurrentSlot}
+	case SlotAll:
+		targets = g.SlotNames()
+	}
+	for _, s := range targets {
+		if s != g.CurrentSlot && slot != SlotAll {
+			res = append(res, FlashWarning{WarnInactive, s})
+		}
+		if st := g.Slot(s); st != nil && st.Unbootable == "yes" {
+			res = append(res, FlashWarning{WarnUnbootable, s})
+		}
+	}
+	return res
+}
+
+// ImageTooLargeError reports an image that does not fit its partition.
+type ImageTooLargeError struct {
+	Partition string
+	Image     int64
+	Capacity  int64
+}
+
+func (e *ImageTooLargeError) Error() string {
+	return fmt.Sprintf("image is %d bytes but partition %s holds only %d", e.Image, e.Partition, e.Capacity)
+}
+
+// flashTargets returns the partition names a flash to slot writes.
+func (g *GetVarInfo) flashTargets(partition, slot string) []string {
+	res := []string{partition}
+	switch slot {
+	case SlotDefault:
+		if g.CurrentSlot != "" {
+			res = append(res, partition+"_"+g.CurrentSlot)
+		}
+	case SlotAll:
+		for _, s := range g.SlotNames() {
+			res = append(res, partition+"_"+s)
+		}
+	default:
+		res = append(res, partition+"_"+slot)
+	}
+	return res
+}
+
+// CheckImageSize returns an *ImageTooLargeError when image is larger than the
+// partition size reported by getvar. Sparse images count with their expanded
+// size. Logical partitions are not checked, fastbootd resizes them.
+func (g *GetVarInfo) CheckImageSize(partition, slot, image string) error {
+	size, err := imgtool.ImageSize(image)
+	if err != nil {
+		return err
+	}
+	if g == nil {
+		return nil
+	}
+	for _, name := range g.flashTargets(partition, slot) {
+		p := g.Partition(name)
+		if p == nil || p.Size < 0 || p.Logical {
+			continue
+		}
+		if size > p.Size {
+			return &ImageTooLargeError{Partition: name, Image: size, Capacity: p.Size}
+		}
+	}
+	return nil
+}
+
+// InFastboot reports whether serial is listed by "fastboot devices".
+func (m *Manager) InFastboot(serial string) (bool, error) {
+	out, err := m.ExecFastboot("", "devices")
+	if err != nil {
+		return false, err
+	}
+	for _, d := range parseFastbootDevices(out) {
+		if d.Serial == serial {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// SetActiveSlot marks slot as the one to boot next.
+func (m *Manager) SetActiveSlot(serial, slot string) (string, error) {
+	if slot != SlotA && slot != SlotB {
+		return "", fmt.Errorf("invalid slot %q", slot)
+	}
+	return m.ExecFastboot(serial, "set_active", slot)
+}
+
+// FlashOptions are the flags of a flash command.
+type FlashOptions struct {
+	Slot                string // SlotDefault, SlotA, SlotB or SlotAll
+	DisableVerity       bool   // vbmeta only: --disable-verity
+	DisableVerification bool   // vbmeta only: --disable-verification
+}
+
+func (o FlashOptions) args() []string {
+	var res []string
+	if o.Slot != SlotDefault {
+		res = append(res, "--slot="+o.Slot)
+	}
+	if o.DisableVerity {
+		res = append(res, "--disable-verity")
+	}
+	if o.DisableVerification {
+		res = append(res, "--disable-verification")
+	}
+	return res
+}
+
+// FlashPartition flashes image to partition.
+func (m *Manager) FlashPartition(serial, partition, image string, opt FlashOptions) (string, error) {
+	partition = strings.TrimSpace(partition)
+	if partition == "" {
+		return "", errors.New("no partition given")
+	}
+	return m.ExecFastboot(serial, append(opt.args(), "flash", partition, image)...)
+}
+
+// FlashImage flashes like FlashPartition, but an image larger than
+// maxDownload is first split into sparse pieces that are flashed in turn.
+// progress, if set, is called before each piece.
+func (m *Manager) FlashImage(serial, partition, image string, opt FlashOptions, maxDownload int64, progress func(piece, pieces int)) (string, error) {
+	st, err := os.Stat(image)
+	if err != nil {
+		return "", err
+	}
+	if maxDownload <= 0 || st.Size() <= maxDownload {
+		return m.FlashPartition(serial, partition, image, opt)
+	}
+	im, err := imgtool.Open(image, 0)
+	if err != nil {
+		// Not block aligned; let fastboot resparse it itself.
+		return m.FlashPartition(serial, partition, image, opt)
+	}
+	defer im.Close()
+	dir, err := os.MkdirTemp("", "adb-gui-sparse-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+	pieces, err := im.Split(dir, filepath.Base(image), maxDownload)
+	if err != nil {
+		return "", err
+	}
+	var log strings.Builder
+	for i, p := range pieces {
+		if progress != nil {
+			progress(i+1, len(pieces))
+		}
+		out, err := m.FlashPartition(serial, partition, p, opt)
+		log.WriteString(out)
+		if err != nil {
+			return log.String(), fmt.Errorf("piece %d/%d: %w", i+1, len(pieces), err)
+		}
+	}
+	return log.String(), nil
+}
+
+// RebootFastbootd switches a device from the bootloader to userspace fastboot
+// and waits for it to be listed again.
+func (m *Manager) RebootFastbootd(serial string) (string, error) {
+	out, err := m.ExecFastboot(serial, "reboot", "fastboot")
+	if err == nil {
+		err = m.WaitFastboot(context.Background(), serial, 90*time.Second)
+	}
+	return out, err
+}
@@ -0,0 +1,156 @@
+package adb
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"adb-gui/internal/imgtool"
+)
+
+// testGetVar describes an A/B device on slot a with slot b marked unbootable.
+func testGetVar() *GetVarInfo {
+	return parseGetVarAll("(bootloader) current-slot:a\n" +
+		"(bootloader) slot-count:2\n" +
+		"(bootloader) slot-unbootable:b:yes\n" +
+		"(bootloader) has-slot:boot:yes\n" +
+		"(bootloader) has-slot:misc:no\n" +
+		"(bootloader) partition-size:boot_a:0x8000\n" +
+		"(bootloader) partition-size:boot_b:0x4000\n" +
+		"(bootloader) partition-size:misc:0x8000\n" +
+		"(bootloader) has-slot:system:yes\n" +
+		"(bootloader) is-logical:system_a:yes\n" +
+		"(bootloader) partition-size:system_a:0x1000\n")
+}
+
+func TestFlashTargets(t *testing.T) {
+	g := testGetVar()
+	tests := []struct {
+		partition, slot string
+		want            []string
+	}{
+		{"boot", SlotDefault, []string{"boot", "boot_a"}},
+		{"boot", SlotB, []string{"boot", "boot_b"}},
+		{"boot", SlotAll, []string{"boot", "boot_a", "boot_b"}},
+		{"misc", SlotDefault, []string{"misc", "misc_a"}},
+	}
+	for _, tt := range tests {
+		if got := g.flashTargets(tt.partition, tt.slot); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("flashTargets(%q, %q) = %v, want %v", tt.partition, tt.slot, got, tt.want)
+		}
+	}
+	unslotted := parseGetVarAll("(bootloader) partition-size:boot:0x8000\n")
+	if got := unslotted.flashTargets("boot", SlotDefault); !reflect.DeepEqual(got, []string{"boot"}) {
+		t.Errorf("flashTargets on a non-A/B device = %v", got)
+	}
+}
+
+func TestFlashWarnings(t *testing.T) {
+	g := testGetVar()
+	tests := []struct {
+		partition, slot string
+		want            []FlashWarning
+	}{
+		{"boot", SlotDefault, nil},
+		{"boot", SlotA, nil},
+		{"boot", SlotB, []FlashWarning{{WarnInactive, "b"}, {WarnUnbootable, "b"}}},
+		{"boot", SlotAll, []FlashWarning{{WarnUnbootable, "b"}}},
+		{"misc", SlotDefault, nil},
+		{"misc", SlotB, []FlashWarning{{WarnNotSlotted, "misc"}}},
+		{"misc", SlotAll, []FlashWarning{{WarnNotSlotted, "misc"}}},
+	}
+	for _, tt := range tests {
+		if got := g.FlashWarnings(tt.partition, tt.slot); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("FlashWarnings(%q, %q) = %v, want %v", tt.partition, tt.slot, got, tt.want)
+		}
+	}
+	if got := parseGetVarAll("(bootloader) product:x\n").FlashWarnings("boot", SlotB); got != nil {
+		t.Errorf("FlashWarnings on a non-A/B device = %v", got)
+	}
+}
+
+func TestCheckImageSize(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, size int) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, make([]byte, size), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	small := write("small.img", 0x4000)
+	large := write("large.img", 0x6000)
+	// A sparse image of zero blocks is tiny on disk but expands to 0x10000.
+	raw := write("zero.img", 0x10000)
+	im, err := imgtool.Open(raw, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sparse := filepath.Join(dir, "zero.simg")
+	err = im.WriteSparse(sparse)
+	im.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := testGetVar()
+	tests := []struct {
+		partition, slot, image string
+		tooLarge               string // partition reported, "" for no error
+	}{
+		{"boot", SlotDefault, large, ""},
+		{"boot", SlotB, large, "boot_b"},
+		{"boot", SlotAll, large, "boot_b"},
+		{"boot", SlotAll, small, ""},
+		{"misc", SlotDefault, sparse, "misc"},
+		{"system", SlotDefault, sparse, ""}, // logical: fastbootd resizes it
+		{"vendor", SlotDefault, sparse, ""}, // not reported
+	}
+	for _, tt := range tests {
+		err := g.CheckImageSize(tt.partition, tt.slot, tt.image)
+		var tl *ImageTooLargeError
+		switch {
+		case tt.tooLarge == "" && err != nil:
+			t.Errorf("CheckImageSize(%s, %q, %s) = %v", tt.partition, tt.slot, filepath.Base(tt.image), err)
+		case tt.tooLarge != "" && (!errors.As(err, &tl) || tl.Partition != tt.tooLarge):
+			t.Errorf("CheckImageSize(%s, %q, %s) = %v, want too large for %s", tt.partition, tt.slot, filepath.Base(tt.image), err, tt.tooLarge)
+		}
+	}
+	if err := g.CheckImageSize("misc", SlotDefault, sparse); err != nil {
+		var tl *ImageTooLargeError
+		if errors.As(err, &tl) && tl.Image != 0x10000 {
+			t.Errorf("sparse image counted as %d bytes, want its expanded size", tl.Image)
+		}
+	}
+	if err := (*GetVarInfo)(nil).CheckImageSize("boot", SlotDefault, large); err != nil {
+		t.Errorf("CheckImageSize without device info = %v", err)
+	}
+}
+
+func TestFastbootTimeout(t *testing.T) {
+	tests := []struct {
+		args []string
+		want time.Duration
+	}{
+		{[]string{"devices"}, fastbootQueryTimeout},
+		{[]string{"getvar", "all"}, fastbootQueryTimeout},
+		{[]string{"set_active", "b"}, fastbootQueryTimeout},
+		{[]string{"reboot"}, fastbootQueryTimeout},
+		{[]string{"reboot", "fastboot"}, fastbootdSwitchTimeout},
+		{[]string{"reboot-bootloader"}, fastbootQueryTimeout},
+		{[]string{"oem", "device-info"}, fastbootQueryTimeout},
+		{[]string{"oem", "unlock"}, 0},
+		{[]string{"--slot=all", "flash", "boot", "boot.img"}, 0},
+		{[]string{"-w", "update", "image.zip"}, 0},
+		{[]string{"flashing", "unlock"}, 0},
+		{nil, 0},
+	}
+	for _, tt := range tests {
+		if got := fastbootTimeout(tt.args); got != tt.want {
+			t.Errorf("fastbootTimeout(%q) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
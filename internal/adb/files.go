@@ -0,0 +1,154 @@
+package adb
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrRemoteChanged is returned by WriteFile when the remote file was modified
+// after it was read, so saving would overwrite someone else's changes.
+var ErrRemoteChanged = errors.New("remote file changed since it was loaded")
+
+// editStagingDir is a world-writable location used to stage pushed files
+// before they are copied over the real target (possibly as root).
+const editStagingDir = "/data/local/tmp"
+
+// ShellAs runs a shell script on the device, wrapped in "su -c" when asRoot is true
+// or when root mode via su is enabled for the device.
+func (m *Manager) ShellAs(serial string, asRoot bool, script string) (string, error) {
+	if asRoot || m.useSu(serial) {
+		return m.ExecSerial(serial, "shell", "su", "-c", shellQuote(script))
+	}
+	return m.ExecSerial(serial, "shell", script)
+}
+
+// HasSu reports whether "su" is available and grants uid 0 on the device.
+func (m *Manager) HasSu(serial string) bool {
+	out, err := m.ExecSerial(serial, "shell", "su", "-c", "id")
+	return err == nil && strings.Contains(out, "uid=0")
+}
+
+// FileStamp identifies a version of a remote file for conflict checks.
+// stat's %Y has one-second resolution, so the size is compared as well and
+// the sub-second time from %y is used when the device reports it.
+type FileStamp struct {
+	Size  int64
+	Mtime time.Time
+}
+
+// IsZero reports whether the stamp is unset.
+func (s FileStamp) IsZero() bool {
+	return s.Mtime.IsZero()
+}
+
+// Equal reports whether two stamps describe the same file version.
+func (s FileStamp) Equal(o FileStamp) bool {
+	return s.Size == o.Size && s.Mtime.Equal(o.Mtime)
+}
+
+// statTimeLayout is the format of stat's %y ("2024-01-02 15:04:05.123456789 +0000").
+const statTimeLayout = "2006-01-02 15:04:05.999999999 -0700"
+
+// StatFile returns the size in bytes and modification time of a remote file.
+func (m *Manager) StatFile(serial, remotePath string, asRoot bool) (int64, time.Time, error) {
+	out, err := m.ShellAs(serial, asRoot, "stat -c '%s %Y %y' -- "+shellQuote(remotePath))
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("%v: %s", err, strings.TrimSpace(out))
+	}
+	return parseStat(out)
+}
+
+// parseStat parses "size seconds %y" as printed by StatFile, preferring the
+// sub-second time of %y over the whole seconds of %Y.
+func parseStat(out string) (int64, time.Time, error) {
+	f := strings.Fields(strings.TrimSpace(out))
+	if len(f) < 2 {
+		return 0, time.Time{}, errors.New(strings.TrimSpace(out))
+	}
+	size, err1 := strconv.ParseInt(f[0], 10, 64)
+	mtime, err2 := strconv.ParseInt(f[1], 10, 64)
+	if err1 != nil || err2 != nil {
+		return 0, time.Time{}, errors.New(strings.TrimSpace(out))
+	}
+	if len(f) >= 5 {
+		if t, err := time.Parse(statTimeLayout, strings.Join(f[2:5], " ")); err == nil && t.Unix() == mtime {
+			return size, t, nil
+		}
+	}
+	return size, time.Unix(mtime, 0), nil
+}
+
+// StampFile returns the FileStamp of a remote file.
+func (m *Manager) StampFile(serial, remotePath string, asRoot bool) (FileStamp, error) {
+	size, mtime, err := m.StatFile(serial, remotePath, asRoot)
+	return FileStamp{Size: size, Mtime: mtime}, err
+}
+
+// ReadFile returns up to limit bytes of a remote file via "exec-out".
+// A limit <= 0 reads the whole file.
+func (m *Manager) ReadFile(serial, remotePath string, limit int64, asRoot bool) ([]byte, error) {
+	script := "cat -- " + shellQuote(remotePath)
+	if limit > 0 {
+		script = "head -c " + strconv.FormatInt(limit, 10) + " -- " + shellQuote(remotePath)
+	}
+	if asRoot || m.useSu(serial) {
+		return m.ExecSerialStdout(serial, "exec-out", "su", "-c", shellQuote(script))
+	}
+	return m.ExecSerialStdout(serial, "exec-out", script)
+}
+
+// WriteFile replaces the contents of a remote file with data.
+// If expect is set the remote size and modification time are checked first and
+// ErrRemoteChanged is returned when they differ. The file is staged in
+// /data/local/tmp and copied with "cat >" so the target keeps its owner and mode.
+// Returns the new stamp of the remote file.
+func (m *Manager) WriteFile(serial, remotePath string, data []byte, expect FileStamp, asRoot bool) (FileStamp, string, error) {
+	if strings.TrimSpace(remotePath) == "" {
+		return FileStamp{}, "", errors.New("invalid write arguments")
+	}
+	if !expect.IsZero() {
+		cur, err := m.StampFile(serial, remotePath, asRoot)
+		if err != nil {
+			return FileStamp{}, "", err
+		}
+		if !cur.Equal(expect) {
+			return cur, "", ErrRemoteChanged
+		}
+	}
+
+	tmp, err := os.CreateTemp("", "adb-gui-edit-*")
+	if err != nil {
+		return FileStamp{}, "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return FileStamp{}, "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return FileStamp{}, "", err
+	}
+
+	staged := path.Join(editStagingDir, "adb-gui-edit-"+strconv.FormatInt(time.Now().UnixNano(), 10))
+	out, err := m.ExecSerial(serial, "push", tmp.Name(), staged)
+	if err != nil {
+		return FileStamp{}, out, err
+	}
+	script := fmt.Sprintf("cat %s > %s; rc=$?; rm -f %s; [ $rc -eq 0 ] && stat -c '%%s %%Y %%y' -- %s",
+		shellQuote(staged), shellQuote(remotePath), shellQuote(staged), shellQuote(remotePath))
+	out2, err := m.ShellAs(serial, asRoot, script)
+	out = strings.TrimSpace(out + "\n" + out2)
+	if err != nil {
+		return FileStamp{}, out, err
+	}
+	size, mtime, err := parseStat(out2)
+	if err != nil {
+		return FileStamp{}, out, err
+	}
+	return FileStamp{Size: size, Mtime: mtime}, out, nil
+}
@@ -0,0 +1,40 @@
+package adb
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseStat(t *testing.T) {
+	tests := []struct {
+		out   string
+		size  int64
+		mtime time.Time
+	}{
+		// toybox and busybox print nanoseconds in %y
+		{"42 1700000000 2023-11-14 22:13:20.123456789 +0000\n", 42, time.Unix(1700000000, 123456789)},
+		{"42 1700000000 2023-11-15 06:13:20.5 +0800", 42, time.Unix(1700000000, 500000000)},
+		// without %y, or when it disagrees with %Y, whole seconds are used
+		{"7 1700000000", 7, time.Unix(1700000000, 0)},
+		{"7 1700000000 garbage here now", 7, time.Unix(1700000000, 0)},
+	}
+	for _, tt := range tests {
+		size, mtime, err := parseStat(tt.out)
+		if err != nil || size != tt.size || !mtime.Equal(tt.mtime) {
+			t.Errorf("parseStat(%q) = %d, %v, %v; want %d, %v", tt.out, size, mtime, err, tt.size, tt.mtime)
+		}
+	}
+	for _, out := range []string{"", "stat: /x: No such file or directory", "x 1700000000"} {
+		if _, _, err := parseStat(out); err == nil {
+			t.Errorf("parseStat(%q) accepted bad output", out)
+		}
+	}
+
+	a := FileStamp{Size: 10, Mtime: time.Unix(1700000000, 100)}
+	if a.Equal(FileStamp{Size: 11, Mtime: a.Mtime}) {
+		t.Error("stamps with different sizes compare equal")
+	}
+	if a.Equal(FileStamp{Size: 10, Mtime: time.Unix(1700000000, 200)}) {
+		t.Error("stamps within the same second compare equal")
+	}
+}
@@ -0,0 +1,167 @@
+package adb
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FindOptions are the filters for a recursive search on the device.
+// Zero values mean "no filter".
+type FindOptions struct {
+	Root      string         // directory to search under
+	Name      string         // shell glob matched against the base name (-iname)
+	Regex     *regexp.Regexp // matched against the full path on the host side
+	MinSize   int64          // bytes, inclusive
+	MaxSize   int64          // bytes, inclusive
+	NewerThan time.Time      // modified at or after
+	OlderThan time.Time      // modified at or before
+	Type      string         // "f", "d", "l" or "" for any
+}
+
+// FindFlavor reports which find implementation the device ships:
+// "toybox" (stock Android 6+), "busybox" (older ROMs/recoveries) or "unknown".
+func (m *Manager) FindFlavor(serial string) string {
+	out, _ := m.ExecSerial(serial, "shell", "find --help 2>&1 | head -n 3; readlink \"$(command -v find)\"")
+	lo := strings.ToLower(out)
+	switch {
+	case strings.Contains(lo, "toybox"):
+		return "toybox"
+	case strings.Contains(lo, "busybox"):
+		return "busybox"
+	}
+	return "unknown"
+}
+
+// findScript builds the device-side find command. Filters both implementations
+// understand (-iname, -type, -size Nc, -mmin) are pushed to the device; regex is
+// applied on the host because toybox find has no -regex.
+func findScript(opts FindOptions, flavor string, now time.Time) string {
+	var b strings.Builder
+	b.WriteString("find " + shellQuote(opts.Root))
+	if opts.Type != "" {
+		b.WriteString(" -type " + opts.Type)
+	}
+	if opts.Name != "" {
+		b.WriteString(" -iname " + shellQuote(opts.Name))
+	}
+	// -size +N means strictly greater, so shift by one byte to keep bounds inclusive
+	if opts.MinSize > 0 {
+		b.WriteString(" -size +" + strconv.FormatInt(opts.MinSize-1, 10) + "c")
+	}
+	if opts.MaxSize > 0 {
+		b.WriteString(" -size -" + strconv.FormatInt(opts.MaxSize+1, 10) + "c")
+	}
+	if !opts.NewerThan.IsZero() {
+		if mins := int64(now.Sub(opts.NewerThan).Minutes()) + 1; mins > 0 {
+			b.WriteString(" -mmin -" + strconv.FormatInt(mins, 10))
+		}
+	}
+	if !opts.OlderThan.IsZero() {
+		if mins := int64(now.Sub(opts.OlderThan).Minutes()); mins > 0 {
+			b.WriteString(" -mmin +" + strconv.FormatInt(mins, 10))
+		}
+	}
+	// Name is printed last because it may contain the separator
+	const format = "'%s|%Y|%A|%n'"
+	if flavor == "busybox" {
+		// busybox builds without FEATURE_FIND_EXEC_PLUS reject "{} +"
+		b.WriteString(" -exec stat -c " + format + " {} \\;")
+	} else {
+		b.WriteString(" -exec stat -c " + format + " {} +")
+	}
+	b.WriteString(" 2>/dev/null")
+	return b.String()
+}
+
+// parseFindLine parses one "size|mtime|mode|path" line produced by findScript.
+func parseFindLine(ln string) (FileEntry, time.Time, bool) {
+	parts := strings.SplitN(strings.TrimRight(ln, "\r"), "|", 4)
+	if len(parts) != 4 || parts[3] == "" {
+		return FileEntry{}, time.Time{}, false
+	}
+	size, err1 := strconv.ParseInt(parts[0], 10, 64)
+	ts, err2 := strconv.ParseInt(parts[1], 10, 64)
+	if err1 != nil || err2 != nil {
+		return FileEntry{}, time.Time{}, false
+	}
+	mtime := time.Unix(ts, 0)
+	return FileEntry{
+		Name:    parts[3],
+		IsDir:   strings.HasPrefix(parts[2], "d"),
+		Size:    size,
+		Mode:    parts[2],
+		ModTime: mtime.Format("2006-01-02 15:04:05"),
+	}, mtime, true
+}
+
+// matches re-checks the filters on the host, covering regex and any filter the
+// device-side find approximated.
+func (o FindOptions) matches(e FileEntry, mtime time.Time) bool {
+	if o.Regex != nil && !o.Regex.MatchString(e.Name) {
+		return false
+	}
+	if !e.IsDir {
+		if o.MinSize > 0 && e.Size < o.MinSize {
+			return false
+		}
+		if o.MaxSize > 0 && e.Size > o.MaxSize {
+			return false
+		}
+	}
+	if !o.NewerThan.IsZero() && mtime.Before(o.NewerThan) {
+		return false
+	}
+	if !o.OlderThan.IsZero() && mtime.After(o.OlderThan) {
+		return false
+	}
+	return true
+}
+
+// Find searches recursively under opts.Root and calls onEntry for every match as
+// results arrive. FileEntry.Name holds the full remote path. It blocks until find
+// finishes or ctx is cancelled (in which case ctx.Err() is returned).
+func (m *Manager) Find(ctx context.Context, serial string, opts FindOptions, onEntry func(FileEntry)) error {
+	if strings.TrimSpace(opts.Root) == "" {
+		return errors.New("empty search path")
+	}
+	switch opts.Type {
+	case "", "f", "d", "l":
+	default:
+		return fmt.Errorf("invalid find type %q", opts.Type)
+	}
+	script := findScript(opts, m.FindFlavor(serial), time.Now())
+	args := []string{"shell", script}
+	if m.useSu(serial) {
+		args = []string{"shell", "su", "-c", shellQuote(script)}
+	}
+	cmd, stdout, err := m.StartSerial(ctx, serial, args...)
+	if err != nil {
+		return err
+	}
+	sc := bufio.NewScanner(stdout)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		e, mtime, ok := parseFindLine(sc.Text())
+		if !ok || !opts.matches(e, mtime) {
+			continue
+		}
+		onEntry(e)
+	}
+	err = cmd.Wait()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	// find exits non-zero when some directories are unreadable; that is not a failure
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return nil
+	}
+	return err
+}
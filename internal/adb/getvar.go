@@ -0,0 +1,153 @@
+package adb
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// getvarIndexed are the variables reported once per partition or slot, as
+// "name:partition:value".
+var getvarIndexed = map[string]bool{
+	"partition-size":   true,
+	"partition-type":   true,
+	"is-logical":       true,
+	"has-slot":         true,
+	"slot-successful":  true,
+	"slot-unbootable":  true,
+	"slot-retry-count": true,
+}
+
+// GetVarPartition is one row of the partition table reported by "getvar all".
+type GetVarPartition struct {
+	Name    string
+	Type    string
+	SizeHex string
+	Size    int64 // decoded from SizeHex, -1 if unknown
+	Logical bool
+	HasSlot bool
+}
+
+// GetVarSlot is the A/B state of one slot.
+type GetVarSlot struct {
+	Name       string
+	Successful string
+	Unbootable string
+	RetryCount string
+}
+
+// GetVarInfo is the structured result of "fastboot getvar all".
+type GetVarInfo struct {
+	Vars        map[string]string // scalar variables
+	Partitions  []GetVarPartition // sorted by name
+	Slots       []GetVarSlot      // sorted by name
+	CurrentSlot string
+	Unlocked    string // "yes", "no" or "" when not reported
+	Secure      string
+	Raw         string
+	indexed     map[string]map[string]string
+}
+
+// Flatten returns every variable as a single map, with per-partition and
+// per-slot variables keyed "name:partition", for snapshots and diffs.
+func (g *GetVarInfo) Flatten() map[string]string {
+	res := make(map[string]string, len(g.Vars))
+	for k, v := range g.Vars {
+		res[k] = v
+	}
+	for name, byPart := range g.indexed {
+		for part, v := range byPart {
+			res[name+":"+part] = v
+		}
+	}
+	return res
+}
+
+// GetVarAll runs "fastboot getvar all" and parses the result.
+func (m *Manager) GetVarAll(serial string) (*GetVarInfo, string, error) {
+	out, err := m.ExecFastboot(serial, "getvar", "all")
+	if err != nil {
+		return nil, out, err
+	}
+	return parseGetVarAll(out), out, nil
+}
+
+// parseGetVarAll parses "(bootloader) key:value" lines. The key ends at the
+// first colon, except for the indexed variables whose partition or slot name
+// follows as a second field; values may themselves contain colons.
+func parseGetVarAll(out string) *GetVarInfo {
+	g := &GetVarInfo{Vars: map[string]string{}, indexed: map[string]map[string]string{}, Raw: out}
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		rest, ok := strings.CutPrefix(line, "(bootloader) ")
+		if !ok {
+			continue
+		}
+		key, val, ok := strings.Cut(rest, ":")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		if getvarIndexed[key] {
+			part, v, ok := strings.Cut(val, ":")
+			if !ok {
+				continue
+			}
+			part = strings.TrimSpace(part)
+			if g.indexed[key] == nil {
+				g.indexed[key] = map[string]string{}
+			}
+			g.indexed[key][part] = strings.TrimSpace(v)
+			continue
+		}
+		g.Vars[key] = strings.TrimSpace(val)
+	}
+
+	parts := map[string]bool{}
+	for _, k := range []string{"partition-size", "partition-type", "is-logical", "has-slot"} {
+		for p := range g.indexed[k] {
+			parts[p] = true
+		}
+	}
+	for p := range parts {
+		row := GetVarPartition{
+			Name:    p,
+			Type:    g.indexed["partition-type"][p],
+			SizeHex: g.indexed["partition-size"][p],
+			Size:    -1,
+			Logical: g.indexed["is-logical"][p] == "yes",
+			HasSlot: g.indexed["has-slot"][p] == "yes",
+		}
+		if n, err := strconv.ParseInt(strings.TrimPrefix(strings.ToLower(row.SizeHex), "0x"), 16, 64); err == nil {
+			row.Size = n
+		}
+		g.Partitions = append(g.Partitions, row)
+	}
+	sort.Slice(g.Partitions, func(i, j int) bool { return g.Partitions[i].Name < g.Partitions[j].Name })
+
+	slots := map[string]bool{}
+	for _, k := range []string{"slot-successful", "slot-unbootable", "slot-retry-count"} {
+		for s := range g.indexed[k] {
+			slots[s] = true
+		}
+	}
+	if n, err := strconv.Atoi(g.Vars["slot-count"]); err == nil {
+		for i := 0; i < n && i < 26; i++ {
+			slots[string(rune('a'+i))] = true
+		}
+	}
+	for s := range slots {
+		g.Slots = append(g.Slots, GetVarSlot{
+			Name:       s,
+			Successful: g.indexed["slot-successful"][s],
+			Unbootable: g.indexed["slot-unbootable"][s],
+			RetryCount: g.indexed["slot-retry-count"][s],
+		})
+	}
+	sort.Slice(g.Slots, func(i, j int) bool { return g.Slots[i].Name < g.Slots[j].Name })
+
+	g.CurrentSlot = strings.TrimPrefix(g.Vars["current-slot"], "_")
+	g.Unlocked = g.Vars["unlocked"]
+	g.Secure = g.Vars["secure"]
+	return g
+}
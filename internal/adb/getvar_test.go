@@ -0,0 +1,91 @@
+package adb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseGetVarAll(t *testing.T) {
+	tests := []struct {
+		name        string
+		out         string
+		vars        map[string]string
+		partitions  []GetVarPartition
+		slots       []string
+		currentSlot string
+	}{
+		{
+			name: "indexed keys",
+			out: "(bootloader) partition-size:modem: 0x0000000008000000\n" +
+				"(bootloader) partition-type:modem:raw\n" +
+				"(bootloader) is-logical:system_a:yes\n" +
+				"(bootloader) partition-size:system_a: 0x40000000\n" +
+				"(bootloader) has-slot:modem:yes\n" +
+				"all: Done!!\n",
+			vars: map[string]string{},
+			partitions: []GetVarPartition{
+				{Name: "modem", Type: "raw", SizeHex: "0x0000000008000000", Size: 0x8000000, HasSlot: true},
+				{Name: "system_a", SizeHex: "0x40000000", Size: 0x40000000, Logical: true},
+			},
+		},
+		{
+			name: "values with colons",
+			out: "(bootloader) version-bootloader:slider-1.2:build:42\n" +
+				"(bootloader) partition-type:userdata:f2fs:extra\n" +
+				"(bootloader)  product : oriole\n",
+			vars: map[string]string{"version-bootloader": "slider-1.2:build:42", "product": "oriole"},
+			partitions: []GetVarPartition{
+				{Name: "userdata", Type: "f2fs:extra", Size: -1},
+			},
+		},
+		{
+			name: "current slot with underscore",
+			out: "(bootloader) current-slot:_a\n" +
+				"(bootloader) slot-successful:a:yes\n" +
+				"(bootloader) slot-unbootable:b:no\n",
+			vars:        map[string]string{"current-slot": "_a"},
+			slots:       []string{"a", "b"},
+			currentSlot: "a",
+		},
+		{
+			name:        "current slot without underscore",
+			out:         "(bootloader) current-slot:b\n(bootloader) slot-retry-count:b:7\n",
+			vars:        map[string]string{"current-slot": "b"},
+			slots:       []string{"b"},
+			currentSlot: "b",
+		},
+		{
+			name:  "slot-count without per-slot variables",
+			out:   "(bootloader) slot-count:2\r\n(bootloader) unlocked:no\r\n",
+			vars:  map[string]string{"slot-count": "2", "unlocked": "no"},
+			slots: []string{"a", "b"},
+		},
+	}
+	for _, tt := range tests {
+		g := parseGetVarAll(tt.out)
+		if !reflect.DeepEqual(g.Vars, tt.vars) {
+			t.Errorf("%s: Vars = %v, want %v", tt.name, g.Vars, tt.vars)
+		}
+		if !reflect.DeepEqual(g.Partitions, tt.partitions) {
+			t.Errorf("%s: Partitions = %+v, want %+v", tt.name, g.Partitions, tt.partitions)
+		}
+		var slots []string
+		for _, s := range g.Slots {
+			slots = append(slots, s.Name)
+		}
+		if !reflect.DeepEqual(slots, tt.slots) {
+			t.Errorf("%s: Slots = %v, want %v", tt.name, slots, tt.slots)
+		}
+		if g.CurrentSlot != tt.currentSlot {
+			t.Errorf("%s: CurrentSlot = %q, want %q", tt.name, g.CurrentSlot, tt.currentSlot)
+		}
+	}
+
+	g := parseGetVarAll("(bootloader) slot-retry-count:b:7\n(bootloader) is-logical:system_a:yes\n")
+	if g.Slots[0].RetryCount != "7" {
+		t.Errorf("RetryCount = %q", g.Slots[0].RetryCount)
+	}
+	if flat := g.Flatten(); flat["is-logical:system_a"] != "yes" || flat["slot-retry-count:b"] != "7" {
+		t.Errorf("Flatten() = %v", flat)
+	}
+}
@@ -0,0 +1,122 @@
+package adb
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// LogBuffers are the logcat ring buffers offered for selection.
+var LogBuffers = []string{"main", "system", "crash", "events", "radio"}
+
+// LogLevels in increasing severity, as printed by logcat.
+const LogLevels = "VDIWEF"
+
+// LogEntry is one parsed "logcat -v threadtime" line.
+type LogEntry struct {
+	Seq     uint64 // arrival order, assigned by the reader
+	Time    string // "MM-DD HH:MM:SS.mmm"
+	PID     int
+	TID     int
+	Level   byte // one of LogLevels
+	Tag     string
+	Message string
+	Raw     string
+}
+
+// LevelIndex returns the severity of the entry (0 = verbose), -1 if unknown.
+func (e LogEntry) LevelIndex() int {
+	return strings.IndexByte(LogLevels, e.Level)
+}
+
+var threadtimeRe = regexp.MustCompile(`^(\d\d-\d\d \d\d:\d\d:\d\d\.\d+)\s+(\d+)\s+(\d+)\s+([VDIWEFS])\s+(.*?)\s*: ?(.*)$`)
+
+// ParseThreadtime parses a "-v threadtime" line. Lines that do not match (buffer
+// banners, wrapped output) are returned as level-less entries with only Message set.
+func ParseThreadtime(line string) LogEntry {
+	line = strings.TrimRight(line, "\r")
+	mm := threadtimeRe.FindStringSubmatch(line)
+	if mm == nil {
+		return LogEntry{Message: line, Raw: line}
+	}
+	pid, _ := strconv.Atoi(mm[2])
+	tid, _ := strconv.Atoi(mm[3])
+	lvl := mm[4][0]
+	if lvl == 'S' {
+		lvl = 'F'
+	}
+	return LogEntry{Time: mm[1], PID: pid, TID: tid, Level: lvl, Tag: mm[5], Message: mm[6], Raw: line}
+}
+
+// Logcat streams "logcat -v threadtime" for the given buffers until ctx is
+// cancelled or the device goes away, calling onEntry for every line.
+func (m *Manager) Logcat(ctx context.Context, serial string, buffers []string, onEntry func(LogEntry)) error {
+	args := []string{"logcat", "-v", "threadtime"}
+	for _, b := range buffers {
+		args = append(args, "-b", b)
+	}
+	cmd, stdout, err := m.StartSerial(ctx, serial, args...)
+	if err != nil {
+		return err
+	}
+	sc := bufio.NewScanner(stdout)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	var seq uint64
+	for sc.Scan() {
+		e := ParseThreadtime(sc.Text())
+		if strings.HasPrefix(e.Raw, "--------- beginning of") {
+			continue
+		}
+		seq++
+		e.Seq = seq
+		onEntry(e)
+	}
+	err = cmd.Wait()
+	if ctx.Err() != nil {
+		return nil
+	}
+	var ee *exec.ExitError
+	if errors.As(err, &ee) {
+		return errors.New("logcat exited: " + ee.Error())
+	}
+	return err
+}
+
+// ClearLogcat clears the given log buffers on the device.
+func (m *Manager) ClearLogcat(serial string, buffers []string) (string, error) {
+	args := []string{"logcat", "-c"}
+	for _, b := range buffers {
+		args = append(args, "-b", b)
+	}
+	return m.ExecSerial(serial, args...)
+}
+
+// PidOf returns the PIDs of processes named pkg (including ":service" processes).
+func (m *Manager) PidOf(serial, pkg string) ([]int, error) {
+	out, err := m.ExecSerial(serial, "shell", "pidof", shellQuote(pkg))
+	var pids []int
+	for _, f := range strings.Fields(out) {
+		if n, err := strconv.Atoi(f); err == nil {
+			pids = append(pids, n)
+		}
+	}
+	// pidof only matches exact names; pick up "pkg:remote" style processes too
+	if ps, _ := m.ExecSerial(serial, "shell", "ps", "-A", "-o", "PID,NAME"); ps != "" {
+		for _, ln := range strings.Split(ps, "\n") {
+			f := strings.Fields(ln)
+			if len(f) == 2 && strings.HasPrefix(f[1], pkg+":") {
+				if n, err := strconv.Atoi(f[0]); err == nil {
+					pids = append(pids, n)
+				}
+			}
+		}
+	}
+	if len(pids) == 0 && err != nil {
+		return nil, err
+	}
+	return pids, nil
+}
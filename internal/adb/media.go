@@ -0,0 +1,27 @@
+package adb
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+)
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// Screenshot captures the current screen of a device as PNG via "exec-out screencap -p".
+func (m *Manager) Screenshot(serial string) ([]byte, error) {
+	out, err := m.ExecSerialStdout(serial, "exec-out", "screencap", "-p")
+	if err != nil {
+		return nil, err
+	}
+	// Some devices print warnings before the image; skip to the PNG header
+	i := bytes.Index(out, pngSignature)
+	if i < 0 {
+		msg := strings.TrimSpace(string(out))
+		if msg == "" {
+			msg = "screencap returned no image"
+		}
+		return nil, errors.New(msg)
+	}
+	return out[i:], nil
+}
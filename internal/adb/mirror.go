@@ -0,0 +1,116 @@
+package adb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Android key codes used by the remote control.
+const (
+	KeyHome       = 3
+	KeyBack       = 4
+	KeyDpadUp     = 19
+	KeyDpadDown   = 20
+	KeyDpadLeft   = 21
+	KeyDpadRight  = 22
+	KeyVolumeUp   = 24
+	KeyVolumeDown = 25
+	KeyPower      = 26
+	KeyTab        = 61
+	KeyEnter      = 66
+	KeyDel        = 67
+	KeyEscape     = 111
+	KeyForwardDel = 112
+	KeyAppSwitch  = 187
+)
+
+// IsWireless reports whether serial refers to a TCP/IP or mDNS (wireless debugging) connection.
+func IsWireless(serial string) bool {
+	return strings.Contains(serial, ":") || strings.Contains(serial, "._adb-tls-connect.")
+}
+
+// ScreenFrame grabs one frame of the display. raw transfers uncompressed pixels,
+// which is much faster over USB; PNG is smaller and better for wireless links.
+func (m *Manager) ScreenFrame(serial string, raw bool) (image.Image, error) {
+	if !raw {
+		data, err := m.Screenshot(serial)
+		if err != nil {
+			return nil, err
+		}
+		return png.Decode(bytes.NewReader(data))
+	}
+	data, err := m.ExecSerialStdout(serial, "exec-out", "screencap")
+	if err != nil {
+		return nil, err
+	}
+	return decodeRawScreencap(data)
+}
+
+// decodeRawScreencap decodes "screencap" output without -p: a header of width,
+// height and pixel format (plus a color space word since Android 12) followed by
+// 4 bytes per pixel.
+func decodeRawScreencap(data []byte) (image.Image, error) {
+	if len(data) < 12 {
+		return nil, errors.New("short screencap output")
+	}
+	w := int(binary.LittleEndian.Uint32(data[0:4]))
+	h := int(binary.LittleEndian.Uint32(data[4:8]))
+	format := binary.LittleEndian.Uint32(data[8:12])
+	size := w * h * 4
+	if w <= 0 || h <= 0 || size > len(data) {
+		return nil, fmt.Errorf("unexpected screencap header %dx%d", w, h)
+	}
+	header := len(data) - size
+	if header != 12 && header != 16 {
+		return nil, fmt.Errorf("unexpected screencap size %d for %dx%d", len(data), w, h)
+	}
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	copy(img.Pix, data[header:])
+	switch format {
+	case 1, 2: // RGBA_8888, RGBX_8888
+	case 5: // BGRA_8888
+		for i := 0; i < len(img.Pix); i += 4 {
+			img.Pix[i], img.Pix[i+2] = img.Pix[i+2], img.Pix[i]
+		}
+	default:
+		return nil, fmt.Errorf("unsupported screencap pixel format %d", format)
+	}
+	if format == 2 {
+		for i := 3; i < len(img.Pix); i += 4 {
+			img.Pix[i] = 0xff
+		}
+	}
+	return img, nil
+}
+
+// InputTap taps at display coordinates.
+func (m *Manager) InputTap(serial string, x, y int) (string, error) {
+	return m.ExecSerial(serial, "shell", "input", "tap", strconv.Itoa(x), strconv.Itoa(y))
+}
+
+// InputSwipe drags from (x1,y1) to (x2,y2) over d.
+func (m *Manager) InputSwipe(serial string, x1, y1, x2, y2 int, d time.Duration) (string, error) {
+	return m.ExecSerial(serial, "shell", "input", "swipe",
+		strconv.Itoa(x1), strconv.Itoa(y1), strconv.Itoa(x2), strconv.Itoa(y2), strconv.FormatInt(d.Milliseconds(), 10))
+}
+
+// InputKeyEvent sends an Android key code.
+func (m *Manager) InputKeyEvent(serial string, keycode int) (string, error) {
+	return m.ExecSerial(serial, "shell", "input", "keyevent", strconv.Itoa(keycode))
+}
+
+// InputText types ASCII text into the focused field. "input text" treats
+// "%s" as a space, so spaces are encoded that way.
+func (m *Manager) InputText(serial, text string) (string, error) {
+	if text == "" {
+		return "", nil
+	}
+	return m.ExecSerial(serial, "shell", "input", "text", shellQuote(strings.ReplaceAll(text, " ", "%s")))
+}
@@ -0,0 +1,386 @@
+package adb
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/bzip2"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ulikunitz/xz"
+)
+
+// Install operation types of the update_engine payload format.
+const (
+	opReplace   = 0
+	opReplaceBz = 1
+	opZero      = 6
+	opDiscard   = 7
+	opReplaceXz = 8
+)
+
+// PayloadDefaultImages are the partitions selected for extraction by default.
+var PayloadDefaultImages = []string{"boot", "init_boot", "vendor_boot", "vbmeta"}
+
+type payloadExtent struct {
+	start, count uint64 // in blocks
+}
+
+type payloadOp struct {
+	typ        uint64
+	dataOffset uint64
+	dataLength uint64
+	dst        []payloadExtent
+	hash       []byte
+}
+
+// PayloadPartition is one partition image contained in a payload.
+type PayloadPartition struct {
+	Name string
+	Size uint64
+	Hash []byte // SHA-256 of the complete image, if present
+	ops  []payloadOp
+}
+
+// Payload is an opened payload.bin (Chrome OS update_engine format).
+type Payload struct {
+	Version      uint64
+	BlockSize    uint64
+	MinorVersion uint64
+	Partitions   []PayloadPartition
+	r            io.ReaderAt
+	dataOffset   int64
+	closer       io.Closer
+}
+
+// OpenPayload opens a payload.bin, or the payload.bin stored inside an OTA zip.
+func OpenPayload(path string) (*Payload, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	var r io.ReaderAt = f
+	var magic [4]byte
+	if _, err := f.ReadAt(magic[:], 0); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if string(magic[:]) == "PK\x03\x04" {
+		if r, err = payloadInZip(f); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	p := &Payload{r: r, closer: f}
+	if err := p.readManifest(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return p, nil
+}
+
+// payloadInZip locates payload.bin inside an OTA zip. OTA packages store it
+// uncompressed so it can be read in place.
+func payloadInZip(f *os.File) (io.ReaderAt, error) {
+	st, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(f, st.Size())
+	if err != nil {
+		return nil, err
+	}
+	for _, zf := range zr.File {
+		if zf.Name != "payload.bin" {
+			continue
+		}
+		if zf.Method != zip.Store {
+			return nil, errors.New("payload.bin is compressed inside the zip; extract it first")
+		}
+		off, err := zf.DataOffset()
+		if err != nil {
+			return nil, err
+		}
+		return io.NewSectionReader(f, off, int64(zf.UncompressedSize64)), nil
+	}
+	return nil, errors.New("payload.bin not found in zip")
+}
+
+// Close closes the underlying file.
+func (p *Payload) Close() error {
+	return p.closer.Close()
+}
+
+func (p *Payload) readManifest() error {
+	var hdr [24]byte
+	if _, err := p.r.ReadAt(hdr[:], 0); err != nil {
+		return fmt.Errorf("reading payload header: %w", err)
+	}
+	if string(hdr[:4]) != "CrAU" {
+		return errors.New("not a payload.bin (bad magic)")
+	}
+	p.Version = binary.BigEndian.Uint64(hdr[4:12])
+	if p.Version != 2 {
+		return fmt.Errorf("unsupported payload version %d", p.Version)
+	}
+	manifestSize := binary.BigEndian.Uint64(hdr[12:20])
+	sigSize := binary.BigEndian.Uint32(hdr[20:24])
+	if manifestSize > 64<<20 {
+		return fmt.Errorf("manifest too large (%d bytes)", manifestSize)
+	}
+	manifest := make([]byte, manifestSize)
+	if _, err := p.r.ReadAt(manifest, 24); err != nil {
+		return fmt.Errorf("reading manifest: %w", err)
+	}
+	p.dataOffset = 24 + int64(manifestSize) + int64(sigSize)
+	p.BlockSize = 4096
+	return walkProto(manifest, func(field int, v uint64, b []byte) error {
+		switch field {
+		case 3:
+			p.BlockSize = v
+		case 12:
+			p.MinorVersion = v
+		case 13:
+			part, err := parsePartitionUpdate(b)
+			if err != nil {
+				return err
+			}
+			p.Partitions = append(p.Partitions, part)
+		}
+		return nil
+	})
+}
+
+func parsePartitionUpdate(b []byte) (PayloadPartition, error) {
+	var part PayloadPartition
+	err := walkProto(b, func(field int, _ uint64, b []byte) error {
+		switch field {
+		case 1:
+			part.Name = string(b)
+		case 7: // new_partition_info
+			return walkProto(b, func(field int, v uint64, b []byte) error {
+				switch field {
+				case 1:
+					part.Size = v
+				case 2:
+					part.Hash = append([]byte(nil), b...)
+				}
+				return nil
+			})
+		case 8:
+			op, err := parseInstallOp(b)
+			if err != nil {
+				return err
+			}
+			part.ops = append(part.ops, op)
+		}
+		return nil
+	})
+	return part, err
+}
+
+func parseInstallOp(b []byte) (payloadOp, error) {
+	var op payloadOp
+	err := walkProto(b, func(field int, v uint64, b []byte) error {
+		switch field {
+		case 1:
+			op.typ = v
+		case 2:
+			op.dataOffset = v
+		case 3:
+			op.dataLength = v
+		case 6:
+			var e payloadExtent
+			err := walkProto(b, func(field int, v uint64, _ []byte) error {
+				switch field {
+				case 1:
+					e.start = v
+				case 2:
+					e.count = v
+				}
+				return nil
+			})
+			op.dst = append(op.dst, e)
+			return err
+		case 8:
+			op.hash = append([]byte(nil), b...)
+		}
+		return nil
+	})
+	return op, err
+}
+
+// walkProto calls fn for each field of a protobuf message. Varint fields
+// pass their value in v, length-delimited fields their bytes in b.
+func walkProto(buf []byte, fn func(field int, v uint64, b []byte) error) error {
+	for len(buf) > 0 {
+		key, n := binary.Uvarint(buf)
+		if n <= 0 {
+			return errors.New("malformed manifest")
+		}
+		buf = buf[n:]
+		field := int(key >> 3)
+		var v uint64
+		var b []byte
+		switch key & 7 {
+		case 0:
+			v, n = binary.Uvarint(buf)
+			if n <= 0 {
+				return errors.New("malformed manifest")
+			}
+			buf = buf[n:]
+		case 1:
+			if len(buf) < 8 {
+				return errors.New("malformed manifest")
+			}
+			v = binary.LittleEndian.Uint64(buf)
+			buf = buf[8:]
+		case 2:
+			l, n := binary.Uvarint(buf)
+			if n <= 0 || uint64(len(buf)-n) < l {
+				return errors.New("malformed manifest")
+			}
+			b = buf[n : n+int(l)]
+			buf = buf[n+int(l):]
+		case 5:
+			if len(buf) < 4 {
+				return errors.New("malformed manifest")
+			}
+			v = uint64(binary.LittleEndian.Uint32(buf))
+			buf = buf[4:]
+		default:
+			return fmt.Errorf("unsupported wire type %d in manifest", key&7)
+		}
+		if err := fn(field, v, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Partition returns the named partition, or nil.
+func (p *Payload) Partition(name string) *PayloadPartition {
+	for i := range p.Partitions {
+		if p.Partitions[i].Name == name {
+			return &p.Partitions[i]
+		}
+	}
+	return nil
+}
+
+// Full reports whether the partition can be rebuilt without a source image,
+// i.e. it only uses REPLACE, REPLACE_BZ, REPLACE_XZ, ZERO and DISCARD.
+func (pp *PayloadPartition) Full() bool {
+	for _, op := range pp.ops {
+		switch op.typ {
+		case opReplace, opReplaceBz, opReplaceXz, opZero, opDiscard:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Extract writes the named partition image to dst and verifies its hashes.
+// progress receives the bytes written so far and the image size.
+func (p *Payload) Extract(ctx context.Context, name, dst string, progress func(done, total int64)) (err error) {
+	pp := p.Partition(name)
+	if pp == nil {
+		return fmt.Errorf("partition %s not in payload", name)
+	}
+	if !pp.Full() {
+		return fmt.Errorf("%s: incremental OTA operations are not supported", name)
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(dst)
+		}
+	}()
+	if err := out.Truncate(int64(pp.Size)); err != nil {
+		return err
+	}
+	var done int64
+	for i, op := range pp.ops {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := p.applyOp(out, op); err != nil {
+			return fmt.Errorf("%s: operation %d: %w", name, i, err)
+		}
+		for _, e := range op.dst {
+			done += int64(e.count * p.BlockSize)
+		}
+		if progress != nil {
+			progress(min(done, int64(pp.Size)), int64(pp.Size))
+		}
+	}
+	if len(pp.Hash) == 0 {
+		return nil
+	}
+	if _, err := out.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, out); err != nil {
+		return err
+	}
+	if !bytes.Equal(h.Sum(nil), pp.Hash) {
+		return fmt.Errorf("%s: SHA-256 of the extracted image does not match the manifest", name)
+	}
+	return nil
+}
+
+func (p *Payload) applyOp(out *os.File, op payloadOp) error {
+	if op.typ == opZero || op.typ == opDiscard {
+		// The file was truncated to size, so these blocks already read as zero.
+		return nil
+	}
+	blob := make([]byte, op.dataLength)
+	if _, err := p.r.ReadAt(blob, p.dataOffset+int64(op.dataOffset)); err != nil {
+		return err
+	}
+	if len(op.hash) > 0 {
+		if sum := sha256.Sum256(blob); !bytes.Equal(sum[:], op.hash) {
+			return errors.New("data SHA-256 mismatch")
+		}
+	}
+	var r io.Reader = bytes.NewReader(blob)
+	switch op.typ {
+	case opReplace:
+	case opReplaceBz:
+		r = bzip2.NewReader(r)
+	case opReplaceXz:
+		xr, err := xz.NewReader(r)
+		if err != nil {
+			return err
+		}
+		r = xr
+	default:
+		return fmt.Errorf("unsupported operation type %d", op.typ)
+	}
+	for _, e := range op.dst {
+		w := io.NewOffsetWriter(out, int64(e.start*p.BlockSize))
+		if _, err := io.CopyN(w, r, int64(e.count*p.BlockSize)); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+	}
+	return nil
+}
+
+// PayloadImageName is the file name used for an extracted partition.
+func PayloadImageName(partition string) string {
+	return strings.ReplaceAll(partition, "/", "_") + ".img"
+}
@@ -0,0 +1,162 @@
+package adb
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Minimal protobuf encoders for building manifests.
+func pbVarint(field int, v uint64) []byte {
+	b := binary.AppendUvarint(nil, uint64(field)<<3)
+	return binary.AppendUvarint(b, v)
+}
+
+func pbBytes(field int, data []byte) []byte {
+	b := binary.AppendUvarint(nil, uint64(field)<<3|2)
+	b = binary.AppendUvarint(b, uint64(len(data)))
+	return append(b, data...)
+}
+
+func pbExtent(field int, start, count uint64) []byte {
+	return pbBytes(field, append(pbVarint(1, start), pbVarint(2, count)...))
+}
+
+func TestWalkProtoMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		buf  []byte
+	}{
+		{"truncated key", []byte{0x80}},
+		{"truncated varint", []byte{0x08, 0x80}},
+		{"varint overflow", append([]byte{0x08}, bytes.Repeat([]byte{0xff}, 11)...)},
+		{"length beyond buffer", []byte{0x12, 0x05, 0x01}},
+		{"huge length", append([]byte{0x12}, binary.AppendUvarint(nil, 1<<63)...)},
+		{"short fixed64", []byte{0x09, 1, 2, 3}},
+		{"short fixed32", []byte{0x0d, 1, 2}},
+		{"group wire type", []byte{0x0b}},
+	}
+	for _, tt := range tests {
+		err := walkProto(tt.buf, func(int, uint64, []byte) error { return nil })
+		if err == nil {
+			t.Errorf("%s: no error", tt.name)
+		}
+	}
+
+	var fields []int
+	msg := append(pbVarint(3, 4096), pbBytes(13, []byte("x"))...)
+	if err := walkProto(msg, func(f int, _ uint64, _ []byte) error {
+		fields = append(fields, f)
+		return nil
+	}); err != nil || len(fields) != 2 || fields[0] != 3 || fields[1] != 13 {
+		t.Errorf("walkProto = %v, %v", fields, err)
+	}
+}
+
+// testPayload writes a payload.bin with one partition, "boot", made of two
+// REPLACE blocks followed by one ZERO block, and returns it with the image.
+func testPayload(t *testing.T, corrupt func(data, image []byte)) (string, []byte) {
+	t.Helper()
+	const bs = 4096
+	data := make([]byte, 2*bs)
+	rand.New(rand.NewSource(3)).Read(data)
+	image := append(append([]byte(nil), data...), make([]byte, bs)...)
+	dataSum := sha256.Sum256(data)
+	imageSum := sha256.Sum256(image)
+	if corrupt != nil {
+		corrupt(data, imageSum[:])
+	}
+
+	replace := bytes.Join([][]byte{
+		pbVarint(1, opReplace),
+		pbVarint(2, 0),
+		pbVarint(3, uint64(len(data))),
+		pbExtent(6, 0, 2),
+		pbBytes(8, dataSum[:]),
+	}, nil)
+	zero := append(pbVarint(1, opZero), pbExtent(6, 2, 1)...)
+	info := append(pbVarint(1, uint64(len(image))), pbBytes(2, imageSum[:])...)
+	part := bytes.Join([][]byte{
+		pbBytes(1, []byte("boot")),
+		pbBytes(7, info),
+		pbBytes(8, replace),
+		pbBytes(8, zero),
+	}, nil)
+	manifest := append(pbVarint(3, bs), pbBytes(13, part)...)
+
+	var buf bytes.Buffer
+	buf.WriteString("CrAU")
+	binary.Write(&buf, binary.BigEndian, uint64(2))
+	binary.Write(&buf, binary.BigEndian, uint64(len(manifest)))
+	binary.Write(&buf, binary.BigEndian, uint32(0))
+	buf.Write(manifest)
+	buf.Write(data)
+	p := filepath.Join(t.TempDir(), "payload.bin")
+	if err := os.WriteFile(p, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return p, image
+}
+
+func TestPayloadExtract(t *testing.T) {
+	p, image := testPayload(t, nil)
+	pl, err := OpenPayload(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pl.Close()
+	if pl.BlockSize != 4096 || len(pl.Partitions) != 1 {
+		t.Fatalf("payload = %+v", pl)
+	}
+	pp := pl.Partition("boot")
+	if pp == nil || pp.Size != uint64(len(image)) || !pp.Full() {
+		t.Fatalf("boot partition = %+v", pp)
+	}
+	dst := filepath.Join(t.TempDir(), PayloadImageName("boot"))
+	if err := pl.Extract(context.Background(), "boot", dst, nil); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, image) {
+		t.Error("extracted image differs")
+	}
+	if err := pl.Extract(context.Background(), "system", dst, nil); err == nil {
+		t.Error("Extract of a missing partition succeeded")
+	}
+}
+
+func TestPayloadExtractHashMismatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func(data, imageSum []byte)
+		want    string
+	}{
+		{"operation data", func(data, _ []byte) { data[100] ^= 0xff }, "data SHA-256"},
+		{"whole image", func(_, imageSum []byte) { imageSum[0] ^= 0xff }, "extracted image"},
+	}
+	for _, tt := range tests {
+		p, _ := testPayload(t, tt.corrupt)
+		pl, err := OpenPayload(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dst := filepath.Join(t.TempDir(), "boot.img")
+		err = pl.Extract(context.Background(), "boot", dst, nil)
+		pl.Close()
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: Extract error = %v, want %q", tt.name, err, tt.want)
+		}
+		if _, serr := os.Stat(dst); serr == nil {
+			t.Errorf("%s: the failed image was left behind", tt.name)
+		}
+	}
+}
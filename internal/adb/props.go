@@ -0,0 +1,83 @@
+package adb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// propValueMax is the longest value setprop accepts for a non-ro property.
+const propValueMax = 91
+
+// PropGroups are the prefixes the property browser groups by, most specific
+// first; the last entry collects everything else.
+var PropGroups = []string{"ro.build.", "persist.", "sys.", "vendor.", "ro.", "other"}
+
+// PropGroup returns the PropGroups entry a property belongs to.
+func PropGroup(key string) string {
+	for _, g := range PropGroups[:len(PropGroups)-1] {
+		if strings.HasPrefix(key, g) {
+			return g
+		}
+	}
+	return PropGroups[len(PropGroups)-1]
+}
+
+// PropWritable reports whether setprop can change a property at runtime.
+// ro.* properties can only be set once, during boot.
+func PropWritable(key string) bool {
+	return !strings.HasPrefix(key, "ro.")
+}
+
+// parseGetprop parses "getprop" output. Values may span several lines; a
+// value ends at a line ending in "]" that is followed by the next "[key]: ["
+// line or the end of the output.
+func parseGetprop(out string) map[string]string {
+	props := make(map[string]string)
+	lines := strings.Split(strings.ReplaceAll(out, "\r\n", "\n"), "\n")
+	startsEntry := func(ln string) bool {
+		return strings.HasPrefix(ln, "[") && strings.Contains(ln, "]: [")
+	}
+	for i := 0; i < len(lines); i++ {
+		ln := lines[i]
+		if !startsEntry(ln) {
+			continue
+		}
+		key, rest, _ := strings.Cut(ln[1:], "]: [")
+		val := []string{rest}
+		for {
+			last := val[len(val)-1]
+			next := i + 1
+			if strings.HasSuffix(last, "]") && (next >= len(lines) || startsEntry(lines[next]) || strings.TrimSpace(lines[next]) == "") {
+				val[len(val)-1] = strings.TrimSuffix(last, "]")
+				break
+			}
+			if next >= len(lines) {
+				break
+			}
+			i = next
+			val = append(val, lines[i])
+		}
+		props[key] = strings.Join(val, "\n")
+	}
+	return props
+}
+
+// SetProp runs setprop and reads the property back, since setprop reports
+// neither read-only properties nor SELinux denials through its exit status.
+func (m *Manager) SetProp(serial, key, value string, asRoot bool) (string, error) {
+	if len(value) > propValueMax {
+		return "", fmt.Errorf("value is longer than %d bytes", propValueMax)
+	}
+	out, err := m.ShellAs(serial, asRoot, "setprop "+shellQuote(key)+" "+shellQuote(value))
+	if err != nil {
+		return out, err
+	}
+	got, err := m.ExecSerial(serial, "shell", "getprop", shellQuote(key))
+	if err != nil {
+		return out + got, err
+	}
+	if got = strings.TrimRight(got, "\r\n"); got != value {
+		return out, fmt.Errorf("%s is still %q (read-only or permission denied)", key, got)
+	}
+	return out, nil
+}
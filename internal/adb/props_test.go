@@ -0,0 +1,45 @@
+package adb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseGetprop(t *testing.T) {
+	tests := []struct {
+		name string
+		out  string
+		want map[string]string
+	}{
+		{
+			name: "single line",
+			out:  "[ro.product.model]: [Pixel 6]\n[sys.boot_completed]: [1]\n",
+			want: map[string]string{"ro.product.model": "Pixel 6", "sys.boot_completed": "1"},
+		},
+		{
+			name: "empty value",
+			out:  "[persist.sys.locale]: []\r\n[ro.debuggable]: [0]\r\n",
+			want: map[string]string{"persist.sys.locale": "", "ro.debuggable": "0"},
+		},
+		{
+			name: "multi-line value",
+			out:  "[ro.build.fingerprint]: [a]\n[ro.x.banner]: [first\nsecond line\n\nlast]\n[ro.y]: [z]\n",
+			want: map[string]string{"ro.build.fingerprint": "a", "ro.x.banner": "first\nsecond line\n\nlast", "ro.y": "z"},
+		},
+		{
+			name: "brackets in value",
+			out:  "[ro.a]: [[x]]\n[ro.b]: [x] y]\n[ro.c]: [line one]\nline two]\n[ro.d]: [ok]",
+			want: map[string]string{"ro.a": "[x]", "ro.b": "x] y", "ro.c": "line one]\nline two", "ro.d": "ok"},
+		},
+		{
+			name: "truncated output",
+			out:  "[ro.a]: [1]\n[ro.b]: [no end",
+			want: map[string]string{"ro.a": "1", "ro.b": "no end"},
+		},
+	}
+	for _, tt := range tests {
+		if got := parseGetprop(tt.out); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: parseGetprop = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package adb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// recordSegmentLimit is the longest single screenrecord run most devices allow.
+const recordSegmentLimit = 180 * time.Second
+
+// ErrFFmpegMissing is reported (as a note, not a failure) when a multi-segment
+// recording cannot be joined because ffmpeg is not installed.
+var ErrFFmpegMissing = errors.New("ffmpeg not found; segments were kept as separate files")
+
+// RecordOptions configures screenrecord.
+type RecordOptions struct {
+	BitRate   int           // bits per second, 0 = device default
+	Size      string        // "WIDTHxHEIGHT", empty = native resolution
+	TimeLimit time.Duration // total length, 0 = until stopped
+	BugReport bool          // overlay timestamps and frame info (--bugreport)
+}
+
+func (o RecordOptions) script(limit time.Duration, remote string) string {
+	args := []string{"screenrecord"}
+	if o.BitRate > 0 {
+		args = append(args, "--bit-rate", strconv.Itoa(o.BitRate))
+	}
+	if s := strings.TrimSpace(o.Size); s != "" {
+		args = append(args, "--size", shellQuote(s))
+	}
+	if o.BugReport {
+		args = append(args, "--bugreport")
+	}
+	secs := max(int(limit.Round(time.Second)/time.Second), 1)
+	args = append(args, "--time-limit", strconv.Itoa(secs), shellQuote(remote))
+	return strings.Join(args, " ")
+}
+
+// Recording phases.
+const (
+	RecordRecording = "recording"
+	RecordPulling   = "pulling"
+	RecordDone      = "done"
+)
+
+// Recording is a running (or finished) screen recording of one device.
+// Recordings longer than the per-run limit are chained as segments and joined
+// with ffmpeg after they are pulled.
+type Recording struct {
+	Serial  string
+	Local   string // final .mp4 path on the host
+	Started time.Time
+
+	mgr  *Manager
+	opts RecordOptions
+	done chan struct{}
+
+	mu       sync.Mutex
+	phase    string
+	segments []string // remote segment files
+	current  string   // segment being recorded
+	stopped  bool
+	files    []string // local result files
+	note     string
+	err      error
+}
+
+// StartRecording begins recording serial into localPath (an .mp4 on the host).
+func (m *Manager) StartRecording(serial string, opts RecordOptions, localPath string) (*Recording, error) {
+	if strings.TrimSpace(serial) == "" || strings.TrimSpace(localPath) == "" {
+		return nil, errors.New("invalid recording arguments")
+	}
+	if err := os.MkdirAll(filepath.Dir(localPath), 0o755); err != nil {
+		return nil, err
+	}
+	r := &Recording{
+		Serial:  serial,
+		Local:   localPath,
+		Started: time.Now(),
+		mgr:     m,
+		opts:    opts,
+		done:    make(chan struct{}),
+		phase:   RecordRecording,
+	}
+	go r.run()
+	return r, nil
+}
+
+// Status returns the current phase and the number of segments recorded so far.
+func (r *Recording) Status() (string, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.phase, len(r.segments)
+}
+
+// Done is closed once the recording has been pulled and cleaned up.
+func (r *Recording) Done() <-chan struct{} {
+	return r.done
+}
+
+// Result returns the local files, an optional non-fatal note (e.g. ErrFFmpegMissing)
+// and the error, if any. Only meaningful after Done is closed.
+func (r *Recording) Result() ([]string, string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.files, r.note, r.err
+}
+
+// Stop ends the recording. screenrecord is sent SIGINT so it finalizes the
+// MP4 instead of leaving an unplayable file.
+func (r *Recording) Stop() {
+	r.mu.Lock()
+	if r.stopped {
+		r.mu.Unlock()
+		return
+	}
+	r.stopped = true
+	cur := r.current
+	r.mu.Unlock()
+	if cur == "" {
+		return
+	}
+	// The segment may not have started on the device yet; retry until it exits.
+	// Only the screenrecord writing our segment file is signalled, never
+	// recordings started by other windows or tools.
+	go func() {
+		for i := 0; i < 10; i++ {
+			_, _ = r.mgr.ExecSerial(r.Serial, "shell", "pkill -INT -f "+shellQuote(cur))
+			time.Sleep(500 * time.Millisecond)
+			r.mu.Lock()
+			running := r.current == cur
+			r.mu.Unlock()
+			if !running {
+				return
+			}
+		}
+	}()
+}
+
+func (r *Recording) run() {
+	defer close(r.done)
+	base := path.Join(editStagingDir, "adb-gui-rec-"+strconv.FormatInt(r.Started.UnixNano(), 10))
+	for n := 1; ; n++ {
+		limit := recordSegmentLimit
+		if r.opts.TimeLimit > 0 {
+			remaining := r.opts.TimeLimit - time.Since(r.Started)
+			if remaining < time.Second {
+				break
+			}
+			limit = min(limit, remaining)
+		}
+		seg := fmt.Sprintf("%s-%d.mp4", base, n)
+		r.mu.Lock()
+		if r.stopped {
+			r.mu.Unlock()
+			break
+		}
+		r.current = seg
+		r.segments = append(r.segments, seg)
+		r.mu.Unlock()
+
+		segStart := time.Now()
+		cmd := r.mgr.commandSerial(context.Background(), r.Serial, "shell", r.opts.script(limit, seg))
+		var out bytes.Buffer
+		cmd.Stdout = &out
+		cmd.Stderr = &out
+		err := cmd.Run()
+
+		r.mu.Lock()
+		r.current = ""
+		stopped := r.stopped
+		r.mu.Unlock()
+		if stopped {
+			break
+		}
+		// A run that fails right away (bad size, no screenrecord, ...) is fatal
+		if err != nil && time.Since(segStart) < 2*time.Second {
+			r.mu.Lock()
+			r.err = fmt.Errorf("%v: %s", err, strings.TrimSpace(out.String()))
+			r.segments = r.segments[:len(r.segments)-1]
+			r.mu.Unlock()
+			break
+		}
+		if r.opts.TimeLimit == 0 && err != nil {
+			// Device disconnected or screenrecord crashed: keep what we have
+			r.mu.Lock()
+			r.err = fmt.Errorf("%v: %s", err, strings.TrimSpace(out.String()))
+			r.mu.Unlock()
+			break
+		}
+	}
+
+	r.mu.Lock()
+	r.phase = RecordPulling
+	segments := append([]string(nil), r.segments...)
+	r.mu.Unlock()
+
+	files, note, err := r.collect(segments)
+	r.mu.Lock()
+	r.phase = RecordDone
+	r.files, r.note = files, note
+	if r.err == nil {
+		r.err = err
+	}
+	r.mu.Unlock()
+}
+
+// collect pulls the segments, removes them from the device and joins them.
+func (r *Recording) collect(segments []string) ([]string, string, error) {
+	if len(segments) == 0 {
+		return nil, "", nil
+	}
+	defer func() {
+		quoted := make([]string, len(segments))
+		for i, s := range segments {
+			quoted[i] = shellQuote(s)
+		}
+		_, _ = r.mgr.ExecSerial(r.Serial, "shell", "rm -f "+strings.Join(quoted, " "))
+	}()
+
+	stem := strings.TrimSuffix(r.Local, filepath.Ext(r.Local))
+	var parts []string
+	var firstErr error
+	for i, seg := range segments {
+		dst := r.Local
+		if len(segments) > 1 {
+			dst = fmt.Sprintf("%s_part%d.mp4", stem, i+1)
+		}
+		if out, err := r.mgr.ExecSerial(r.Serial, "pull", seg, dst); err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%v: %s", err, strings.TrimSpace(out))
+			}
+			continue
+		}
+		parts = append(parts, dst)
+	}
+	if len(parts) <= 1 {
+		if len(parts) == 1 && parts[0] != r.Local {
+			if err := os.Rename(parts[0], r.Local); err == nil {
+				parts[0] = r.Local
+			}
+		}
+		return parts, "", firstErr
+	}
+	if err := concatMP4(parts, r.Local); err != nil {
+		return parts, err.Error(), firstErr
+	}
+	for _, p := range parts {
+		os.Remove(p)
+	}
+	return []string{r.Local}, "", firstErr
+}
+
+// concatMP4 joins MP4 files without re-encoding using ffmpeg's concat demuxer.
+func concatMP4(parts []string, out string) error {
+	ff, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return ErrFFmpegMissing
+	}
+	list, err := os.CreateTemp("", "adb-gui-concat-*.txt")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(list.Name())
+	for _, p := range parts {
+		abs, _ := filepath.Abs(p)
+		fmt.Fprintf(list, "file '%s'\n", strings.ReplaceAll(filepath.ToSlash(abs), "'", `'\''`))
+	}
+	if err := list.Close(); err != nil {
+		return err
+	}
+	cmd := exec.Command(ff, "-y", "-loglevel", "error", "-f", "concat", "-safe", "0", "-i", list.Name(), "-c", "copy", out)
+	hideWindowsWindow(cmd)
+	if o, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("ffmpeg: %v: %s", err, strings.TrimSpace(string(o)))
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package adb
+
+import (
+	"errors"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RootMethod says how privileged file operations are performed on a device.
+type RootMethod string
+
+const (
+	RootNone RootMethod = ""     // unprivileged shell user
+	RootADBD RootMethod = "adbd" // "adb root": adbd itself runs as uid 0
+	RootSu   RootMethod = "su"   // commands are wrapped in "su -c" (Magisk, KernelSU, ...)
+)
+
+// RootInfo is the result of probing a device for root access.
+type RootInfo struct {
+	Method   RootMethod
+	Provider string // e.g. "Magisk 27.0", "KernelSU", "adbd"
+}
+
+// DetectRoot checks whether adbd already runs as root and otherwise whether su works.
+func (m *Manager) DetectRoot(serial string) (RootInfo, string) {
+	out, err := m.ExecSerial(serial, "shell", "id")
+	if err == nil && strings.Contains(out, "uid=0") {
+		return RootInfo{Method: RootADBD, Provider: "adbd"}, out
+	}
+	out2, err := m.ExecSerial(serial, "shell", "su", "-c", "id")
+	out += out2
+	if err != nil || !strings.Contains(out2, "uid=0") {
+		return RootInfo{}, out
+	}
+	info := RootInfo{Method: RootSu, Provider: "su"}
+	ver, _ := m.ExecSerial(serial, "shell", "su", "-v")
+	v := strings.TrimSpace(ver)
+	switch lo := strings.ToLower(v); {
+	case strings.Contains(lo, "magisk"):
+		info.Provider = "Magisk " + strings.TrimSpace(strings.Split(v, ":")[0])
+	case strings.Contains(lo, "kernelsu"):
+		info.Provider = "KernelSU"
+	case v != "":
+		info.Provider = "su " + firstField(v)
+	}
+	return info, out + ver
+}
+
+func firstField(s string) string {
+	if f := strings.Fields(s); len(f) > 0 {
+		return f[0]
+	}
+	return ""
+}
+
+// RestartADBDAsRoot runs "adb root" (userdebug/eng builds only) and waits for the device.
+func (m *Manager) RestartADBDAsRoot(serial string) (string, error) {
+	out, err := m.ExecSerial(serial, "root")
+	if err != nil {
+		return out, err
+	}
+	if strings.Contains(out, "cannot run as root") {
+		return out, errors.New(strings.TrimSpace(out))
+	}
+	o2, _ := m.ExecSerial(serial, "wait-for-device")
+	return out + o2, nil
+}
+
+// SetRootMode enables (method != RootNone) or disables root file browsing for a device.
+func (m *Manager) SetRootMode(serial string, method RootMethod) {
+	m.rootMu.Lock()
+	defer m.rootMu.Unlock()
+	if m.rootModes == nil {
+		m.rootModes = map[string]RootMethod{}
+	}
+	if method == RootNone {
+		delete(m.rootModes, serial)
+		return
+	}
+	m.rootModes[serial] = method
+}
+
+// RootMode returns how privileged operations are done for serial (RootNone when disabled).
+func (m *Manager) RootMode(serial string) RootMethod {
+	m.rootMu.Lock()
+	defer m.rootMu.Unlock()
+	return m.rootModes[serial]
+}
+
+// useSu reports whether file operations for serial must be wrapped in su.
+func (m *Manager) useSu(serial string) bool {
+	return m.RootMode(serial) == RootSu
+}
+
+// stagingPath returns a unique path in /data/local/tmp for root-mode transfers.
+func stagingPath(kind string) string {
+	return path.Join(editStagingDir, "adb-gui-"+kind+"-"+strconv.FormatInt(time.Now().UnixNano(), 10))
+}
+
+// pushAsRoot pushes to a world-writable staging dir, then copies into place
+// with su. The copy gets the owner and SELinux context of remoteDir, so files
+// pushed into an app's data directory stay readable by the app.
+func (m *Manager) pushAsRoot(serial, localPath, remoteDir string) (string, error) {
+	staged := stagingPath("push")
+	if out, err := m.ExecSerial(serial, "shell", "mkdir", "-p", shellQuote(staged)); err != nil {
+		return out, err
+	}
+	out, err := m.ExecSerial(serial, "push", localPath, staged+"/")
+	if err != nil {
+		_, _ = m.ExecSerial(serial, "shell", "rm", "-rf", shellQuote(staged))
+		return out, err
+	}
+	dir, target := shellQuote(remoteDir), shellQuote(path.Join(remoteDir, filepath.Base(localPath)))
+	script := "owner=$(stat -c %u:%g " + dir + ") && ctx=$(stat -c %C " + dir + " 2>/dev/null);" +
+		" cp -R " + shellQuote(staged) + "/. " + dir + "/ && chown -R \"$owner\" " + target +
+		" && { [ -z \"$ctx\" ] || chcon -R \"$ctx\" " + target + " 2>/dev/null || restorecon -R " + target + "; };" +
+		" rc=$?; rm -rf " + shellQuote(staged) + "; exit $rc"
+	o2, err := m.ShellAs(serial, true, script)
+	return out + o2, err
+}
+
+// pullAsRoot copies remotePath with su into a staging dir readable by the shell
+// user (fixed ownership and a+rX permissions), pulls it and removes the copy.
+func (m *Manager) pullAsRoot(serial, remotePath, localDir string, preserve bool) (string, error) {
+	staged := stagingPath("pull")
+	cpFlags := "-R"
+	if preserve {
+		cpFlags = "-Rp"
+	}
+	script := "mkdir -p " + shellQuote(staged) + " && cp " + cpFlags + " " + shellQuote(remotePath) + " " + shellQuote(staged) + "/" +
+		" && chown -R shell:shell " + shellQuote(staged) + " && chmod -R a+rX " + shellQuote(staged)
+	out, err := m.ShellAs(serial, true, script)
+	if err == nil {
+		var o2 string
+		args := []string{"pull"}
+		if preserve {
+			args = append(args, "-a")
+		}
+		args = append(args, path.Join(staged, path.Base(remotePath)), localDir)
+		o2, err = m.ExecSerial(serial, args...)
+		out += o2
+	}
+	_, _ = m.ShellAs(serial, true, "rm -rf "+shellQuote(staged))
+	return out, err
+}
@@ -0,0 +1,165 @@
+package adb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SettingsNamespaces are the tables of the Android settings provider.
+var SettingsNamespaces = []string{"system", "secure", "global"}
+
+// SettingChange is one "settings put" (or delete) operation.
+type SettingChange struct {
+	Namespace string `json:"namespace"`
+	Key       string `json:"key"`
+	Value     string `json:"value,omitempty"`
+	Delete    bool   `json:"delete,omitempty"`
+}
+
+func settingsArgs(user int, args ...string) []string {
+	return append([]string{"shell", "settings", "--user", strconv.Itoa(user)}, args...)
+}
+
+// ListSettings returns all entries of a namespace for a user. Values that
+// contain newlines continue on lines without a "key=" prefix.
+func (m *Manager) ListSettings(serial, namespace string, user int) (map[string]string, string, error) {
+	out, err := m.ExecSerial(serial, settingsArgs(user, "list", namespace)...)
+	if err != nil {
+		return nil, out, err
+	}
+	res := make(map[string]string)
+	last := ""
+	for _, ln := range strings.Split(strings.ReplaceAll(out, "\r\n", "\n"), "\n") {
+		k, v, ok := strings.Cut(ln, "=")
+		if ok && k != "" && !strings.ContainsAny(k, " \t") {
+			res[k] = v
+			last = k
+		} else if last != "" && ln != "" {
+			res[last] += "\n" + ln
+		}
+	}
+	return res, out, nil
+}
+
+// GetSetting reads one entry; ok is false when the key is not set.
+func (m *Manager) GetSetting(serial, namespace, key string, user int) (value string, ok bool, err error) {
+	out, err := m.ExecSerial(serial, settingsArgs(user, "get", namespace, shellQuote(key))...)
+	if err != nil {
+		return "", false, fmt.Errorf("%v: %s", err, strings.TrimSpace(out))
+	}
+	out = strings.TrimRight(out, "\r\n")
+	if out == "null" {
+		return "", false, nil
+	}
+	return out, true, nil
+}
+
+// PutSetting writes one entry.
+func (m *Manager) PutSetting(serial, namespace, key, value string, user int) (string, error) {
+	return m.settingsCmd(serial, user, "put", namespace, shellQuote(key), shellQuote(value))
+}
+
+// DeleteSetting removes one entry.
+func (m *Manager) DeleteSetting(serial, namespace, key string, user int) (string, error) {
+	return m.settingsCmd(serial, user, "delete", namespace, shellQuote(key))
+}
+
+// settingsCmd runs a settings command; the tool exits 0 on most failures, so
+// exception text in the output is turned into an error.
+func (m *Manager) settingsCmd(serial string, user int, args ...string) (string, error) {
+	out, err := m.ExecSerial(serial, settingsArgs(user, args...)...)
+	if err == nil && (strings.Contains(out, "Exception") || strings.Contains(out, "Invalid")) {
+		err = errors.New(strings.TrimSpace(firstLine(out)))
+	}
+	return out, err
+}
+
+func firstLine(s string) string {
+	ln, _, _ := strings.Cut(s, "\n")
+	return ln
+}
+
+// SettingsBackup records the values a preset overwrote on one device so the
+// change can be rolled back.
+type SettingsBackup struct {
+	Serial   string          `json:"serial"`
+	User     int             `json:"user"`
+	Preset   string          `json:"preset"`
+	Applied  time.Time       `json:"applied"`
+	Original []SettingChange `json:"original"` // applying these restores the previous state
+}
+
+// ApplySettings records the current value of each entry and then applies the
+// changes. On error the returned backup covers the entries already changed.
+func (m *Manager) ApplySettings(serial string, user int, preset string, changes []SettingChange) (*SettingsBackup, string, error) {
+	b := &SettingsBackup{Serial: serial, User: user, Preset: preset, Applied: time.Now()}
+	var log strings.Builder
+	for _, c := range changes {
+		old, ok, err := m.GetSetting(serial, c.Namespace, c.Key, user)
+		if err != nil {
+			return b, log.String(), err
+		}
+		var out string
+		if c.Delete {
+			out, err = m.DeleteSetting(serial, c.Namespace, c.Key, user)
+		} else {
+			out, err = m.PutSetting(serial, c.Namespace, c.Key, c.Value, user)
+		}
+		log.WriteString(out)
+		if err != nil {
+			return b, log.String(), fmt.Errorf("%s %s: %w", c.Namespace, c.Key, err)
+		}
+		b.Original = append(b.Original, SettingChange{Namespace: c.Namespace, Key: c.Key, Value: old, Delete: !ok})
+	}
+	return b, log.String(), nil
+}
+
+// Rollback restores the values recorded in the backup, newest change first.
+func (m *Manager) Rollback(b *SettingsBackup) (string, error) {
+	var log strings.Builder
+	for i := len(b.Original) - 1; i >= 0; i-- {
+		c := b.Original[i]
+		var out string
+		var err error
+		if c.Delete {
+			out, err = m.DeleteSetting(b.Serial, c.Namespace, c.Key, b.User)
+		} else {
+			out, err = m.PutSetting(b.Serial, c.Namespace, c.Key, c.Value, b.User)
+		}
+		log.WriteString(out)
+		if err != nil {
+			return log.String(), fmt.Errorf("%s %s: %w", c.Namespace, c.Key, err)
+		}
+	}
+	return log.String(), nil
+}
+
+// Save writes the backup as indented JSON.
+func (b *SettingsBackup) Save(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// LoadSettingsBackup reads a backup saved with Save.
+func LoadSettingsBackup(path string) (*SettingsBackup, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var b SettingsBackup
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, err
+	}
+	if b.Serial == "" {
+		return nil, errors.New("not a settings backup")
+	}
+	return &b, nil
+}
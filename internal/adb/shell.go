@@ -0,0 +1,92 @@
+package adb
+
+import (
+	"context"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// ShellSession is an interactive "adb shell" process. Output (stdout and
+// stderr merged) is read with Read and input is sent with Write.
+type ShellSession struct {
+	Serial string
+	PTY    bool // a pseudo-terminal was allocated on the device (remote echo, prompt, job control)
+	Root   bool
+
+	cmd    *exec.Cmd
+	cancel context.CancelFunc
+	stdin  io.WriteCloser
+	out    io.ReadCloser
+}
+
+// HasShellV2 reports whether the device supports the shell protocol, which is
+// needed for the -t/-T flags (Android 7.0+).
+func (m *Manager) HasShellV2(serial string) bool {
+	out, err := m.ExecSerial(serial, "features")
+	return err == nil && strings.Contains(out, "shell_v2")
+}
+
+// StartShell opens an interactive shell. With pty a terminal is forced on the
+// device ("shell -tt"); otherwise the shell runs in line mode ("shell -T") and
+// is started with -i so it still prints a prompt. Devices without the shell
+// protocol always get the legacy PTY shell. With root the shell is "su".
+func (m *Manager) StartShell(serial string, pty, root bool) (*ShellSession, error) {
+	args := []string{"shell"}
+	switch {
+	case !m.HasShellV2(serial):
+		pty = true
+	case pty:
+		args = append(args, "-tt")
+	default:
+		args = append(args, "-T")
+	}
+	switch {
+	case root:
+		args = append(args, "su")
+	case !pty:
+		args = append(args, "sh", "-i")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd := m.commandSerial(ctx, serial, args...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	cmd.Stderr = cmd.Stdout
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return nil, err
+	}
+	return &ShellSession{Serial: serial, PTY: pty, Root: root, cmd: cmd, cancel: cancel, stdin: stdin, out: out}, nil
+}
+
+// Read reads shell output.
+func (s *ShellSession) Read(p []byte) (int, error) {
+	return s.out.Read(p)
+}
+
+// Write sends input to the shell.
+func (s *ShellSession) Write(p []byte) (int, error) {
+	return s.stdin.Write(p)
+}
+
+// Wait waits for the shell to exit; call it after Read returns an error.
+func (s *ShellSession) Wait() error {
+	err := s.cmd.Wait()
+	s.cancel()
+	return err
+}
+
+// Close ends the session by closing stdin and killing the adb process.
+func (s *ShellSession) Close() {
+	s.stdin.Close()
+	s.cancel()
+}
@@ -0,0 +1,129 @@
+package adb
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Snapshot kinds.
+const (
+	SnapshotProps  = "getprop"
+	SnapshotGetVar = "getvar"
+)
+
+// Snapshot is a saved set of device properties or fastboot variables.
+type Snapshot struct {
+	Kind        string            `json:"kind"` // SnapshotProps or SnapshotGetVar
+	Serial      string            `json:"serial"`
+	Fingerprint string            `json:"fingerprint,omitempty"`
+	Taken       time.Time         `json:"taken"`
+	Values      map[string]string `json:"values"`
+}
+
+// TakeSnapshot reads getprop (adb devices) or getvar all (fastboot devices).
+func (m *Manager) TakeSnapshot(serial, kind string) (*Snapshot, error) {
+	s := &Snapshot{Kind: kind, Serial: serial, Taken: time.Now()}
+	var err error
+	if kind == SnapshotGetVar {
+		var info *GetVarInfo
+		if info, _, err = m.GetVarAll(serial); err == nil {
+			s.Values = info.Flatten()
+			s.Fingerprint = s.Values["version-bootloader"]
+		}
+	} else {
+		s.Values, _, err = m.GetProps(serial)
+		s.Fingerprint = s.Values["ro.build.fingerprint"]
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Save writes the snapshot as indented JSON.
+func (s *Snapshot) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// LoadSnapshot reads a snapshot saved with Save.
+func LoadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	if s.Values == nil {
+		return nil, errors.New("not a property snapshot")
+	}
+	return &s, nil
+}
+
+// Diff kinds.
+const (
+	DiffAdded   = "added"
+	DiffRemoved = "removed"
+	DiffChanged = "changed"
+)
+
+// DiffEntry is one key that differs between two snapshots.
+type DiffEntry struct {
+	Key  string
+	Kind string // DiffAdded, DiffRemoved or DiffChanged
+	Old  string
+	New  string
+}
+
+// DiffValues compares two key/value sets, sorted by key. It works the same for
+// getprop and getvar maps.
+func DiffValues(old, new map[string]string) []DiffEntry {
+	var res []DiffEntry
+	for k, ov := range old {
+		nv, ok := new[k]
+		switch {
+		case !ok:
+			res = append(res, DiffEntry{Key: k, Kind: DiffRemoved, Old: ov})
+		case nv != ov:
+			res = append(res, DiffEntry{Key: k, Kind: DiffChanged, Old: ov, New: nv})
+		}
+	}
+	for k, nv := range new {
+		if _, ok := old[k]; !ok {
+			res = append(res, DiffEntry{Key: k, Kind: DiffAdded, New: nv})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Key < res[j].Key })
+	return res
+}
+
+// buildKeyParts match properties and fastboot variables that identify a build.
+var buildKeyParts = []string{
+	"fingerprint", "build.id", "build.display.id", "build.version.", "build.date",
+	"build.type", "build.tags", "security_patch", "bootloader", "baseband",
+	"version-", "product", "secure", "unlocked", "current-slot",
+}
+
+// IsBuildKey reports whether a key is one of the commonly compared build
+// identifiers (fingerprints, versions, patch levels, bootloader and baseband).
+func IsBuildKey(key string) bool {
+	k := strings.ToLower(key)
+	if strings.HasPrefix(k, "ro.product.") && !strings.Contains(k, "fingerprint") {
+		return k == "ro.product.model" || k == "ro.product.device" || k == "ro.product.name"
+	}
+	for _, p := range buildKeyParts {
+		if strings.Contains(k, p) {
+			return true
+		}
+	}
+	return false
+}
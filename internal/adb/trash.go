@@ -0,0 +1,233 @@
+package adb
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// trashDirName is the hidden directory created at the root of each storage
+// volume. Deleted items live in <root>/.adb-gui-trash/files and a small
+// "<id>.info" record in <root>/.adb-gui-trash/info remembers where they came from.
+const trashDirName = ".adb-gui-trash"
+
+// userStorageRe matches the root of a user-accessible storage volume.
+var userStorageRe = regexp.MustCompile(`^(/storage/emulated/\d+|/storage/self/primary|/storage/[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}|/sdcard|/mnt/media_rw/[^/]+)(/|$)`)
+
+// TrashEntry describes one item sitting in a volume's trash directory.
+type TrashEntry struct {
+	ID           string
+	OriginalPath string
+	TrashPath    string
+	DeletedAt    time.Time
+}
+
+// VolumeRoot returns the storage volume root containing p (e.g. /storage/emulated/0).
+// ok is false for paths outside user storage such as /system or /data.
+func VolumeRoot(p string) (string, bool) {
+	p = path.Clean(p)
+	mm := userStorageRe.FindStringSubmatch(p)
+	if len(mm) < 2 {
+		return "", false
+	}
+	return mm[1], true
+}
+
+// IsUserStorage reports whether p is inside (but not equal to) a user storage volume,
+// i.e. whether it can be moved into that volume's trash.
+func IsUserStorage(p string) bool {
+	root, ok := VolumeRoot(p)
+	if !ok {
+		return false
+	}
+	p = path.Clean(p)
+	if p == root {
+		return false
+	}
+	// Never trash the trash itself
+	return !strings.HasPrefix(p, path.Join(root, trashDirName))
+}
+
+// TrashDir returns the trash directory for the volume containing p.
+func TrashDir(p string) (string, bool) {
+	root, ok := VolumeRoot(p)
+	if !ok {
+		return "", false
+	}
+	return path.Join(root, trashDirName), true
+}
+
+// MoveToTrash moves a remote file or directory into its volume's trash and records its original path.
+func (m *Manager) MoveToTrash(serial, remotePath string) (TrashEntry, string, error) {
+	if strings.TrimSpace(remotePath) == "" {
+		return TrashEntry{}, "", errors.New("invalid delete arguments")
+	}
+	remotePath = path.Clean(remotePath)
+	if !IsUserStorage(remotePath) {
+		return TrashEntry{}, "", fmt.Errorf("%s is not on a user storage volume", remotePath)
+	}
+	dir, _ := TrashDir(remotePath)
+	now := time.Now()
+	id := strconv.FormatInt(now.UnixNano(), 10) + "-" + path.Base(remotePath)
+	entry := TrashEntry{
+		ID:           id,
+		OriginalPath: remotePath,
+		TrashPath:    path.Join(dir, "files", id),
+		DeletedAt:    now,
+	}
+	info := path.Join(dir, "info", id+".info")
+	script := fmt.Sprintf("mkdir -p %s %s && mv -- %s %s && printf '%%s\\n' %s %s > %s && echo OK",
+		shellQuote(path.Join(dir, "files")), shellQuote(path.Join(dir, "info")),
+		shellQuote(remotePath), shellQuote(entry.TrashPath),
+		shellQuote("path="+remotePath), shellQuote("deleted="+strconv.FormatInt(now.Unix(), 10)),
+		shellQuote(info))
+	out, err := m.ShellAs(serial, false, script)
+	if err == nil && !strings.Contains(out, "OK") {
+		err = errors.New(strings.TrimSpace(out))
+	}
+	return entry, out, err
+}
+
+// MoveMultipleToTrash moves several remote paths into trash, returning entries for those that succeeded.
+func (m *Manager) MoveMultipleToTrash(serial string, remotePaths []string) ([]TrashEntry, string, error) {
+	if len(remotePaths) == 0 {
+		return nil, "", errors.New("no files to delete")
+	}
+	var entries []TrashEntry
+	var outs []string
+	var firstErr error
+	for _, rp := range remotePaths {
+		e, out, err := m.MoveToTrash(serial, rp)
+		outs = append(outs, out)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, strings.Join(outs, "\n"), firstErr
+}
+
+// ListTrash lists the trash of the volume containing volumePath, newest first.
+func (m *Manager) ListTrash(serial, volumePath string) ([]TrashEntry, string, error) {
+	dir, ok := TrashDir(volumePath)
+	if !ok {
+		return nil, "", fmt.Errorf("%s is not on a user storage volume", volumePath)
+	}
+	script := fmt.Sprintf(`for f in %s/*.info; do [ -f "$f" ] && echo "id=${f##*/}" && cat "$f" && echo; done`,
+		shellQuote(path.Join(dir, "info")))
+	out, err := m.ShellAs(serial, false, script)
+	if err != nil {
+		return nil, out, err
+	}
+	entries := parseTrashInfo(out, dir)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].DeletedAt.After(entries[j].DeletedAt)
+	})
+	return entries, out, nil
+}
+
+// parseTrashInfo parses blank-line separated "key=value" blocks written by MoveToTrash.
+func parseTrashInfo(out, dir string) []TrashEntry {
+	var res []TrashEntry
+	var cur TrashEntry
+	flush := func() {
+		if cur.ID != "" && cur.OriginalPath != "" {
+			cur.TrashPath = path.Join(dir, "files", cur.ID)
+			res = append(res, cur)
+		}
+		cur = TrashEntry{}
+	}
+	for _, ln := range strings.Split(out, "\n") {
+		ln = strings.TrimRight(ln, "\r")
+		if strings.TrimSpace(ln) == "" {
+			flush()
+			continue
+		}
+		kv := strings.SplitN(ln, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "id":
+			cur.ID = strings.TrimSuffix(kv[1], ".info")
+		case "path":
+			cur.OriginalPath = kv[1]
+		case "deleted":
+			if ts, err := strconv.ParseInt(kv[1], 10, 64); err == nil {
+				cur.DeletedAt = time.Unix(ts, 0)
+			}
+		}
+	}
+	flush()
+	return res
+}
+
+// RestoreFromTrash moves a trashed item back to its original path.
+// It refuses to overwrite anything that has since been created at that path.
+func (m *Manager) RestoreFromTrash(serial string, e TrashEntry) (string, error) {
+	if e.ID == "" || e.OriginalPath == "" {
+		return "", errors.New("invalid trash entry")
+	}
+	dir, ok := TrashDir(e.OriginalPath)
+	if !ok {
+		return "", fmt.Errorf("%s is not on a user storage volume", e.OriginalPath)
+	}
+	script := fmt.Sprintf("if [ -e %s ]; then echo EXISTS; else mkdir -p %s && mv -- %s %s && rm -f %s && echo OK; fi",
+		shellQuote(e.OriginalPath), shellQuote(path.Dir(e.OriginalPath)),
+		shellQuote(e.TrashPath), shellQuote(e.OriginalPath),
+		shellQuote(path.Join(dir, "info", e.ID+".info")))
+	out, err := m.ShellAs(serial, false, script)
+	if err != nil {
+		return out, err
+	}
+	if strings.Contains(out, "EXISTS") {
+		return out, fmt.Errorf("%s already exists", e.OriginalPath)
+	}
+	if !strings.Contains(out, "OK") {
+		return out, errors.New(strings.TrimSpace(out))
+	}
+	return out, nil
+}
+
+// RestoreMultipleFromTrash restores several trash entries.
+func (m *Manager) RestoreMultipleFromTrash(serial string, entries []TrashEntry) (string, error) {
+	var outs []string
+	var firstErr error
+	for _, e := range entries {
+		out, err := m.RestoreFromTrash(serial, e)
+		outs = append(outs, out)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return strings.Join(outs, "\n"), firstErr
+}
+
+// PurgeTrash permanently deletes a trashed item and its info record.
+func (m *Manager) PurgeTrash(serial string, e TrashEntry) (string, error) {
+	if e.ID == "" {
+		return "", errors.New("invalid trash entry")
+	}
+	dir, ok := TrashDir(e.OriginalPath)
+	if !ok {
+		return "", fmt.Errorf("%s is not on a user storage volume", e.OriginalPath)
+	}
+	return m.ShellAs(serial, false, "rm -rf -- "+shellQuote(e.TrashPath)+" "+shellQuote(path.Join(dir, "info", e.ID+".info")))
+}
+
+// EmptyTrash permanently deletes everything in the trash of the volume containing volumePath.
+func (m *Manager) EmptyTrash(serial, volumePath string) (string, error) {
+	dir, ok := TrashDir(volumePath)
+	if !ok {
+		return "", fmt.Errorf("%s is not on a user storage volume", volumePath)
+	}
+	return m.ShellAs(serial, false, "rm -rf -- "+shellQuote(dir))
+}
@@ -0,0 +1,208 @@
+package adb
+
+import (
+	"errors"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// VolumeUsage is one mounted filesystem as reported by "df -k".
+type VolumeUsage struct {
+	Filesystem string
+	MountPoint string
+	Total      int64 // bytes
+	Used       int64 // bytes
+	Free       int64 // bytes
+}
+
+// DirUsage is the recursive size of one directory entry as reported by "du".
+type DirUsage struct {
+	Path  string
+	Size  int64 // bytes
+	IsDir bool
+}
+
+// AppStorage is the per-package breakdown from "dumpsys diskstats".
+type AppStorage struct {
+	Package string
+	Code    int64 // bytes (APK + libs)
+	Data    int64 // bytes
+	Cache   int64 // bytes
+}
+
+// Total returns code + data + cache.
+func (a AppStorage) Total() int64 {
+	return a.Code + a.Data + a.Cache
+}
+
+// DiskFree returns mounted filesystems with their total/used/free space.
+// Pseudo filesystems and zero-sized mounts are skipped.
+func (m *Manager) DiskFree(serial string) ([]VolumeUsage, string, error) {
+	out, err := m.ExecSerial(serial, "shell", "df", "-k")
+	if err != nil {
+		return nil, out, err
+	}
+	return parseDf(out), out, nil
+}
+
+func parseDf(out string) []VolumeUsage {
+	var res []VolumeUsage
+	seen := map[string]bool{}
+	var carry string
+	for _, ln := range strings.Split(out, "\n") {
+		ln = strings.TrimSpace(ln)
+		if ln == "" || strings.HasPrefix(ln, "Filesystem") {
+			continue
+		}
+		f := strings.Fields(ln)
+		// busybox wraps long device names onto their own line
+		if len(f) == 1 {
+			carry = f[0]
+			continue
+		}
+		if carry != "" {
+			f = append([]string{carry}, f...)
+			carry = ""
+		}
+		if len(f) < 6 {
+			continue
+		}
+		total, err1 := strconv.ParseInt(f[1], 10, 64)
+		used, err2 := strconv.ParseInt(f[2], 10, 64)
+		free, err3 := strconv.ParseInt(f[3], 10, 64)
+		if err1 != nil || err2 != nil || err3 != nil || total == 0 {
+			continue
+		}
+		switch f[0] {
+		case "tmpfs", "proc", "sysfs", "none", "cgroup", "debugfs", "tracefs":
+			continue
+		}
+		mount := strings.Join(f[5:], " ")
+		if seen[mount] {
+			continue
+		}
+		seen[mount] = true
+		res = append(res, VolumeUsage{
+			Filesystem: f[0],
+			MountPoint: mount,
+			Total:      total * 1024,
+			Used:       used * 1024,
+			Free:       free * 1024,
+		})
+	}
+	return res
+}
+
+// DirSizes returns the recursive size of every direct child of dir, files
+// included, largest first. It uses "du -k -a -d 1" (toybox and busybox) and
+// falls back to "du -k -s dir/*".
+func (m *Manager) DirSizes(serial, dir string) ([]DirUsage, string, error) {
+	if strings.TrimSpace(dir) == "" {
+		return nil, "", errors.New("empty path")
+	}
+	dir = path.Clean(dir)
+	// Errors for unreadable subdirectories are expected; keep stdout only
+	out, err := m.ShellAs(serial, false, "du -k -a -d 1 -- "+shellQuote(dir)+" 2>/dev/null")
+	if strings.TrimSpace(out) == "" {
+		out, err = m.ShellAs(serial, false, "du -k -s -- "+shellQuote(dir)+"/* "+shellQuote(dir)+"/.[!.]* 2>/dev/null")
+	}
+	list := parseDu(out, dir)
+	if len(list) == 0 && err != nil {
+		return nil, out, err
+	}
+	// Mark directories so the UI can drill down
+	types, _ := m.ShellAs(serial, false, "for f in "+shellQuote(dir)+"/* "+shellQuote(dir)+"/.[!.]*; do [ -d \"$f\" ] && echo \"$f\"; done 2>/dev/null")
+	dirs := map[string]bool{}
+	for _, ln := range strings.Split(types, "\n") {
+		if ln = strings.TrimRight(ln, "\r"); ln != "" {
+			dirs[path.Clean(ln)] = true
+		}
+	}
+	for i := range list {
+		list[i].IsDir = dirs[list[i].Path]
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Size > list[j].Size })
+	return list, out, nil
+}
+
+var duLineRe = regexp.MustCompile(`^(\d+)\s+(.+)$`)
+
+// parseDu parses "size<TAB>path" lines, dropping the line for dir itself.
+func parseDu(out, dir string) []DirUsage {
+	var res []DirUsage
+	for _, ln := range strings.Split(out, "\n") {
+		mm := duLineRe.FindStringSubmatch(strings.TrimRight(ln, "\r"))
+		if len(mm) != 3 {
+			continue
+		}
+		p := path.Clean(mm[2])
+		if p == dir {
+			continue
+		}
+		kb, _ := strconv.ParseInt(mm[1], 10, 64)
+		res = append(res, DirUsage{Path: p, Size: kb * 1024})
+	}
+	return res
+}
+
+// AppStorageStats returns per-app code/data/cache sizes from "dumpsys diskstats".
+// The numbers are cached by the system and may be a few hours old.
+func (m *Manager) AppStorageStats(serial string) ([]AppStorage, string, error) {
+	out, err := m.ExecSerial(serial, "shell", "dumpsys", "diskstats")
+	if err != nil {
+		return nil, out, err
+	}
+	apps := parseDiskstats(out)
+	if len(apps) == 0 {
+		return nil, out, errors.New("no per-app sizes in dumpsys diskstats")
+	}
+	sort.SliceStable(apps, func(i, j int) bool { return apps[i].Total() > apps[j].Total() })
+	return apps, out, nil
+}
+
+// parseDiskstats reads the parallel arrays "Package Names", "App Sizes",
+// "App Data Sizes" and "Cache Sizes" from dumpsys diskstats.
+func parseDiskstats(out string) []AppStorage {
+	arrays := map[string][]string{}
+	for _, ln := range strings.Split(out, "\n") {
+		kv := strings.SplitN(strings.TrimSpace(ln), ":", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		v := strings.TrimSpace(kv[1])
+		if !strings.HasPrefix(v, "[") || !strings.HasSuffix(v, "]") {
+			continue
+		}
+		v = strings.TrimSuffix(strings.TrimPrefix(v, "["), "]")
+		var items []string
+		for _, it := range strings.Split(v, ",") {
+			items = append(items, strings.Trim(strings.TrimSpace(it), `"`))
+		}
+		arrays[strings.TrimSpace(kv[0])] = items
+	}
+	names := arrays["Package Names"]
+	num := func(key string, i int) int64 {
+		a := arrays[key]
+		if i >= len(a) {
+			return 0
+		}
+		n, _ := strconv.ParseInt(a[i], 10, 64)
+		return n
+	}
+	var res []AppStorage
+	for i, n := range names {
+		if n == "" {
+			continue
+		}
+		res = append(res, AppStorage{
+			Package: n,
+			Code:    num("App Sizes", i),
+			Data:    num("App Data Sizes", i),
+			Cache:   num("Cache Sizes", i),
+		})
+	}
+	return res
+}
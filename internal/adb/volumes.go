@@ -0,0 +1,147 @@
+package adb
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// StorageVolume is a mountable storage volume reported by "sm list-volumes".
+type StorageVolume struct {
+	ID         string // e.g. "emulated;0", "public:179,1", "private:8,2"
+	Kind       string // "internal", "emulated", "sdcard", "usb", "adopted", "public"
+	Label      string
+	MountPoint string
+	State      string // "mounted", "unmounted", "ejecting", ...
+	UUID       string
+	Total      int64 // bytes, 0 if unknown
+	Free       int64 // bytes, 0 if unknown
+}
+
+// Mounted reports whether the volume can be browsed.
+func (v StorageVolume) Mounted() bool {
+	return v.State == "mounted" || v.State == "mounted_read_only"
+}
+
+// StorageVolumes lists internal storage, SD cards (portable and adopted) and USB OTG
+// drives with free/total space from df. Devices without "sm" (pre-Android 6) fall
+// back to the volumes visible under /storage in df.
+func (m *Manager) StorageVolumes(serial string) ([]StorageVolume, string, error) {
+	smOut, smErr := m.ExecSerial(serial, "shell", "sm", "list-volumes", "all")
+	dfVols, dfOut, dfErr := m.DiskFree(serial)
+	raw := smOut + "\n" + dfOut
+
+	var vols []StorageVolume
+	if smErr == nil {
+		vols = parseSmVolumes(smOut)
+	}
+	if len(vols) == 0 {
+		for _, d := range dfVols {
+			if strings.HasPrefix(d.MountPoint, "/storage/") || d.MountPoint == "/data" {
+				vols = append(vols, StorageVolume{
+					ID:         d.MountPoint,
+					Kind:       "public",
+					Label:      path.Base(d.MountPoint),
+					MountPoint: d.MountPoint,
+					State:      "mounted",
+				})
+			}
+		}
+	}
+	if len(vols) == 0 && smErr != nil {
+		if dfErr != nil {
+			return nil, raw, dfErr
+		}
+		return nil, raw, smErr
+	}
+
+	// Attach sizes from df; the emulated view lives on /data (or /storage/emulated via FUSE)
+	byMount := map[string]VolumeUsage{}
+	for _, d := range dfVols {
+		byMount[d.MountPoint] = d
+	}
+	for i := range vols {
+		candidates := []string{vols[i].MountPoint, path.Dir(vols[i].MountPoint)}
+		if vols[i].Kind == "emulated" {
+			candidates = append(candidates, "/data")
+		}
+		for _, c := range candidates {
+			if d, ok := byMount[c]; ok {
+				vols[i].Total = d.Total
+				vols[i].Free = d.Free
+				break
+			}
+		}
+	}
+	sort.SliceStable(vols, func(i, j int) bool { return volumeOrder(vols[i].Kind) < volumeOrder(vols[j].Kind) })
+	return vols, raw, nil
+}
+
+func volumeOrder(kind string) int {
+	switch kind {
+	case "emulated":
+		return 0
+	case "sdcard":
+		return 1
+	case "usb":
+		return 2
+	case "adopted":
+		return 3
+	case "internal":
+		return 5
+	}
+	return 4
+}
+
+// parseSmVolumes parses "sm list-volumes all" lines of the form "<id> <state> <fsUuid>".
+func parseSmVolumes(out string) []StorageVolume {
+	var res []StorageVolume
+	for _, ln := range strings.Split(out, "\n") {
+		f := strings.Fields(ln)
+		if len(f) < 2 {
+			continue
+		}
+		v := StorageVolume{ID: f[0], State: f[1]}
+		if len(f) >= 3 && f[2] != "null" {
+			v.UUID = f[2]
+		}
+		// disk major number: 179 = MMC (SD card), 8/65+ = SCSI (USB OTG)
+		major := ""
+		if i := strings.Index(v.ID, ":"); i >= 0 {
+			major = strings.SplitN(v.ID[i+1:], ",", 2)[0]
+		}
+		switch {
+		case v.ID == "private":
+			v.Kind, v.Label, v.MountPoint = "internal", "Internal (/data)", "/data"
+		case strings.HasPrefix(v.ID, "emulated"):
+			user := "0"
+			if i := strings.Index(v.ID, ";"); i >= 0 {
+				user = v.ID[i+1:]
+			}
+			v.Kind, v.Label, v.MountPoint = "emulated", "Internal shared storage", "/storage/emulated/"+user
+		case strings.HasPrefix(v.ID, "public:"):
+			v.Kind, v.Label = "public", "Portable storage"
+			if major == "179" {
+				v.Kind, v.Label = "sdcard", "SD card"
+			} else if major != "" {
+				v.Kind, v.Label = "usb", "USB drive"
+			}
+			if v.UUID != "" {
+				v.MountPoint = "/storage/" + v.UUID
+				v.Label += " " + v.UUID
+			}
+		case strings.HasPrefix(v.ID, "private:"):
+			v.Kind, v.Label = "adopted", "Adopted storage"
+			if v.UUID != "" {
+				v.MountPoint = "/mnt/expand/" + v.UUID
+			}
+		case strings.HasPrefix(v.ID, "stub:"):
+			// Virtual volumes (e.g. ChromeOS/Android Auto) are not browsable
+			continue
+		default:
+			continue
+		}
+		res = append(res, v)
+	}
+	return res
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 const appName = "adb-gui"
@@ -13,8 +14,106 @@ const appName = "adb-gui"
 type Config struct {
 	ADBPath    string `json:"adb_path"`
 	LastDevice string `json:"last_device,omitempty"`
-	ThemeMode  string `json:"theme_mode,omitempty"` // "system" (default), "light", "dark"
-	Language   string `json:"language,omitempty"`   // "zh" (Chinese), "en" (English), "auto" (auto-detect)
+	ThemeMode  string `json:"theme_mode,omitempty"`  // "system" (default), "light", "dark"
+	Language   string `json:"language,omitempty"`    // "zh" (Chinese), "en" (English), "auto" (auto-detect)
+	SafeDelete bool   `json:"safe_delete,omitempty"` // move deleted files to an on-device trash instead of rm -rf
+	// Bookmarks are remote paths offered in the Storage tab. nil means "never edited" (defaults apply).
+	Bookmarks []string `json:"bookmarks"`
+	MediaDir  string   `json:"media_dir,omitempty"` // where screenshots and recordings are saved
+	// ShellHistory holds commands entered in the Shell tab, oldest first.
+	ShellHistory []string `json:"shell_history,omitempty"`
+	// SettingsPresets are offered in the Settings tab. nil means "never edited" (defaults apply).
+	SettingsPresets []SettingsPreset `json:"settings_presets"`
+}
+
+// SettingsPreset is a named list of settings-provider changes.
+type SettingsPreset struct {
+	Name    string         `json:"name"`
+	Entries []SettingEntry `json:"entries"`
+}
+
+// SettingEntry is one "settings put" (or delete) in a preset.
+type SettingEntry struct {
+	Namespace string `json:"namespace"`
+	Key       string `json:"key"`
+	Value     string `json:"value,omitempty"`
+	Delete    bool   `json:"delete,omitempty"`
+}
+
+// DefaultSettingsPresets are offered until the user edits the preset list.
+var DefaultSettingsPresets = []SettingsPreset{
+	{Name: "UI testing", Entries: []SettingEntry{
+		{Namespace: "global", Key: "window_animation_scale", Value: "0"},
+		{Namespace: "global", Key: "transition_animation_scale", Value: "0"},
+		{Namespace: "global", Key: "animator_duration_scale", Value: "0"},
+		{Namespace: "global", Key: "stay_on_while_plugged_in", Value: "7"},
+		{Namespace: "system", Key: "show_touches", Value: "1"},
+	}},
+	{Name: "Private DNS (dns.google)", Entries: []SettingEntry{
+		{Namespace: "global", Key: "private_dns_mode", Value: "hostname"},
+		{Namespace: "global", Key: "private_dns_specifier", Value: "dns.google"},
+	}},
+}
+
+// maxShellHistory bounds the persisted shell command history.
+const maxShellHistory = 500
+
+// AddShellHistory records a command, skipping immediate repeats.
+func (c *Config) AddShellHistory(cmd string) {
+	if cmd == "" || (len(c.ShellHistory) > 0 && c.ShellHistory[len(c.ShellHistory)-1] == cmd) {
+		return
+	}
+	c.ShellHistory = append(c.ShellHistory, cmd)
+	if len(c.ShellHistory) > maxShellHistory {
+		c.ShellHistory = append([]string{}, c.ShellHistory[len(c.ShellHistory)-maxShellHistory:]...)
+	}
+}
+
+// DefaultBookmarks are offered until the user edits the bookmark list.
+var DefaultBookmarks = []string{
+	"/data/local/tmp",
+	"/sdcard/Android/data",
+	"/sdcard/DCIM",
+	"/sdcard/Download",
+}
+
+// StorageBookmarks returns the user's bookmarks, or the defaults if never edited.
+func (c *Config) StorageBookmarks() []string {
+	if c.Bookmarks == nil {
+		return append([]string{}, DefaultBookmarks...)
+	}
+	return c.Bookmarks
+}
+
+// Presets returns the user's settings presets, or the defaults if never edited.
+func (c *Config) Presets() []SettingsPreset {
+	if c.SettingsPresets == nil {
+		return append([]SettingsPreset{}, DefaultSettingsPresets...)
+	}
+	return c.SettingsPresets
+}
+
+// MediaOutputDir returns the folder for screenshots and recordings,
+// defaulting to ~/Pictures/adb-gui.
+func (c *Config) MediaOutputDir() string {
+	if strings.TrimSpace(c.MediaDir) != "" {
+		return c.MediaDir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), appName)
+	}
+	return filepath.Join(home, "Pictures", appName)
+}
+
+// DataDir returns the folder named name for files the app keeps for itself,
+// such as settings backups and snapshots, next to the config file.
+func (c *Config) DataDir(name string) (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
 }
 
 func configDir() (string, error) {
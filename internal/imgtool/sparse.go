@@ -0,0 +1,458 @@
+// Package imgtool reads and writes Android image formats on the host: sparse
+// images (the simg2img/img2simg format used by fastboot) and AVB vbmeta.
+package imgtool
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Sparse format constants.
+const (
+	SparseMagic      = 0xed26ff3a
+	sparseHeaderSize = 28
+	chunkHeaderSize  = 12
+
+	ChunkRaw      = 0xcac1
+	ChunkFill     = 0xcac2
+	ChunkDontCare = 0xcac3
+	ChunkCRC32    = 0xcac4
+
+	// DefaultBlockSize is the block size used when creating sparse images.
+	DefaultBlockSize = 4096
+
+	// maxRawChunkSize caps the data of a raw chunk made from a raw image, as
+	// img2simg does; the chunk size field is only 32 bits.
+	maxRawChunkSize = 64 << 20
+)
+
+// Chunk is one chunk of a sparse image. Raw chunks refer to data in the
+// source file rather than holding it in memory.
+type Chunk struct {
+	Type   uint16
+	Start  uint32 // first output block
+	Blocks uint32
+	Offset int64  // raw data position in the source file
+	Fill   uint32 // fill value (as stored, little endian)
+}
+
+// Image is the chunk layout of a sparse image, or of a raw image scanned as
+// if it were one.
+type Image struct {
+	Sparse      bool // source file is a sparse image
+	BlockSize   uint32
+	TotalBlocks uint32
+	Chunks      []Chunk
+	Checksum    uint32
+	SourceSize  int64
+	src         *os.File
+}
+
+// ChunkCounts returns the number of chunks of each type.
+func (im *Image) ChunkCounts() map[uint16]int {
+	res := map[uint16]int{}
+	for _, c := range im.Chunks {
+		res[c.Type]++
+	}
+	return res
+}
+
+// RawSize is the size of the expanded image.
+func (im *Image) RawSize() int64 {
+	return int64(im.TotalBlocks) * int64(im.BlockSize)
+}
+
+// Close closes the source file.
+func (im *Image) Close() error {
+	return im.src.Close()
+}
+
+// IsSparse reports whether the file starts with the sparse magic.
+func IsSparse(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	var magic [4]byte
+	if _, err := io.ReadFull(f, magic[:]); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return false, nil
+		}
+		return false, err
+	}
+	return binary.LittleEndian.Uint32(magic[:]) == SparseMagic, nil
+}
+
+// ImageSize returns the size an image occupies once flashed: the expanded
+// size from the header of a sparse image, otherwise the file size.
+func ImageSize(path string) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	var hdr [sparseHeaderSize]byte
+	if _, err := io.ReadFull(f, hdr[:]); err != nil || binary.LittleEndian.Uint32(hdr[:]) != SparseMagic {
+		return st.Size(), nil
+	}
+	le := binary.LittleEndian
+	return int64(le.Uint32(hdr[16:])) * int64(le.Uint32(hdr[12:])), nil
+}
+
+// Open reads a sparse image's chunk table, or scans a raw image into raw,
+// fill and zero chunks using blockSize.
+func Open(path string, blockSize uint32) (*Image, error) {
+	sparse, err := IsSparse(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	st, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	im := &Image{Sparse: sparse, SourceSize: st.Size(), src: f}
+	if sparse {
+		err = im.readSparse()
+	} else {
+		err = im.scanRaw(blockSize)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return im, nil
+}
+
+func (im *Image) readSparse() error {
+	r := bufio.NewReader(io.NewSectionReader(im.src, 0, im.SourceSize))
+	var hdr [sparseHeaderSize]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return fmt.Errorf("reading sparse header: %w", err)
+	}
+	le := binary.LittleEndian
+	if major := le.Uint16(hdr[4:]); major != 1 {
+		return fmt.Errorf("unsupported sparse version %d", major)
+	}
+	fileHdr, chunkHdr := int64(le.Uint16(hdr[8:])), int64(le.Uint16(hdr[10:]))
+	if fileHdr < sparseHeaderSize || chunkHdr < chunkHeaderSize {
+		return errors.New("invalid sparse header sizes")
+	}
+	im.BlockSize = le.Uint32(hdr[12:])
+	im.TotalBlocks = le.Uint32(hdr[16:])
+	total := le.Uint32(hdr[20:])
+	im.Checksum = le.Uint32(hdr[24:])
+	if im.BlockSize == 0 || im.BlockSize%4 != 0 {
+		return fmt.Errorf("invalid block size %d", im.BlockSize)
+	}
+	if _, err := r.Discard(int(fileHdr - sparseHeaderSize)); err != nil {
+		return err
+	}
+	pos := fileHdr
+	var block uint32
+	ch := make([]byte, chunkHdr)
+	for i := uint32(0); i < total; i++ {
+		if _, err := io.ReadFull(r, ch); err != nil {
+			return fmt.Errorf("chunk %d: %w", i, err)
+		}
+		c := Chunk{Type: le.Uint16(ch[0:]), Start: block, Blocks: le.Uint32(ch[4:])}
+		dataSize := int64(le.Uint32(ch[8:])) - chunkHdr
+		pos += chunkHdr
+		switch c.Type {
+		case ChunkRaw:
+			if dataSize != int64(c.Blocks)*int64(im.BlockSize) {
+				return fmt.Errorf("chunk %d: raw size mismatch", i)
+			}
+			c.Offset = pos
+		case ChunkFill:
+			if dataSize < 4 {
+				return fmt.Errorf("chunk %d: short fill chunk", i)
+			}
+			var v [4]byte
+			if _, err := io.ReadFull(r, v[:]); err != nil {
+				return err
+			}
+			c.Fill = le.Uint32(v[:])
+			dataSize -= 4
+			pos += 4
+		case ChunkDontCare, ChunkCRC32:
+		default:
+			return fmt.Errorf("chunk %d: unknown type %#x", i, c.Type)
+		}
+		if _, err := r.Discard(int(dataSize)); err != nil {
+			return fmt.Errorf("chunk %d: %w", i, err)
+		}
+		pos += dataSize
+		if c.Type == ChunkCRC32 {
+			continue
+		}
+		block += c.Blocks
+		im.Chunks = append(im.Chunks, c)
+	}
+	if block != im.TotalBlocks {
+		return fmt.Errorf("chunks cover %d blocks, header says %d", block, im.TotalBlocks)
+	}
+	return nil
+}
+
+// scanRaw describes a raw image as chunks: runs of identical 32-bit words
+// become fill chunks, everything else raw chunks.
+func (im *Image) scanRaw(blockSize uint32) error {
+	if blockSize == 0 {
+		blockSize = DefaultBlockSize
+	}
+	im.BlockSize = blockSize
+	if im.SourceSize%int64(blockSize) != 0 {
+		return fmt.Errorf("image size %d is not a multiple of the block size %d", im.SourceSize, blockSize)
+	}
+	im.TotalBlocks = uint32(im.SourceSize / int64(blockSize))
+	r := bufio.NewReaderSize(io.NewSectionReader(im.src, 0, im.SourceSize), 1<<20)
+	buf := make([]byte, blockSize)
+	maxRaw := max(uint32(maxRawChunkSize/int64(blockSize)), 1)
+	for b := uint32(0); b < im.TotalBlocks; b++ {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return err
+		}
+		c := Chunk{Type: ChunkRaw, Start: b, Blocks: 1, Offset: int64(b) * int64(blockSize)}
+		if fill, ok := fillValue(buf); ok {
+			c = Chunk{Type: ChunkFill, Start: b, Blocks: 1, Fill: fill}
+		}
+		if n := len(im.Chunks); n > 0 {
+			last := &im.Chunks[n-1]
+			if last.Type == c.Type && (c.Type == ChunkRaw && last.Blocks < maxRaw || c.Type == ChunkFill && last.Fill == c.Fill) {
+				last.Blocks++
+				continue
+			}
+		}
+		im.Chunks = append(im.Chunks, c)
+	}
+	return nil
+}
+
+func fillValue(block []byte) (uint32, bool) {
+	if !bytes.Equal(block[4:], block[:len(block)-4]) {
+		return 0, false
+	}
+	return binary.LittleEndian.Uint32(block), true
+}
+
+// WriteRaw expands the image to dst (simg2img). Fill and don't-care chunks
+// of zeros are left as holes.
+func (im *Image) WriteRaw(dst string, progress func(done, total int64)) (err error) {
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(dst)
+		}
+	}()
+	if err := out.Truncate(im.RawSize()); err != nil {
+		return err
+	}
+	bs := int64(im.BlockSize)
+	for _, c := range im.Chunks {
+		w := io.NewOffsetWriter(out, int64(c.Start)*bs)
+		size := int64(c.Blocks) * bs
+		switch {
+		case c.Type == ChunkRaw:
+			if _, err := io.Copy(w, io.NewSectionReader(im.src, c.Offset, size)); err != nil {
+				return err
+			}
+		case c.Type == ChunkFill && c.Fill != 0:
+			if _, err := io.Copy(w, io.LimitReader(fillReader(c.Fill), size)); err != nil {
+				return err
+			}
+		}
+		if progress != nil {
+			progress(int64(c.Start+c.Blocks)*bs, im.RawSize())
+		}
+	}
+	return nil
+}
+
+// fillReader repeats a 32-bit little endian value.
+func fillReader(v uint32) io.Reader {
+	var word [4]byte
+	binary.LittleEndian.PutUint32(word[:], v)
+	return &repeatReader{pattern: bytes.Repeat(word[:], 1024)}
+}
+
+type repeatReader struct {
+	pattern []byte
+}
+
+func (r *repeatReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		n += copy(p[n:], r.pattern)
+	}
+	return n, nil
+}
+
+// WriteSparse writes the whole image as one sparse file (img2simg).
+func (im *Image) WriteSparse(dst string) error {
+	return im.writePiece(dst, im.Chunks)
+}
+
+// chunkSize is the number of bytes a chunk takes in a sparse file.
+func (im *Image) chunkSize(c Chunk) int64 {
+	switch c.Type {
+	case ChunkRaw:
+		return chunkHeaderSize + int64(c.Blocks)*int64(im.BlockSize)
+	case ChunkFill:
+		return chunkHeaderSize + 4
+	}
+	return chunkHeaderSize
+}
+
+// writePiece writes chunks as a sparse file covering the whole image; blocks
+// outside the chunks are marked don't-care.
+func (im *Image) writePiece(dst string, chunks []Chunk) (err error) {
+	var all []Chunk
+	var next uint32
+	for _, c := range chunks {
+		if c.Start > next {
+			all = append(all, Chunk{Type: ChunkDontCare, Start: next, Blocks: c.Start - next})
+		}
+		all = append(all, c)
+		next = c.Start + c.Blocks
+	}
+	if next < im.TotalBlocks {
+		all = append(all, Chunk{Type: ChunkDontCare, Start: next, Blocks: im.TotalBlocks - next})
+	}
+
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(dst)
+		}
+	}()
+	w := bufio.NewWriterSize(f, 1<<20)
+	le := binary.LittleEndian
+	hdr := make([]byte, sparseHeaderSize)
+	le.PutUint32(hdr[0:], SparseMagic)
+	le.PutUint16(hdr[4:], 1)
+	le.PutUint16(hdr[6:], 0)
+	le.PutUint16(hdr[8:], sparseHeaderSize)
+	le.PutUint16(hdr[10:], chunkHeaderSize)
+	le.PutUint32(hdr[12:], im.BlockSize)
+	le.PutUint32(hdr[16:], im.TotalBlocks)
+	le.PutUint32(hdr[20:], uint32(len(all)))
+	if _, err := w.Write(hdr); err != nil {
+		return err
+	}
+	ch := make([]byte, chunkHeaderSize)
+	for _, c := range all {
+		le.PutUint16(ch[0:], c.Type)
+		le.PutUint32(ch[4:], c.Blocks)
+		le.PutUint32(ch[8:], uint32(im.chunkSize(c)))
+		if _, err := w.Write(ch); err != nil {
+			return err
+		}
+		switch c.Type {
+		case ChunkRaw:
+			if _, err := io.Copy(w, io.NewSectionReader(im.src, c.Offset, int64(c.Blocks)*int64(im.BlockSize))); err != nil {
+				return err
+			}
+		case ChunkFill:
+			var v [4]byte
+			le.PutUint32(v[:], c.Fill)
+			if _, err := w.Write(v[:]); err != nil {
+				return err
+			}
+		}
+	}
+	return w.Flush()
+}
+
+// Split writes the image as sparse pieces of at most maxSize bytes each
+// into dir, named <base>.N.simg. Flashed in order, the pieces rebuild the
+// whole image, because each marks the blocks it does not carry as don't-care.
+func (im *Image) Split(dir, base string, maxSize int64) ([]string, error) {
+	// header and the trailing don't-care chunk; the don't-care chunks before
+	// and between carried chunks are counted as they are added
+	budget := maxSize - sparseHeaderSize - chunkHeaderSize
+	bs := int64(im.BlockSize)
+	if budget < 2*chunkHeaderSize+bs {
+		return nil, fmt.Errorf("maximum size %d is too small for block size %d", maxSize, bs)
+	}
+	var pieces [][]Chunk
+	var cur []Chunk
+	var used int64
+	var next uint32 // first block after the last chunk in cur
+	flush := func() {
+		if len(cur) > 0 {
+			pieces = append(pieces, cur)
+		}
+		cur, used, next = nil, 0, 0
+	}
+	for _, c := range im.Chunks {
+		if c.Type == ChunkDontCare {
+			continue
+		}
+		for c.Blocks > 0 {
+			var gap int64
+			if c.Start > next {
+				gap = chunkHeaderSize
+			}
+			if size := gap + im.chunkSize(c); used+size <= budget {
+				cur = append(cur, c)
+				used += size
+				next = c.Start + c.Blocks
+				break
+			}
+			if c.Type == ChunkRaw && used+gap+chunkHeaderSize+bs <= budget {
+				// split a raw chunk at the remaining budget
+				n := uint32((budget - used - gap - chunkHeaderSize) / bs)
+				part := c
+				part.Blocks = n
+				cur = append(cur, part)
+				c.Start += n
+				c.Offset += int64(n) * bs
+				c.Blocks -= n
+			}
+			flush()
+		}
+	}
+	flush()
+	if len(pieces) == 0 {
+		pieces = [][]Chunk{nil}
+	}
+	base = strings.TrimSuffix(base, filepath.Ext(base))
+	var paths []string
+	for i, p := range pieces {
+		dst := filepath.Join(dir, fmt.Sprintf("%s.%d.simg", base, i+1))
+		if err := im.writePiece(dst, p); err != nil {
+			return paths, err
+		}
+		paths = append(paths, dst)
+	}
+	return paths, nil
+}
@@ -0,0 +1,214 @@
+package imgtool
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testBlock = 4096
+
+// testRawImage returns an image of random, zero and repeated-word blocks.
+func testRawImage(blocks int) []byte {
+	rng := rand.New(rand.NewSource(1))
+	data := make([]byte, blocks*testBlock)
+	for b := 0; b < blocks; b++ {
+		block := data[b*testBlock : (b+1)*testBlock]
+		switch b % 5 {
+		case 2:
+			// zero
+		case 3:
+			for i := 0; i < len(block); i += 4 {
+				binary.LittleEndian.PutUint32(block[i:], 0xdeadbeef)
+			}
+		default:
+			rng.Read(block)
+		}
+	}
+	return data
+}
+
+type testChunk struct {
+	typ    uint16
+	blocks uint32
+	data   []byte // raw data, or the 4-byte fill value
+}
+
+// writeTestSparse writes a sparse image by hand, independent of writePiece.
+func writeTestSparse(t *testing.T, path string, chunks []testChunk) {
+	t.Helper()
+	le := binary.LittleEndian
+	var total uint32
+	for _, c := range chunks {
+		total += c.blocks
+	}
+	var buf bytes.Buffer
+	hdr := make([]byte, sparseHeaderSize)
+	le.PutUint32(hdr[0:], SparseMagic)
+	le.PutUint16(hdr[4:], 1)
+	le.PutUint16(hdr[8:], sparseHeaderSize)
+	le.PutUint16(hdr[10:], chunkHeaderSize)
+	le.PutUint32(hdr[12:], testBlock)
+	le.PutUint32(hdr[16:], total)
+	le.PutUint32(hdr[20:], uint32(len(chunks)))
+	buf.Write(hdr)
+	for _, c := range chunks {
+		ch := make([]byte, chunkHeaderSize)
+		le.PutUint16(ch[0:], c.typ)
+		le.PutUint32(ch[4:], c.blocks)
+		le.PutUint32(ch[8:], uint32(chunkHeaderSize+len(c.data)))
+		buf.Write(ch)
+		buf.Write(c.data)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// apply writes the blocks an image carries onto dst, as flashing it would;
+// don't-care blocks keep their previous content.
+func apply(t *testing.T, im *Image, dst []byte) {
+	t.Helper()
+	bs := int64(im.BlockSize)
+	for _, c := range im.Chunks {
+		part := dst[int64(c.Start)*bs : int64(c.Start+c.Blocks)*bs]
+		switch c.Type {
+		case ChunkRaw:
+			if _, err := im.src.ReadAt(part, c.Offset); err != nil {
+				t.Fatal(err)
+			}
+		case ChunkFill:
+			for i := 0; i < len(part); i += 4 {
+				binary.LittleEndian.PutUint32(part[i:], c.Fill)
+			}
+		}
+	}
+}
+
+func TestRawSparseRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	data := testRawImage(64)
+	rawPath := filepath.Join(dir, "raw.img")
+	if err := os.WriteFile(rawPath, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	im, err := Open(rawPath, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer im.Close()
+	if im.Sparse || im.TotalBlocks != 64 {
+		t.Fatalf("raw image: sparse=%v blocks=%d", im.Sparse, im.TotalBlocks)
+	}
+	if n := im.ChunkCounts()[ChunkFill]; n == 0 {
+		t.Error("zero and repeated blocks were not turned into fill chunks")
+	}
+	simg := filepath.Join(dir, "out.simg")
+	if err := im.WriteSparse(simg); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := IsSparse(simg); err != nil || !ok {
+		t.Fatalf("IsSparse(%s) = %v, %v", simg, ok, err)
+	}
+	if size, err := ImageSize(simg); err != nil || size != int64(len(data)) {
+		t.Errorf("ImageSize = %d, %v; want %d", size, err, len(data))
+	}
+
+	sp, err := Open(simg, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sp.Close()
+	if !sp.Sparse || sp.RawSize() != int64(len(data)) {
+		t.Fatalf("sparse image: sparse=%v size=%d", sp.Sparse, sp.RawSize())
+	}
+	back := filepath.Join(dir, "back.img")
+	if err := sp.WriteRaw(back, nil); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(back)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Error("raw -> sparse -> raw changed the image")
+	}
+}
+
+func TestSplit(t *testing.T) {
+	dir := t.TempDir()
+	rng := rand.New(rand.NewSource(2))
+	// Alternate single raw blocks with don't-care gaps, so each piece needs
+	// a don't-care header between every pair of carried chunks.
+	var chunks []testChunk
+	for i := 0; i < 40; i++ {
+		block := make([]byte, testBlock)
+		rng.Read(block)
+		chunks = append(chunks, testChunk{ChunkRaw, 1, block}, testChunk{ChunkDontCare, 1, nil})
+	}
+	chunks = append(chunks,
+		testChunk{ChunkFill, 8, []byte{1, 2, 3, 4}},
+		testChunk{ChunkRaw, 20, make([]byte, 20*testBlock)},
+		testChunk{ChunkDontCare, 3, nil})
+	src := filepath.Join(dir, "src.simg")
+	writeTestSparse(t, src, chunks)
+
+	im, err := Open(src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer im.Close()
+	want := make([]byte, im.RawSize())
+	apply(t, im, want)
+
+	// room for exactly three raw blocks if the gaps between them were free
+	const maxSize = sparseHeaderSize + 2*chunkHeaderSize + 3*(chunkHeaderSize+testBlock)
+	pieces, err := im.Split(dir, "src.simg", maxSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pieces) < 2 {
+		t.Fatalf("got %d pieces", len(pieces))
+	}
+	got := make([]byte, im.RawSize())
+	for _, p := range pieces {
+		st, err := os.Stat(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if st.Size() > maxSize {
+			t.Errorf("%s is %d bytes, over the %d limit", filepath.Base(p), st.Size(), maxSize)
+		}
+		piece, err := Open(p, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if piece.TotalBlocks != im.TotalBlocks {
+			t.Errorf("%s covers %d blocks, want %d", filepath.Base(p), piece.TotalBlocks, im.TotalBlocks)
+		}
+		apply(t, piece, got)
+		piece.Close()
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("flashing the pieces in order does not rebuild the image")
+	}
+
+	if _, err := im.Split(dir, "small", testBlock); err == nil {
+		t.Error("Split accepted a limit smaller than one block")
+	}
+}
+
+func TestOpenRejectsUnalignedRaw(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "odd.img")
+	if err := os.WriteFile(p, make([]byte, testBlock+1), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if im, err := Open(p, 0); err == nil {
+		im.Close()
+		t.Error("Open accepted an image that is not a multiple of the block size")
+	}
+}
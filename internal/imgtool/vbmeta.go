@@ -0,0 +1,264 @@
+package imgtool
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	vbmetaHeaderSize = 256
+	avbFooterSize    = 64
+)
+
+// AVB vbmeta header flags.
+const (
+	FlagHashtreeDisabled     = 1 << 0
+	FlagVerificationDisabled = 1 << 1
+)
+
+// avbAlgorithms are the names of the AVB algorithm types, by number.
+var avbAlgorithms = []string{
+	"NONE", "SHA256_RSA2048", "SHA256_RSA4096", "SHA256_RSA8192",
+	"SHA512_RSA2048", "SHA512_RSA4096", "SHA512_RSA8192",
+}
+
+// Descriptor kinds.
+const (
+	DescProperty      = "property"
+	DescHashtree      = "hashtree"
+	DescHash          = "hash"
+	DescKernelCmdline = "kernel_cmdline"
+	DescChain         = "chain_partition"
+)
+
+// Descriptor is one vbmeta descriptor, reduced to what is worth showing.
+type Descriptor struct {
+	Kind      string
+	Partition string // hash, hashtree and chain descriptors
+	Algorithm string // hash algorithm of hash and hashtree descriptors
+	ImageSize uint64
+	Digest    string // hex root digest or hash
+	Key       string // property key, or kernel command line
+	Value     string // property value
+	Location  uint32 // rollback index location of a chained partition
+	PublicKey []byte // public key of a chained partition
+}
+
+// VBMeta is a parsed AVB vbmeta structure.
+type VBMeta struct {
+	Version          string // required libavb version
+	Algorithm        string
+	RollbackIndex    uint64
+	RollbackLocation uint32
+	Flags            uint32
+	Release          string
+	PublicKeySize    uint64
+	Descriptors      []Descriptor
+	Footer           bool // found through an AVB footer at the end of a partition image
+}
+
+// FlagNames lists the set header flags.
+func (v *VBMeta) FlagNames() []string {
+	var res []string
+	if v.Flags&FlagHashtreeDisabled != 0 {
+		res = append(res, "HASHTREE_DISABLED")
+	}
+	if v.Flags&FlagVerificationDisabled != 0 {
+		res = append(res, "VERIFICATION_DISABLED")
+	}
+	return res
+}
+
+// Chained returns the chain partition descriptors.
+func (v *VBMeta) Chained() []Descriptor {
+	var res []Descriptor
+	for _, d := range v.Descriptors {
+		if d.Kind == DescChain {
+			res = append(res, d)
+		}
+	}
+	return res
+}
+
+// ReadVBMeta parses a vbmeta image, or the vbmeta referenced by the AVB
+// footer of a partition image such as boot.img.
+func ReadVBMeta(path string) (*VBMeta, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	var magic [4]byte
+	if _, err := f.ReadAt(magic[:], 0); err != nil {
+		return nil, err
+	}
+	var off, size int64 = 0, st.Size()
+	footer := false
+	if string(magic[:]) != "AVB0" {
+		if st.Size() < avbFooterSize {
+			return nil, errors.New("no vbmeta header or AVB footer")
+		}
+		var ft [avbFooterSize]byte
+		if _, err := f.ReadAt(ft[:], st.Size()-avbFooterSize); err != nil {
+			return nil, err
+		}
+		if string(ft[:4]) != "AVBf" {
+			return nil, errors.New("no vbmeta header or AVB footer")
+		}
+		be := binary.BigEndian
+		off, size = int64(be.Uint64(ft[20:])), int64(be.Uint64(ft[28:]))
+		footer = true
+	}
+	if off < 0 || size < vbmetaHeaderSize || size > 64<<20 || off > st.Size()-size {
+		return nil, errors.New("invalid vbmeta size")
+	}
+	buf := make([]byte, size)
+	if _, err := f.ReadAt(buf, off); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	v, err := parseVBMeta(buf)
+	if err != nil {
+		return nil, err
+	}
+	v.Footer = footer
+	return v, nil
+}
+
+func parseVBMeta(b []byte) (*VBMeta, error) {
+	if len(b) < vbmetaHeaderSize || string(b[:4]) != "AVB0" {
+		return nil, errors.New("bad vbmeta magic")
+	}
+	be := binary.BigEndian
+	authSize := be.Uint64(b[12:])
+	auxSize := be.Uint64(b[20:])
+	v := &VBMeta{
+		Version:          fmt.Sprintf("%d.%d", be.Uint32(b[4:]), be.Uint32(b[8:])),
+		PublicKeySize:    be.Uint64(b[72:]),
+		RollbackIndex:    be.Uint64(b[112:]),
+		Flags:            be.Uint32(b[120:]),
+		RollbackLocation: be.Uint32(b[124:]),
+		Release:          strings.TrimRight(string(b[128:176]), "\x00"),
+	}
+	if alg := be.Uint32(b[28:]); int(alg) < len(avbAlgorithms) {
+		v.Algorithm = avbAlgorithms[alg]
+	} else {
+		v.Algorithm = fmt.Sprintf("unknown (%d)", alg)
+	}
+	// Check each operand before adding so crafted sizes cannot wrap around.
+	rest := uint64(len(b) - vbmetaHeaderSize)
+	if authSize > rest || auxSize > rest-authSize {
+		return nil, errors.New("vbmeta blocks exceed the image")
+	}
+	auxStart := vbmetaHeaderSize + authSize
+	aux := b[auxStart : auxStart+auxSize]
+	descOff, descSize := be.Uint64(b[96:]), be.Uint64(b[104:])
+	if descOff > uint64(len(aux)) || descSize > uint64(len(aux))-descOff {
+		return nil, errors.New("descriptors exceed the auxiliary block")
+	}
+	descs := aux[descOff : descOff+descSize]
+	for len(descs) >= 16 {
+		tag, n := be.Uint64(descs), be.Uint64(descs[8:])
+		if n > uint64(len(descs)-16) {
+			return nil, errors.New("truncated descriptor")
+		}
+		d, err := parseDescriptor(tag, descs[16:16+n])
+		if err != nil {
+			return nil, err
+		}
+		v.Descriptors = append(v.Descriptors, d)
+		descs = descs[16+n:]
+	}
+	return v, nil
+}
+
+// cut returns the next n bytes of *b, or nil if too short.
+func cut(b *[]byte, n uint64) []byte {
+	if n > uint64(len(*b)) {
+		return nil
+	}
+	res := (*b)[:n]
+	*b = (*b)[n:]
+	return res
+}
+
+func parseDescriptor(tag uint64, b []byte) (Descriptor, error) {
+	be := binary.BigEndian
+	short := errors.New("truncated descriptor")
+	algName := func(a []byte) string { return string(bytes.TrimRight(a, "\x00")) }
+	switch tag {
+	case 0:
+		if len(b) < 16 {
+			return Descriptor{}, short
+		}
+		kl, vl := be.Uint64(b), be.Uint64(b[8:])
+		if kl >= uint64(len(b)) || vl >= uint64(len(b)) {
+			return Descriptor{}, short
+		}
+		rest := b[16:]
+		k := cut(&rest, kl+1)
+		val := cut(&rest, vl+1)
+		if k == nil || val == nil {
+			return Descriptor{}, short
+		}
+		return Descriptor{Kind: DescProperty, Key: string(k[:kl]), Value: string(val[:vl])}, nil
+	case 1:
+		if len(b) < 164 {
+			return Descriptor{}, short
+		}
+		d := Descriptor{Kind: DescHashtree, ImageSize: be.Uint64(b[4:]), Algorithm: algName(b[56:88])}
+		nl, sl, dl := be.Uint32(b[88:]), be.Uint32(b[92:]), be.Uint32(b[96:])
+		rest := b[164:]
+		name, _, digest := cut(&rest, uint64(nl)), cut(&rest, uint64(sl)), cut(&rest, uint64(dl))
+		if name == nil || digest == nil && dl > 0 {
+			return Descriptor{}, short
+		}
+		d.Partition, d.Digest = string(name), fmt.Sprintf("%x", digest)
+		return d, nil
+	case 2:
+		if len(b) < 116 {
+			return Descriptor{}, short
+		}
+		d := Descriptor{Kind: DescHash, ImageSize: be.Uint64(b), Algorithm: algName(b[8:40])}
+		nl, sl, dl := be.Uint32(b[40:]), be.Uint32(b[44:]), be.Uint32(b[48:])
+		rest := b[116:]
+		name, _, digest := cut(&rest, uint64(nl)), cut(&rest, uint64(sl)), cut(&rest, uint64(dl))
+		if name == nil || digest == nil && dl > 0 {
+			return Descriptor{}, short
+		}
+		d.Partition, d.Digest = string(name), fmt.Sprintf("%x", digest)
+		return d, nil
+	case 3:
+		if len(b) < 8 {
+			return Descriptor{}, short
+		}
+		rest := b[8:]
+		cmd := cut(&rest, uint64(be.Uint32(b[4:])))
+		if cmd == nil {
+			return Descriptor{}, short
+		}
+		return Descriptor{Kind: DescKernelCmdline, Key: string(cmd)}, nil
+	case 4:
+		if len(b) < 76 {
+			return Descriptor{}, short
+		}
+		d := Descriptor{Kind: DescChain, Location: be.Uint32(b)}
+		nl, kl := be.Uint32(b[4:]), be.Uint32(b[8:])
+		rest := b[76:]
+		name, key := cut(&rest, uint64(nl)), cut(&rest, uint64(kl))
+		if name == nil || key == nil {
+			return Descriptor{}, short
+		}
+		d.Partition, d.PublicKey = string(name), append([]byte(nil), key...)
+		return d, nil
+	}
+	return Descriptor{Kind: fmt.Sprintf("unknown (%d)", tag)}, nil
+}
@@ -0,0 +1,182 @@
+package imgtool
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// pad8 pads b with zeros to a multiple of 8 bytes, as avbtool does.
+func pad8(b []byte) []byte {
+	return append(b, make([]byte, (8-len(b)%8)%8)...)
+}
+
+// descriptor encodes one vbmeta descriptor: tag, length and body.
+func descriptor(tag uint64, body []byte) []byte {
+	body = pad8(body)
+	b := make([]byte, 16, 16+len(body))
+	binary.BigEndian.PutUint64(b, tag)
+	binary.BigEndian.PutUint64(b[8:], uint64(len(body)))
+	return append(b, body...)
+}
+
+func propertyDescriptor(key, value string) []byte {
+	b := make([]byte, 16)
+	binary.BigEndian.PutUint64(b, uint64(len(key)))
+	binary.BigEndian.PutUint64(b[8:], uint64(len(value)))
+	b = append(b, key...)
+	b = append(b, 0)
+	b = append(b, value...)
+	return descriptor(0, append(b, 0))
+}
+
+func hashDescriptor(partition string, size uint64, digest []byte) []byte {
+	be := binary.BigEndian
+	b := make([]byte, 116)
+	be.PutUint64(b, size)
+	copy(b[8:40], "sha256")
+	be.PutUint32(b[40:], uint32(len(partition)))
+	be.PutUint32(b[44:], 4) // salt
+	be.PutUint32(b[48:], uint32(len(digest)))
+	b = append(b, partition...)
+	b = append(b, 1, 2, 3, 4)
+	return descriptor(2, append(b, digest...))
+}
+
+func chainDescriptor(partition string, location uint32, key []byte) []byte {
+	be := binary.BigEndian
+	b := make([]byte, 76)
+	be.PutUint32(b, location)
+	be.PutUint32(b[4:], uint32(len(partition)))
+	be.PutUint32(b[8:], uint32(len(key)))
+	b = append(b, partition...)
+	return descriptor(4, append(b, key...))
+}
+
+// testVBMeta builds a vbmeta image with a 64-byte authentication block and
+// the given descriptors in the auxiliary block.
+func testVBMeta(descs ...[]byte) []byte {
+	be := binary.BigEndian
+	aux := bytes.Join(descs, nil)
+	hdr := make([]byte, vbmetaHeaderSize)
+	copy(hdr, "AVB0")
+	be.PutUint32(hdr[4:], 1)
+	be.PutUint32(hdr[8:], 2)
+	be.PutUint64(hdr[12:], 64)
+	be.PutUint64(hdr[20:], uint64(len(aux)))
+	be.PutUint32(hdr[28:], 2) // SHA256_RSA4096
+	be.PutUint64(hdr[96:], 0)
+	be.PutUint64(hdr[104:], uint64(len(aux)))
+	be.PutUint64(hdr[112:], 7)
+	be.PutUint32(hdr[120:], FlagHashtreeDisabled|FlagVerificationDisabled)
+	be.PutUint32(hdr[124:], 1)
+	copy(hdr[128:176], "avbtool 1.2.0")
+	return append(append(hdr, make([]byte, 64)...), aux...)
+}
+
+func TestParseVBMeta(t *testing.T) {
+	digest := bytes.Repeat([]byte{0xab}, 32)
+	b := testVBMeta(
+		propertyDescriptor("com.android.build.boot.os_version", "14"),
+		hashDescriptor("boot", 1<<20, digest),
+		chainDescriptor("vbmeta_system", 2, []byte("key")),
+	)
+	v, err := parseVBMeta(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Version != "1.2" || v.Algorithm != "SHA256_RSA4096" || v.RollbackIndex != 7 || v.RollbackLocation != 1 || v.Release != "avbtool 1.2.0" {
+		t.Errorf("header = %+v", v)
+	}
+	if names := v.FlagNames(); len(names) != 2 {
+		t.Errorf("FlagNames() = %v", names)
+	}
+	if len(v.Descriptors) != 3 {
+		t.Fatalf("got %d descriptors", len(v.Descriptors))
+	}
+	if d := v.Descriptors[0]; d.Kind != DescProperty || d.Key != "com.android.build.boot.os_version" || d.Value != "14" {
+		t.Errorf("property descriptor = %+v", d)
+	}
+	if d := v.Descriptors[1]; d.Kind != DescHash || d.Partition != "boot" || d.ImageSize != 1<<20 || d.Algorithm != "sha256" || d.Digest != strings.Repeat("ab", 32) {
+		t.Errorf("hash descriptor = %+v", d)
+	}
+	chained := v.Chained()
+	if len(chained) != 1 || chained[0].Partition != "vbmeta_system" || chained[0].Location != 2 || string(chained[0].PublicKey) != "key" {
+		t.Errorf("Chained() = %+v", chained)
+	}
+}
+
+func TestReadVBMetaFooter(t *testing.T) {
+	be := binary.BigEndian
+	vb := testVBMeta(hashDescriptor("boot", 8192, []byte{1, 2}))
+	// partition image: data, vbmeta, padding, footer in the last 64 bytes
+	img := make([]byte, 8192)
+	off := len(img)
+	img = append(img, vb...)
+	img = append(img, make([]byte, 4096-len(vb)%4096)...)
+	footer := make([]byte, avbFooterSize)
+	copy(footer, "AVBf")
+	be.PutUint64(footer[12:], 8192)
+	be.PutUint64(footer[20:], uint64(off))
+	be.PutUint64(footer[28:], uint64(len(vb)))
+	img = append(img, footer...)
+
+	p := filepath.Join(t.TempDir(), "boot.img")
+	if err := os.WriteFile(p, img, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	v, err := ReadVBMeta(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !v.Footer || len(v.Descriptors) != 1 || v.Descriptors[0].Partition != "boot" {
+		t.Errorf("ReadVBMeta = %+v", v)
+	}
+
+	// a footer pointing past the end of the file
+	be.PutUint64(img[len(img)-avbFooterSize+20:], math.MaxInt64)
+	if err := os.WriteFile(p, img, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadVBMeta(p); err == nil {
+		t.Error("ReadVBMeta accepted a footer offset beyond the file")
+	}
+}
+
+func TestParseVBMetaMalformed(t *testing.T) {
+	be := binary.BigEndian
+	valid := testVBMeta(propertyDescriptor("k", "v"))
+	tests := []struct {
+		name   string
+		mutate func(b []byte) []byte
+	}{
+		{"truncated header", func(b []byte) []byte { return b[:100] }},
+		{"bad magic", func(b []byte) []byte { copy(b, "XXXX"); return b }},
+		{"truncated aux block", func(b []byte) []byte { return b[:len(b)-8] }},
+		{"auth size wraps", func(b []byte) []byte { be.PutUint64(b[12:], math.MaxUint64); return b }},
+		{"aux size wraps", func(b []byte) []byte { be.PutUint64(b[20:], math.MaxUint64-200); return b }},
+		{"descriptor offset wraps", func(b []byte) []byte {
+			be.PutUint64(b[96:], math.MaxUint64)
+			be.PutUint64(b[104:], 2)
+			return b
+		}},
+		{"descriptor length", func(b []byte) []byte {
+			be.PutUint64(b[vbmetaHeaderSize+64+8:], math.MaxUint64)
+			return b
+		}},
+		{"property key length wraps", func(b []byte) []byte {
+			be.PutUint64(b[vbmetaHeaderSize+64+16:], math.MaxUint64)
+			return b
+		}},
+	}
+	for _, tt := range tests {
+		b := tt.mutate(append([]byte(nil), valid...))
+		if _, err := parseVBMeta(b); err == nil {
+			t.Errorf("%s: no error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package ui
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/widget"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Annotation tools offered by the screenshot viewer.
+const (
+	toolArrow = "arrow"
+	toolBox   = "box"
+	toolText  = "text"
+	toolCrop  = "crop"
+)
+
+var (
+	annotColor = color.NRGBA{R: 0xe5, G: 0x39, B: 0x35, A: 0xff}
+	cropColor  = color.NRGBA{R: 0xff, G: 0xc1, B: 0x07, A: 0xff}
+)
+
+// annotation is one vector mark drawn on top of the screenshot, in image pixels.
+type annotation struct {
+	tool     string
+	from, to image.Point
+	text     string
+}
+
+type annotState struct {
+	base   *image.RGBA
+	annots []annotation
+}
+
+// annotator displays an image and lets the user draw arrows/boxes, place text
+// and crop with the mouse. Marks stay as vectors until Render flattens them.
+type annotator struct {
+	widget.BaseWidget
+
+	Tool string
+	// OnTextRequest is called when the text tool is tapped at an image point;
+	// the callback should ask for the text and then call AddText.
+	OnTextRequest func(at image.Point)
+
+	base     *image.RGBA
+	annots   []annotation
+	history  []annotState
+	img      *canvas.Image
+	dragging bool
+	dragFrom image.Point
+	dragTo   image.Point
+}
+
+func newAnnotator(src image.Image) *annotator {
+	a := &annotator{Tool: toolArrow, base: toRGBA(src)}
+	a.img = canvas.NewImageFromImage(a.base)
+	a.img.FillMode = canvas.ImageFillContain
+	a.img.SetMinSize(fyne.NewSize(240, 240))
+	a.ExtendBaseWidget(a)
+	return a
+}
+
+func (a *annotator) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(a.img)
+}
+
+// toImage maps a widget position to image pixel coordinates.
+func (a *annotator) toImage(pos fyne.Position) image.Point {
+	return containedImagePoint(pos, a.Size(), a.base.Bounds())
+}
+
+// containedImagePoint maps a position inside an ImageFillContain canvas.Image of
+// size sz to pixel coordinates of an image with bounds b, clamped to the image.
+func containedImagePoint(pos fyne.Position, sz fyne.Size, b image.Rectangle) image.Point {
+	if b.Dx() == 0 || b.Dy() == 0 || sz.Width == 0 || sz.Height == 0 {
+		return image.Point{}
+	}
+	scale := math.Min(float64(sz.Width)/float64(b.Dx()), float64(sz.Height)/float64(b.Dy()))
+	offX := (float64(sz.Width) - float64(b.Dx())*scale) / 2
+	offY := (float64(sz.Height) - float64(b.Dy())*scale) / 2
+	x := int((float64(pos.X) - offX) / scale)
+	y := int((float64(pos.Y) - offY) / scale)
+	return image.Pt(min(max(x, 0), b.Dx()-1), min(max(y, 0), b.Dy()-1))
+}
+
+func (a *annotator) Dragged(e *fyne.DragEvent) {
+	if !a.dragging {
+		a.dragging = true
+		a.dragFrom = a.toImage(e.Position.Subtract(e.Dragged))
+	}
+	a.dragTo = a.toImage(e.Position)
+	a.refreshImage()
+}
+
+func (a *annotator) DragEnd() {
+	if !a.dragging {
+		return
+	}
+	a.dragging = false
+	from, to := a.dragFrom, a.dragTo
+	if abs(to.X-from.X) < 4 && abs(to.Y-from.Y) < 4 {
+		a.refreshImage()
+		return
+	}
+	switch a.Tool {
+	case toolArrow, toolBox:
+		a.pushHistory()
+		a.annots = append(a.annots, annotation{tool: a.Tool, from: from, to: to})
+	case toolCrop:
+		a.crop(image.Rectangle{Min: from, Max: to}.Canon())
+	}
+	a.refreshImage()
+}
+
+func (a *annotator) Tapped(e *fyne.PointEvent) {
+	if a.Tool == toolText && a.OnTextRequest != nil {
+		a.OnTextRequest(a.toImage(e.Position))
+	}
+}
+
+// AddText places a text label with its top-left corner at the given image point.
+func (a *annotator) AddText(at image.Point, text string) {
+	if text == "" {
+		return
+	}
+	a.pushHistory()
+	a.annots = append(a.annots, annotation{tool: toolText, from: at, text: text})
+	a.refreshImage()
+}
+
+// Undo reverts the last mark or crop.
+func (a *annotator) Undo() {
+	if len(a.history) == 0 {
+		return
+	}
+	st := a.history[len(a.history)-1]
+	a.history = a.history[:len(a.history)-1]
+	a.base, a.annots = st.base, st.annots
+	a.refreshImage()
+}
+
+func (a *annotator) pushHistory() {
+	a.history = append(a.history, annotState{base: a.base, annots: append([]annotation(nil), a.annots...)})
+}
+
+// crop keeps only r of the image and shifts existing marks accordingly.
+func (a *annotator) crop(r image.Rectangle) {
+	r = r.Intersect(a.base.Bounds())
+	if r.Dx() < 8 || r.Dy() < 8 {
+		return
+	}
+	a.pushHistory()
+	cropped := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(cropped, cropped.Bounds(), a.base, r.Min, draw.Src)
+	a.base = cropped
+	shifted := make([]annotation, 0, len(a.annots))
+	for _, an := range a.annots {
+		an.from = an.from.Sub(r.Min)
+		an.to = an.to.Sub(r.Min)
+		shifted = append(shifted, an)
+	}
+	a.annots = shifted
+}
+
+// Render returns the image with all marks flattened onto it.
+func (a *annotator) Render() *image.RGBA {
+	out := toRGBA(a.base)
+	for _, an := range a.annots {
+		drawAnnotation(out, an)
+	}
+	return out
+}
+
+func (a *annotator) refreshImage() {
+	out := a.Render()
+	if a.dragging {
+		switch a.Tool {
+		case toolArrow, toolBox:
+			drawAnnotation(out, annotation{tool: a.Tool, from: a.dragFrom, to: a.dragTo})
+		case toolCrop:
+			drawBox(out, image.Rectangle{Min: a.dragFrom, Max: a.dragTo}.Canon(), max(strokeWidth(out)/2, 1), cropColor)
+		}
+	}
+	a.img.Image = out
+	a.img.Refresh()
+}
+
+func toRGBA(src image.Image) *image.RGBA {
+	b := src.Bounds()
+	out := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(out, out.Bounds(), src, b.Min, draw.Src)
+	return out
+}
+
+// strokeWidth scales line thickness with the screenshot resolution.
+func strokeWidth(img *image.RGBA) int {
+	return max(img.Bounds().Dx()/200, 3)
+}
+
+func drawAnnotation(img *image.RGBA, an annotation) {
+	w := strokeWidth(img)
+	switch an.tool {
+	case toolArrow:
+		drawLine(img, an.from, an.to, w, annotColor)
+		// Arrow head at the end point
+		ang := math.Atan2(float64(an.to.Y-an.from.Y), float64(an.to.X-an.from.X))
+		l := float64(w * 6)
+		for _, d := range []float64{math.Pi - 0.45, math.Pi + 0.45} {
+			p := image.Pt(an.to.X+int(l*math.Cos(ang+d)), an.to.Y+int(l*math.Sin(ang+d)))
+			drawLine(img, an.to, p, w, annotColor)
+		}
+	case toolBox:
+		drawBox(img, image.Rectangle{Min: an.from, Max: an.to}.Canon(), w, annotColor)
+	case toolText:
+		drawText(img, an.from, an.text, annotColor)
+	}
+}
+
+func drawBox(img *image.RGBA, r image.Rectangle, w int, c color.Color) {
+	drawLine(img, r.Min, image.Pt(r.Max.X, r.Min.Y), w, c)
+	drawLine(img, image.Pt(r.Max.X, r.Min.Y), r.Max, w, c)
+	drawLine(img, r.Max, image.Pt(r.Min.X, r.Max.Y), w, c)
+	drawLine(img, image.Pt(r.Min.X, r.Max.Y), r.Min, w, c)
+}
+
+// drawLine stamps filled discs along the segment, giving round caps and joins.
+func drawLine(img *image.RGBA, p0, p1 image.Point, w int, c color.Color) {
+	steps := max(abs(p1.X-p0.X), abs(p1.Y-p0.Y), 1)
+	r := w / 2
+	for i := 0; i <= steps; i++ {
+		x := p0.X + (p1.X-p0.X)*i/steps
+		y := p0.Y + (p1.Y-p0.Y)*i/steps
+		fillDisc(img, x, y, r, c)
+	}
+}
+
+func fillDisc(img *image.RGBA, cx, cy, r int, c color.Color) {
+	for dy := -r; dy <= r; dy++ {
+		for dx := -r; dx <= r; dx++ {
+			if dx*dx+dy*dy <= r*r {
+				img.Set(cx+dx, cy+dy, c)
+			}
+		}
+	}
+}
+
+var annotFont *opentype.Font
+
+// drawText draws bold text with a white outline so it stays readable on any background.
+func drawText(img *image.RGBA, at image.Point, text string, c color.Color) {
+	if annotFont == nil {
+		f, err := opentype.Parse(gobold.TTF)
+		if err != nil {
+			return
+		}
+		annotFont = f
+	}
+	face, err := opentype.NewFace(annotFont, &opentype.FaceOptions{
+		Size: math.Max(float64(img.Bounds().Dx())/22, 16),
+		DPI:  72,
+	})
+	if err != nil {
+		return
+	}
+	defer face.Close()
+	ascent := face.Metrics().Ascent
+	outline := max(strokeWidth(img)/2, 1)
+	d := &font.Drawer{Dst: img, Face: face, Src: image.White}
+	for dy := -outline; dy <= outline; dy++ {
+		for dx := -outline; dx <= outline; dx++ {
+			d.Dot = fixed.P(at.X+dx, at.Y+dy).Add(fixed.Point26_6{Y: ascent})
+			d.DrawString(text)
+		}
+	}
+	d.Src = image.NewUniform(c)
+	d.Dot = fixed.P(at.X, at.Y).Add(fixed.Point26_6{Y: ascent})
+	d.DrawString(text)
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
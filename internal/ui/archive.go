@@ -0,0 +1,132 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"adb-gui/internal/adb"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+)
+
+// showArchiveDownload asks for a local .tar/.tar.gz/.zip file and streams the
+// selected entries of dir into it with progress.
+func showArchiveDownload(w fyne.Window, mgr *adb.Manager, serial, dir string, names []string) {
+	fd := dialog.NewFileSave(func(uc fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if uc == nil {
+			return
+		}
+		localPath := uc.URI().Path()
+		format, ok := adb.ArchiveFormatFor(localPath)
+		if !ok {
+			format = adb.ArchiveTar
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		t := showTransferDialog(w, T("download_archive"))
+		t.OnCancel = cancel
+		go func() {
+			defer cancel()
+			label := path.Join(dir, strings.Join(names, ", "))
+			t.Update(label, 0, 0)
+			total := mgr.RemoteSize(serial, joinRemote(dir, names))
+			err := mgr.DownloadArchive(ctx, serial, dir, names, format, uc, func(n int64) {
+				if total > 0 {
+					// du counts blocks, tar counts headers; never show more than 100%
+					n = min(n, total)
+				}
+				t.Update(label, n, total)
+			})
+			if cerr := uc.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				_ = os.Remove(localPath)
+			}
+			t.Close()
+			fyne.Do(func() {
+				switch {
+				case t.Cancelled():
+					dialog.ShowInformation(T("download_archive"), T("transfer_cancelled"), w)
+				case err != nil:
+					dialog.ShowError(fmt.Errorf("%s: %v", T("download_failed"), err), w)
+				default:
+					dialog.ShowInformation(T("download_archive"), T("download_complete")+"\n"+localPath, w)
+				}
+			})
+		}()
+	}, w)
+	name := "archive"
+	if len(names) == 1 {
+		name = names[0]
+	}
+	fd.SetFileName(strings.TrimSuffix(name, "/") + ".tar.gz")
+	fd.SetFilter(storage.NewExtensionFileFilter([]string{".tar", ".gz", ".tgz", ".zip"}))
+	fd.Show()
+}
+
+// showArchiveUpload asks for a local archive and extracts it into remoteDir on the device.
+func showArchiveUpload(w fyne.Window, mgr *adb.Manager, serial, remoteDir string, onDone func()) {
+	fd := dialog.NewFileOpen(func(rc fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if rc == nil {
+			return
+		}
+		localPath := rc.URI().Path()
+		rc.Close()
+		if _, ok := adb.ArchiveFormatFor(localPath); !ok {
+			dialog.ShowInformation(T("upload_extract"), T("unsupported_archive"), w)
+			return
+		}
+		var total int64
+		if st, err := os.Stat(localPath); err == nil {
+			total = st.Size()
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		t := showTransferDialog(w, T("upload_extract"))
+		t.OnCancel = cancel
+		go func() {
+			defer cancel()
+			label := filepath.Base(localPath) + " → " + remoteDir
+			out, err := mgr.UploadArchive(ctx, serial, localPath, remoteDir, func(n int64) {
+				t.Update(label, n, total)
+			})
+			t.Close()
+			fyne.Do(func() {
+				switch {
+				case t.Cancelled():
+					dialog.ShowInformation(T("upload_extract"), T("transfer_cancelled"), w)
+				case err != nil:
+					dialog.ShowError(fmt.Errorf("%s: %v\n%s", T("upload_failed"), err, out), w)
+				default:
+					dialog.ShowInformation(T("upload_extract"), T("upload_complete"), w)
+				}
+				if onDone != nil {
+					onDone()
+				}
+			})
+		}()
+	}, w)
+	fd.SetFilter(storage.NewExtensionFileFilter([]string{".tar", ".gz", ".tgz", ".zip"}))
+	fd.Show()
+}
+
+func joinRemote(dir string, names []string) []string {
+	res := make([]string, 0, len(names))
+	for _, n := range names {
+		res = append(res, path.Join(dir, n))
+	}
+	return res
+}
@@ -0,0 +1,265 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"adb-gui/internal/adb"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Boot patch workflow steps, in order.
+var bootPatchSteps = []string{"push", "patch", "pull", "bootloader"}
+
+// showBootPatcher guides a boot or init_boot image through Magisk: push it to
+// the device, wait for the patched image, pull it back, reboot to the
+// bootloader and then test boot or flash it.
+func showBootPatcher(mgr *adb.Manager, serial string, images map[string]string) {
+	win := fyne.CurrentApp().NewWindow(T("bootpatch_title") + " - " + serial)
+
+	var (
+		source  string
+		patched string
+		states  = make([]string, len(bootPatchSteps))
+		cancel  context.CancelFunc
+		running bool
+	)
+	resetStates := func() {
+		for i := range states {
+			states[i] = stepPending
+		}
+	}
+	resetStates()
+
+	sourceLabel := widget.NewLabel(T("bootpatch_no_image"))
+	sourceLabel.Truncation = fyne.TextTruncateEllipsis
+	patchedLabel := widget.NewLabel("")
+	patchedLabel.Truncation = fyne.TextTruncateEllipsis
+	partSelect := widget.NewSelect([]string{"boot", "init_boot", "vendor_boot"}, nil)
+	partSelect.SetSelected("boot")
+	slotValues, slotLabels := slotChoices()
+	slotSelect := widget.NewSelect(slotLabels, nil)
+	slotSelect.SetSelectedIndex(0)
+	status := widget.NewLabel("")
+	status.Wrapping = fyne.TextWrapWord
+
+	stepList := newStepList(func() int { return len(bootPatchSteps) }, func(id int) (string, string) {
+		return fmt.Sprintf("%d. %s", id+1, T("bootpatch_step_"+bootPatchSteps[id])), states[id]
+	})
+
+	btnStart := widget.NewButton(T("bootpatch_start"), nil)
+	btnStart.Importance = widget.HighImportance
+	btnCancel := widget.NewButton(T("cancel"), func() {
+		if cancel != nil {
+			cancel()
+		}
+	})
+	btnMagisk := widget.NewButton(T("bootpatch_open_magisk"), func() {
+		go func() {
+			out, err := mgr.OpenMagisk(serial)
+			if err != nil {
+				showCmdResult(T("bootpatch_open_magisk"), out, err, win)
+			}
+		}()
+	})
+	btnTestBoot := widget.NewButton(T("bootpatch_test_boot"), nil)
+	btnFlash := widget.NewButton(T("bootpatch_flash"), nil)
+	btnFlash.Importance = widget.DangerImportance
+
+	// sync enables the buttons that make sense for the current state.
+	sync := func() {
+		stepList.Refresh()
+		for _, b := range []*widget.Button{btnStart, btnTestBoot, btnFlash, btnCancel} {
+			b.Disable()
+		}
+		if running {
+			btnCancel.Enable()
+			return
+		}
+		if source != "" {
+			btnStart.Enable()
+		}
+		if patched != "" {
+			btnFlash.Enable()
+			// fastboot boot needs a complete boot image
+			if partSelect.Selected == "boot" {
+				btnTestBoot.Enable()
+			}
+		}
+	}
+	partSelect.OnChanged = func(string) { sync() }
+
+	setSource := func(p string) {
+		source, patched = p, ""
+		sourceLabel.SetText(p)
+		patchedLabel.SetText("")
+		partSelect.SetSelected(adb.BootPartitionFor(p))
+		resetStates()
+		sync()
+	}
+	btnChoose := widget.NewButton(T("bootpatch_choose"), func() {
+		fd := dialog.NewFileOpen(func(rc fyne.URIReadCloser, err error) {
+			if err != nil || rc == nil {
+				return
+			}
+			p := rc.URI().Path()
+			rc.Close()
+			setSource(p)
+		}, win)
+		fd.SetFilter(storage.NewExtensionFileFilter([]string{".img"}))
+		fd.Show()
+	})
+	var extracted []string
+	for name := range images {
+		if adb.BootPartitionFor(name) == name {
+			extracted = append(extracted, name)
+		}
+	}
+	sort.Strings(extracted)
+	extractedSelect := widget.NewSelect(extracted, func(name string) {
+		if p, ok := images[name]; ok {
+			setSource(p)
+		}
+	})
+	extractedSelect.PlaceHolder = T("bootpatch_extracted")
+
+	btnStart.OnTapped = func() {
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+		running = true
+		patched = ""
+		patchedLabel.SetText("")
+		resetStates()
+		sync()
+		src := source
+		go func() {
+			var (
+				since time.Time
+				img   adb.PatchedImage
+				out   string
+			)
+			run := func(i int, fn func() error) bool {
+				fyne.Do(func() {
+					states[i] = stepRunning
+					stepList.Refresh()
+					status.SetText(T("bootpatch_hint_" + bootPatchSteps[i]))
+				})
+				out = ""
+				err := fn()
+				log := out
+				fyne.Do(func() {
+					if err != nil {
+						states[i] = stepFailed
+						status.SetText(fmt.Sprintf(T("bootpatch_failed"), T("bootpatch_step_"+bootPatchSteps[i]), err) + "\n" + lastLines(log, 5))
+					} else {
+						states[i] = stepDone
+					}
+					stepList.Refresh()
+				})
+				return err == nil
+			}
+			ok := run(0, func() (err error) {
+				if since, err = mgr.DeviceTime(serial); err != nil {
+					return err
+				}
+				out, err = mgr.Push(serial, src, adb.MagiskDir)
+				return err
+			}) && run(1, func() (err error) {
+				_, _ = mgr.OpenMagisk(serial)
+				img, err = mgr.WaitPatchedImage(ctx, serial, adb.MagiskDir, since)
+				return err
+			}) && run(2, func() (err error) {
+				dir := filepath.Dir(src)
+				if out, err = mgr.Pull(serial, img.Path, dir, false); err == nil {
+					fyne.Do(func() {
+						patched = filepath.Join(dir, filepath.Base(img.Path))
+						patchedLabel.SetText(T("bootpatch_patched") + ": " + patched)
+					})
+				}
+				return err
+			}) && run(3, func() (err error) {
+				if out, err = mgr.Reboot(serial, "bootloader"); err != nil {
+					return err
+				}
+				return mgr.WaitFastboot(ctx, serial, 90*time.Second)
+			})
+			fyne.Do(func() {
+				running = false
+				cancel()
+				if ok {
+					status.SetText(T("bootpatch_ready"))
+				}
+				sync()
+			})
+		}()
+	}
+
+	btnTestBoot.OnTapped = func() {
+		p := patched
+		status.SetText(T("loading"))
+		go func() {
+			out, err := mgr.BootImage(serial, p)
+			showCmdResult(T("bootpatch_test_boot"), out, err, win)
+			fyne.Do(func() {
+				if err == nil {
+					status.SetText(T("bootpatch_test_booted"))
+				} else {
+					status.SetText("")
+				}
+			})
+		}()
+	}
+	btnFlash.OnTapped = func() {
+		p, partition := patched, partSelect.Selected
+		slot := slotValues[max(slotSelect.SelectedIndex(), 0)]
+		withFastbootTarget(win, mgr, serial, func(info *adb.GetVarInfo) {
+			if err := info.CheckImageSize(partition, slot, p); err != nil {
+				showFlashSizeError(win, err)
+				return
+			}
+			confirmFlashWarnings(win, info, partition, slot, func() {
+				confirmDestructive(win, T("bootpatch_flash"), fmt.Sprintf(T("guard_flash_msg"), filepath.Base(p), partition),
+					serial, info, partition, func() {
+						go func() {
+							out, err := mgr.FlashPartition(serial, partition, p, adb.FlashOptions{Slot: slot})
+							showCmdResult(T("bootpatch_flash"), out, err, win)
+						}()
+					})
+			})
+		})
+	}
+
+	win.SetCloseIntercept(func() {
+		if cancel != nil {
+			cancel()
+		}
+		win.Close()
+	})
+
+	imageRow := container.NewBorder(nil, nil, widget.NewLabel(T("bootpatch_image")), container.NewHBox(btnChoose), sourceLabel)
+	if len(extracted) > 0 {
+		imageRow = container.NewBorder(nil, nil, widget.NewLabel(T("bootpatch_image")), container.NewHBox(extractedSelect, btnChoose), sourceLabel)
+	}
+	top := container.NewVBox(
+		imageRow,
+		container.NewHBox(widget.NewLabel(T("partition_name")), partSelect, widget.NewLabel(T("getvar_slot")), slotSelect),
+		container.NewHBox(btnStart, btnMagisk, btnCancel),
+	)
+	bottom := container.NewVBox(
+		patchedLabel,
+		status,
+		container.NewHBox(btnTestBoot, btnFlash),
+	)
+	win.SetContent(container.NewBorder(top, bottom, nil, nil, stepList))
+	win.Resize(fyne.NewSize(760, 520))
+	sync()
+	win.Show()
+}
@@ -0,0 +1,282 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"adb-gui/internal/adb"
+	"adb-gui/internal/config"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+)
+
+// maxBugreportHits caps search results across all sections.
+const maxBugreportHits = 2000
+
+// bugreportDir is where captured bugreports are saved.
+func bugreportDir(cfg *config.Config) string {
+	return filepath.Join(cfg.MediaOutputDir(), "bugreports")
+}
+
+// showBugreportCapture runs bugreportz with a progress dialog and opens the result.
+func showBugreportCapture(w fyne.Window, mgr *adb.Manager, cfg *config.Config, serial string) {
+	local := filepath.Join(bugreportDir(cfg), mediaFileName(serial, time.Now(), ".zip"))
+	ctx, cancel := context.WithCancel(context.Background())
+
+	bar := widget.NewProgressBar()
+	label := widget.NewLabel(T("bugreport_running"))
+	var d dialog.Dialog
+	btnCancel := widget.NewButton(T("cancel"), func() {
+		cancel()
+	})
+	d = dialog.NewCustomWithoutButtons(T("bugreport"), container.NewVBox(label, bar, btnCancel), w)
+	d.Resize(fyne.NewSize(460, 160))
+	d.Show()
+
+	go func() {
+		defer cancel()
+		start := time.Now()
+		if err := os.MkdirAll(filepath.Dir(local), 0o755); err != nil {
+			fyne.Do(func() {
+				d.Hide()
+				dialog.ShowError(err, w)
+			})
+			return
+		}
+		out, err := mgr.CaptureBugreport(ctx, serial, local, func(cur, total int) {
+			fyne.Do(func() {
+				if total > 0 {
+					bar.SetValue(float64(cur) / float64(total))
+				}
+				label.SetText(fmt.Sprintf("%s  %s", T("bugreport_running"), time.Since(start).Round(time.Second)))
+			})
+		})
+		fyne.Do(func() {
+			d.Hide()
+			switch {
+			case ctx.Err() != nil:
+				dialog.ShowInformation(T("bugreport"), T("transfer_cancelled"), w)
+			case err != nil:
+				dialog.ShowError(fmt.Errorf("%s: %v\n%s", T("bugreport_failed"), err, strings.TrimSpace(out)), w)
+			default:
+				dialog.ShowConfirm(T("bugreport"), T("saved_to")+"\n"+local+"\n\n"+T("bugreport_open_now"), func(ok bool) {
+					if ok {
+						openBugreportViewer(w, local)
+					}
+				}, w)
+			}
+		})
+	}()
+}
+
+// showBugreportOpen picks a local bugreport zip and opens it in the viewer.
+func showBugreportOpen(w fyne.Window, cfg *config.Config) {
+	fd := dialog.NewFileOpen(func(rc fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if rc == nil {
+			return
+		}
+		p := rc.URI().Path()
+		rc.Close()
+		openBugreportViewer(w, p)
+	}, w)
+	fd.SetFilter(storage.NewExtensionFileFilter([]string{".zip"}))
+	if lister, err := storage.ListerForURI(storage.NewFileURI(bugreportDir(cfg))); err == nil {
+		fd.SetLocation(lister)
+	}
+	fd.Show()
+}
+
+// openBugreportViewer parses the zip in the background and shows it in a window.
+func openBugreportViewer(w fyne.Window, zipPath string) {
+	prog := dialog.NewCustomWithoutButtons(T("bugreport"), container.NewVBox(widget.NewLabel(T("loading")), widget.NewProgressBarInfinite()), w)
+	prog.Show()
+	go func() {
+		br, err := adb.OpenBugreport(zipPath)
+		fyne.Do(func() {
+			prog.Hide()
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			showBugreportWindow(br)
+		})
+	}()
+}
+
+type bugreportHit struct {
+	section int
+	line    int
+	text    string
+}
+
+func showBugreportWindow(br *adb.Bugreport) {
+	win := fyne.CurrentApp().NewWindow(T("bugreport") + " - " + filepath.Base(br.Path))
+
+	categories := []string{T("all"), adb.SectionDumpsys, adb.SectionLogcat, adb.SectionANR, adb.SectionTombstone, adb.SectionOther}
+	catSelect := widget.NewSelect(categories, nil)
+	nameFilter := widget.NewEntry()
+	nameFilter.SetPlaceHolder(T("bugreport_filter_sections"))
+
+	// Sections shown in the left list (indices into br.Sections)
+	var shown []int
+	var curSection = -1
+	var lines []string
+
+	content := widget.NewList(
+		func() int { return len(lines) },
+		func() fyne.CanvasObject {
+			l := widget.NewLabel("")
+			l.TextStyle = fyne.TextStyle{Monospace: true}
+			l.Truncation = fyne.TextTruncateEllipsis
+			return l
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			o.(*widget.Label).SetText(lines[i])
+		},
+	)
+	title := widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	openSection := func(idx, line int) {
+		if idx < 0 || idx >= len(br.Sections) {
+			return
+		}
+		curSection = idx
+		s := br.Sections[idx]
+		lines = s.Lines
+		title.SetText(fmt.Sprintf("%s (%d)", s.Title, len(s.Lines)))
+		content.UnselectAll()
+		content.Refresh()
+		if line > 0 {
+			content.ScrollTo(line)
+			content.Select(line)
+		} else {
+			content.ScrollToTop()
+		}
+	}
+
+	sectionList := widget.NewList(
+		func() int { return len(shown) },
+		func() fyne.CanvasObject {
+			l := widget.NewLabel("")
+			l.Truncation = fyne.TextTruncateEllipsis
+			return l
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			s := br.Sections[shown[i]]
+			o.(*widget.Label).SetText(fmt.Sprintf("[%s] %s", s.Category, s.Title))
+		},
+	)
+	sectionList.OnSelected = func(i widget.ListItemID) {
+		if i < len(shown) && shown[i] != curSection {
+			openSection(shown[i], 0)
+		}
+	}
+	refreshSections := func() {
+		shown = shown[:0]
+		q := strings.ToLower(strings.TrimSpace(nameFilter.Text))
+		for i, s := range br.Sections {
+			if catSelect.Selected != T("all") && s.Category != catSelect.Selected {
+				continue
+			}
+			if q != "" && !strings.Contains(strings.ToLower(s.Title), q) {
+				continue
+			}
+			shown = append(shown, i)
+		}
+		sectionList.UnselectAll()
+		sectionList.Refresh()
+	}
+	catSelect.OnChanged = func(string) { refreshSections() }
+	nameFilter.OnChanged = func(string) { refreshSections() }
+
+	// Full-text search across all sections
+	var hits []bugreportHit
+	hitList := widget.NewList(
+		func() int { return len(hits) },
+		func() fyne.CanvasObject {
+			l := widget.NewLabel("")
+			l.Truncation = fyne.TextTruncateEllipsis
+			return l
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			h := hits[i]
+			o.(*widget.Label).SetText(fmt.Sprintf("%s:%d  %s", br.Sections[h.section].Title, h.line+1, strings.TrimSpace(h.text)))
+		},
+	)
+	hitList.OnSelected = func(i widget.ListItemID) {
+		if i < len(hits) {
+			openSection(hits[i].section, hits[i].line)
+		}
+	}
+	searchStatus := widget.NewLabel("")
+	searchEntry := widget.NewEntry()
+	searchEntry.SetPlaceHolder(T("bugreport_search"))
+	searchGen := 0
+	searchEntry.OnSubmitted = func(q string) {
+		q = strings.ToLower(strings.TrimSpace(q))
+		searchGen++
+		gen := searchGen
+		hits = nil
+		hitList.Refresh()
+		if q == "" {
+			searchStatus.SetText("")
+			return
+		}
+		searchStatus.SetText(T("searching"))
+		go func() {
+			var found []bugreportHit
+			for si, s := range br.Sections {
+				for li, ln := range s.Lines {
+					if strings.Contains(strings.ToLower(ln), q) {
+						found = append(found, bugreportHit{section: si, line: li, text: ln})
+						if len(found) >= maxBugreportHits {
+							break
+						}
+					}
+				}
+				if len(found) >= maxBugreportHits {
+					break
+				}
+			}
+			fyne.Do(func() {
+				if gen != searchGen {
+					return
+				}
+				hits = found
+				hitList.Refresh()
+				text := fmt.Sprintf("%s: %d", T("search_results"), len(found))
+				if len(found) >= maxBugreportHits {
+					text += "  " + fmt.Sprintf(T("search_truncated"), maxBugreportHits)
+				}
+				searchStatus.SetText(text)
+			})
+		}()
+	}
+
+	info := widget.NewLabel(fmt.Sprintf(T("bugreport_sections"), len(br.Sections)))
+	if meta, err := adb.ReadBugreportMeta(br.Path); err == nil {
+		info.SetText(fmt.Sprintf("%s  %s  SDK %s  %s", meta.Serial, meta.Model, meta.SDK, meta.Captured.Format("2006-01-02 15:04")))
+	}
+
+	left := container.NewBorder(container.NewVBox(catSelect, nameFilter), nil, nil, nil, sectionList)
+	searchPane := container.NewBorder(container.NewBorder(nil, nil, nil, searchStatus, searchEntry), nil, nil, nil, hitList)
+	right := container.NewVSplit(container.NewBorder(title, nil, nil, nil, content), searchPane)
+	right.Offset = 0.7
+	split := container.NewHSplit(left, right)
+	split.Offset = 0.3
+	win.SetContent(container.NewBorder(info, nil, nil, nil, split))
+	win.Resize(fyne.NewSize(1100, 760))
+	catSelect.SetSelected(T("all"))
+	win.Show()
+}
@@ -0,0 +1,71 @@
+package ui
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// copyImageToClipboard puts PNG data on the system clipboard. Fyne's clipboard
+// is text-only, so this shells out to the platform tool (osascript, PowerShell,
+// wl-copy or xclip).
+func copyImageToClipboard(png []byte) error {
+	tmp, err := os.CreateTemp("", "adb-gui-clip-*.png")
+	if err != nil {
+		return err
+	}
+	name := tmp.Name()
+	_, werr := tmp.Write(png)
+	if cerr := tmp.Close(); werr == nil {
+		werr = cerr
+	}
+	if werr != nil {
+		os.Remove(name)
+		return werr
+	}
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		defer os.Remove(name)
+		cmd = exec.Command("osascript", "-e",
+			`set the clipboard to (read (POSIX file "`+name+`") as «class PNGf»)`)
+	case "windows":
+		defer os.Remove(name)
+		script := "Add-Type -AssemblyName System.Windows.Forms,System.Drawing; " +
+			"$img = [System.Drawing.Image]::FromFile('" + strings.ReplaceAll(name, "'", "''") + "'); " +
+			"[System.Windows.Forms.Clipboard]::SetImage($img); $img.Dispose()"
+		cmd = exec.Command("powershell", "-NoProfile", "-STA", "-WindowStyle", "Hidden", "-Command", script)
+	default:
+		// wl-copy and xclip keep serving the selection after we return, so they read from stdin
+		defer os.Remove(name)
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if os.Getenv("WAYLAND_DISPLAY") != "" {
+			if _, err := exec.LookPath("wl-copy"); err == nil {
+				cmd = exec.Command("wl-copy", "--type", "image/png")
+			}
+		}
+		if cmd == nil {
+			if _, err := exec.LookPath("xclip"); err != nil {
+				return errors.New("xclip or wl-copy is required to copy images")
+			}
+			cmd = exec.Command("xclip", "-selection", "clipboard", "-t", "image/png", "-i")
+		}
+		cmd.Stdin = f
+		// No output capture: the forked selection owner would hold the pipe open
+		return cmd.Run()
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return errors.New(msg)
+		}
+		return err
+	}
+	return nil
+}
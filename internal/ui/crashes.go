@@ -0,0 +1,279 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"adb-gui/internal/adb"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// crashPollInterval is how often connected devices are scanned while watching.
+const crashPollInterval = 30 * time.Second
+
+// crashGroup is all occurrences sharing a package and signature.
+type crashGroup struct {
+	Signature   string
+	Kind        string
+	Package     string
+	Title       string
+	Occurrences []adb.CrashReport // newest first
+}
+
+func (g *crashGroup) devices() int {
+	set := map[string]bool{}
+	for _, o := range g.Occurrences {
+		set[o.Serial] = true
+	}
+	return len(set)
+}
+
+// buildCrashesTab collects crashes and ANRs from all connected devices, grouped by signature.
+func buildCrashesTab(w fyne.Window, mgr *adb.Manager, devices *[]adb.Device) fyne.CanvasObject {
+	var (
+		groups []*crashGroup
+		seen   = map[string]bool{} // occurrence keys already counted
+		cur    *crashGroup
+		scanMu sync.Mutex
+	)
+
+	headers := []string{T("crash_kind"), T("package"), T("crash_title"), T("crash_count"), T("devices"), T("crash_last_seen")}
+	table := widget.NewTable(
+		func() (int, int) { return len(groups) + 1, len(headers) },
+		func() fyne.CanvasObject {
+			l := widget.NewLabel("")
+			l.Truncation = fyne.TextTruncateEllipsis
+			return l
+		},
+		func(id widget.TableCellID, o fyne.CanvasObject) {
+			l := o.(*widget.Label)
+			l.TextStyle = fyne.TextStyle{Bold: id.Row == 0}
+			if id.Row == 0 {
+				l.SetText(headers[id.Col])
+				return
+			}
+			g := groups[id.Row-1]
+			switch id.Col {
+			case 0:
+				l.SetText(g.Kind)
+			case 1:
+				l.SetText(g.Package)
+			case 2:
+				l.SetText(g.Title)
+			case 3:
+				l.SetText(fmt.Sprint(len(g.Occurrences)))
+			case 4:
+				l.SetText(fmt.Sprint(g.devices()))
+			case 5:
+				l.SetText(g.Occurrences[0].Time.Format("01-02 15:04:05"))
+			}
+		},
+	)
+	for i, wd := range []float32{70, 200, 320, 60, 60, 120} {
+		table.SetColumnWidth(i, wd)
+	}
+
+	// Detail pane: occurrences of the selected group and the trace of the selected occurrence
+	var occ []adb.CrashReport
+	var curOcc *adb.CrashReport
+	trace := widget.NewMultiLineEntry()
+	trace.TextStyle = fyne.TextStyle{Monospace: true}
+	trace.Wrapping = fyne.TextWrapOff
+	occList := widget.NewList(
+		func() int { return len(occ) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			r := occ[i]
+			text := fmt.Sprintf("%s  %s  pid %d", r.Time.Format("01-02 15:04:05"), r.Serial, r.PID)
+			if r.AppVersion != "" {
+				text += "  " + r.AppVersion
+			}
+			o.(*widget.Label).SetText(text)
+		},
+	)
+	occList.OnSelected = func(i widget.ListItemID) {
+		if i < len(occ) {
+			r := occ[i]
+			curOcc = &r
+			trace.SetText(r.Trace)
+		}
+	}
+	showGroup := func(g *crashGroup) {
+		cur = g
+		occ = nil
+		curOcc = nil
+		trace.SetText("")
+		if g != nil {
+			occ = g.Occurrences
+		}
+		occList.UnselectAll()
+		occList.Refresh()
+		if len(occ) > 0 {
+			occList.Select(0)
+		}
+	}
+	table.OnSelected = func(id widget.TableCellID) {
+		if id.Row > 0 && id.Row-1 < len(groups) {
+			showGroup(groups[id.Row-1])
+		}
+	}
+
+	btnExport := widget.NewButton(T("crash_export_bundle"), func() {
+		if curOcc == nil {
+			return
+		}
+		r := *curOcc
+		fd := dialog.NewFileSave(func(uc fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			if uc == nil {
+				return
+			}
+			p := uc.URI().Path()
+			uc.Close()
+			go func() {
+				err := mgr.ExportCrashBundle(r, p)
+				fyne.Do(func() {
+					if err != nil {
+						dialog.ShowError(err, w)
+						return
+					}
+					dialog.ShowInformation(T("crash_export_bundle"), T("saved_to")+"\n"+p, w)
+				})
+			}()
+		}, w)
+		name := r.Package
+		if name == "" {
+			name = r.Kind
+		}
+		fd.SetFileName(mediaFileName(r.Serial, r.Time, "_"+name+"_"+r.Kind+".zip"))
+		fd.Show()
+	})
+
+	status := widget.NewLabel("")
+
+	// merge adds new occurrences; must run on the UI thread
+	merge := func(reports []adb.CrashReport) int {
+		added := 0
+		for _, r := range reports {
+			if seen[r.Key()] {
+				continue
+			}
+			seen[r.Key()] = true
+			added++
+			var g *crashGroup
+			for _, cand := range groups {
+				if cand.Signature == r.Signature {
+					g = cand
+					break
+				}
+			}
+			if g == nil {
+				g = &crashGroup{Signature: r.Signature, Kind: r.Kind, Package: r.Package, Title: r.Title}
+				groups = append(groups, g)
+			}
+			g.Occurrences = append(g.Occurrences, r)
+			sort.SliceStable(g.Occurrences, func(i, j int) bool { return g.Occurrences[i].Time.After(g.Occurrences[j].Time) })
+		}
+		sort.SliceStable(groups, func(i, j int) bool {
+			if len(groups[i].Occurrences) != len(groups[j].Occurrences) {
+				return len(groups[i].Occurrences) > len(groups[j].Occurrences)
+			}
+			return groups[i].Occurrences[0].Time.After(groups[j].Occurrences[0].Time)
+		})
+		table.Refresh()
+		if cur != nil {
+			occ = cur.Occurrences
+			occList.Refresh()
+		}
+		return added
+	}
+
+	scan := func() {
+		var serials []string
+		for _, d := range *devices {
+			if d.State == "device" {
+				serials = append(serials, d.Serial)
+			}
+		}
+		if len(serials) == 0 {
+			status.SetText(T("please_select_device"))
+			return
+		}
+		go func() {
+			// Skip overlapping scans when a device is slow
+			if !scanMu.TryLock() {
+				return
+			}
+			defer scanMu.Unlock()
+			fyne.Do(func() { status.SetText(T("crash_scanning")) })
+			var all []adb.CrashReport
+			var errs []string
+			for _, s := range serials {
+				reports, err := mgr.CollectCrashes(s)
+				if err != nil {
+					errs = append(errs, fmt.Sprintf("%s: %v", s, err))
+				}
+				all = append(all, reports...)
+			}
+			fyne.Do(func() {
+				added := merge(all)
+				text := fmt.Sprintf(T("crash_scan_done"), len(serials), added, time.Now().Format("15:04:05"))
+				if len(errs) > 0 {
+					text += "  " + strings.Join(errs, "; ")
+				}
+				status.SetText(text)
+			})
+		}()
+	}
+
+	btnScan := widget.NewButton(T("crash_scan_now"), scan)
+	var watchStop chan struct{}
+	watchCheck := widget.NewCheck(T("crash_watch"), func(on bool) {
+		if watchStop != nil {
+			close(watchStop)
+			watchStop = nil
+		}
+		if !on {
+			return
+		}
+		stop := make(chan struct{})
+		watchStop = stop
+		scan()
+		go func() {
+			t := time.NewTicker(crashPollInterval)
+			defer t.Stop()
+			for {
+				select {
+				case <-stop:
+					return
+				case <-t.C:
+					fyne.Do(scan)
+				}
+			}
+		}()
+	})
+	btnReset := widget.NewButton(T("clear"), func() {
+		groups = nil
+		seen = map[string]bool{}
+		table.UnselectAll()
+		showGroup(nil)
+		table.Refresh()
+	})
+
+	detail := container.NewBorder(nil, btnExport, nil, nil,
+		container.NewVSplit(occList, trace))
+	split := container.NewHSplit(table, detail)
+	split.Offset = 0.55
+	controls := container.NewHBox(btnScan, watchCheck, btnReset)
+	return container.NewBorder(controls, status, nil, nil, split)
+}
@@ -0,0 +1,321 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"adb-gui/internal/adb"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Plan step states.
+const (
+	stepPending = "pending"
+	stepRunning = "running"
+	stepDone    = "done"
+	stepFailed  = "failed"
+	stepSkipped = "skipped"
+)
+
+// newStepList shows numbered steps with a colored state column. item returns
+// the text and state of one step.
+func newStepList(length func() int, item func(id int) (text, state string)) *widget.List {
+	return widget.NewList(
+		length,
+		func() fyne.CanvasObject {
+			state := widget.NewLabel("")
+			text := widget.NewLabel("")
+			text.Truncation = fyne.TextTruncateEllipsis
+			return container.NewBorder(nil, nil, state, nil, text)
+		},
+		func(id widget.ListItemID, o fyne.CanvasObject) {
+			c := o.(*fyne.Container)
+			textLabel, stateLabel := c.Objects[0].(*widget.Label), c.Objects[1].(*widget.Label)
+			text, state := item(id)
+			textLabel.SetText(text)
+			stateLabel.Importance = widget.MediumImportance
+			switch state {
+			case stepDone:
+				stateLabel.Importance = widget.SuccessImportance
+			case stepFailed:
+				stateLabel.Importance = widget.DangerImportance
+			case stepRunning:
+				stateLabel.Importance = widget.WarningImportance
+			}
+			stateLabel.SetText(fmt.Sprintf("%-8s", T("planner_state_"+state)))
+		},
+	)
+}
+
+// showFlashPlanner opens a window that previews and runs a factory image or
+// update zip flash on one fastboot device.
+func showFlashPlanner(mgr *adb.Manager, serial string) {
+	win := fyne.CurrentApp().NewWindow(T("planner_title") + " - " + serial)
+
+	var (
+		img     *adb.FactoryImage
+		dev     *adb.GetVarInfo
+		steps   []adb.FlashStep
+		states  []string
+		running bool
+		// notFastboot is set when the serial was not listed by fastboot devices
+		notFastboot bool
+	)
+
+	source := widget.NewLabel(T("planner_no_source"))
+	source.Truncation = fyne.TextTruncateEllipsis
+	checks := widget.NewLabel("")
+	checks.TextStyle = fyne.TextStyle{Monospace: true}
+	wipe := widget.NewCheck(T("planner_wipe"), nil)
+	skipCurrent := widget.NewCheck(T("planner_skip_current"), nil)
+	logEntry := widget.NewMultiLineEntry()
+	logEntry.TextStyle = fyne.TextStyle{Monospace: true}
+	logEntry.Wrapping = fyne.TextWrapOff
+	btnRun := widget.NewButton(T("planner_run"), nil)
+	btnRun.Importance = widget.DangerImportance
+
+	stepList := newStepList(func() int { return len(steps) }, func(id int) (string, string) {
+		s := steps[id]
+		return fmt.Sprintf("%d. %s  —  %s", id+1, fmt.Sprintf(T("planner_step_"+s.Kind), s.Target), s.Command()), states[id]
+	})
+
+	// update rebuilds the checks and the plan; it is a no-op while running.
+	update := func() {
+		if running {
+			return
+		}
+		steps, states = nil, nil
+		if img == nil {
+			checks.SetText("")
+			if notFastboot {
+				checks.SetText(fmt.Sprintf(T("guard_not_fastboot"), serial))
+			}
+			btnRun.Disable()
+			stepList.Refresh()
+			return
+		}
+		var sb strings.Builder
+		if notFastboot {
+			sb.WriteString(fmt.Sprintf(T("guard_not_fastboot"), serial) + "\n")
+		} else if dev == nil {
+			sb.WriteString(T("planner_no_getvar") + "\n")
+		} else {
+			for _, c := range img.Check(dev) {
+				mark := "✓"
+				if !c.OK {
+					mark = "✗"
+				}
+				fmt.Fprintf(&sb, "%s %-20s %s: %s  %s: %s\n", mark, c.Name,
+					T("planner_required"), strings.Join(c.Want, " | "), T("planner_device"), orDash(c.Have))
+			}
+			if !img.ProductMatches(dev) {
+				sb.WriteString(T("planner_product_mismatch") + "\n")
+			}
+		}
+		checks.SetText(strings.TrimRight(sb.String(), "\n"))
+		steps = img.Plan(dev, adb.PlanOptions{Wipe: wipe.Checked, SkipCurrent: skipCurrent.Checked})
+		states = make([]string, len(steps))
+		for i := range states {
+			states[i] = stepPending
+		}
+		if dev != nil && img.ProductMatches(dev) {
+			btnRun.Enable()
+		} else {
+			btnRun.Disable()
+		}
+		stepList.Refresh()
+	}
+	wipe.OnChanged = func(bool) { update() }
+	skipCurrent.OnChanged = func(bool) { update() }
+
+	readDevice := func() {
+		if running {
+			return
+		}
+		checks.SetText(T("loading"))
+		go func() {
+			g, _, err := mgr.GetVarAll(serial)
+			var nf *adb.NotInFastbootError
+			fyne.Do(func() {
+				dev = g
+				if err != nil {
+					dev = nil
+				}
+				notFastboot = errors.As(err, &nf)
+				update()
+			})
+		}()
+	}
+
+	open := func(p string) {
+		if running {
+			return
+		}
+		if img != nil {
+			img.Close()
+			img = nil
+		}
+		source.SetText(T("loading"))
+		update()
+		go func() {
+			f, err := adb.OpenFactoryImage(p, func(name string) {
+				fyne.Do(func() { source.SetText(fmt.Sprintf(T("planner_extracting"), name)) })
+			})
+			fyne.Do(func() {
+				if err != nil {
+					source.SetText(T("planner_no_source"))
+					dialog.ShowError(err, win)
+					return
+				}
+				img = f
+				source.SetText(p)
+				update()
+			})
+		}()
+	}
+
+	btnZip := widget.NewButton(T("planner_open_zip"), func() {
+		fd := dialog.NewFileOpen(func(rc fyne.URIReadCloser, err error) {
+			if err != nil || rc == nil {
+				return
+			}
+			p := rc.URI().Path()
+			rc.Close()
+			open(p)
+		}, win)
+		fd.SetFilter(storage.NewExtensionFileFilter([]string{".zip"}))
+		fd.Show()
+	})
+	btnFolder := widget.NewButton(T("planner_open_folder"), func() {
+		dialog.ShowFolderOpen(func(lu fyne.ListableURI, err error) {
+			if err != nil || lu == nil {
+				return
+			}
+			open(lu.Path())
+		}, win)
+	})
+	btnDevice := widget.NewButton(T("refresh"), readDevice)
+
+	var run func()
+	btnRun.OnTapped = func() {
+		msg := fmt.Sprintf(T("planner_confirm"), len(steps), serial)
+		// -w erases userdata, so it needs the same typed word as unlocking
+		word := ""
+		if wipe.Checked {
+			msg += "\n\n" + T("planner_confirm_wipe")
+			word = "WIPE"
+		}
+		withFastbootTarget(win, mgr, serial, func(info *adb.GetVarInfo) {
+			if !img.ProductMatches(info) {
+				dialog.ShowInformation(T("planner_run"), T("planner_product_mismatch"), win)
+				return
+			}
+			confirmDestructive(win, T("planner_run"), msg, serial, info, word, run)
+		})
+	}
+	run = func() {
+		running = true
+		btnRun.Disable()
+		btnZip.Disable()
+		btnFolder.Disable()
+		logEntry.SetText("")
+		plan := steps
+		for i := range states {
+			states[i] = stepPending
+		}
+		stepList.Refresh()
+		go func() {
+			failed := -1
+			var failOut string
+			var failErr error
+			for i, s := range plan {
+				i, s := i, s
+				fyne.Do(func() {
+					states[i] = stepRunning
+					stepList.Refresh()
+					logEntry.Append(fmt.Sprintf("$ %s\n", s.Command()))
+				})
+				out, err := mgr.RunFlashStep(serial, s)
+				fyne.Do(func() {
+					logEntry.Append(out)
+					if err != nil {
+						logEntry.Append(err.Error() + "\n")
+						states[i] = stepFailed
+					} else {
+						states[i] = stepDone
+					}
+					stepList.Refresh()
+				})
+				if err != nil {
+					failed, failOut, failErr = i, out, err
+					break
+				}
+			}
+			fyne.Do(func() {
+				running = false
+				btnZip.Enable()
+				btnFolder.Enable()
+				btnRun.Enable()
+				if failed < 0 {
+					dialog.ShowInformation(T("planner_title"), T("planner_done"), win)
+					return
+				}
+				for i := failed + 1; i < len(states); i++ {
+					states[i] = stepSkipped
+				}
+				stepList.Refresh()
+				s := plan[failed]
+				report := fmt.Sprintf(T("planner_failed"), failed+1, len(plan), s.Command(), failErr)
+				if tail := lastLines(failOut, 10); tail != "" {
+					report += "\n\n" + tail
+				}
+				report += "\n\n" + fmt.Sprintf(T("planner_not_run"), len(plan)-failed-1)
+				l := widget.NewLabel(report)
+				l.Wrapping = fyne.TextWrapWord
+				d := dialog.NewCustom(T("planner_failed_title"), T("ok"), container.NewVScroll(l), win)
+				d.Resize(fyne.NewSize(640, 420))
+				d.Show()
+			})
+		}()
+	}
+	btnRun.Disable()
+
+	win.SetCloseIntercept(func() {
+		if running {
+			dialog.ShowInformation(T("planner_title"), T("planner_busy"), win)
+			return
+		}
+		if img != nil {
+			img.Close()
+		}
+		win.Close()
+	})
+
+	top := container.NewVBox(
+		container.NewBorder(nil, nil, container.NewHBox(btnZip, btnFolder), nil, source),
+		container.NewBorder(nil, nil, widget.NewLabelWithStyle(T("planner_checks"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}), btnDevice),
+		checks,
+		container.NewHBox(wipe, skipCurrent, btnRun),
+	)
+	split := container.NewVSplit(stepList, logEntry)
+	split.Offset = 0.4
+	win.SetContent(container.NewBorder(top, nil, nil, nil, split))
+	win.Resize(fyne.NewSize(1000, 700))
+	win.Show()
+	readDevice()
+}
+
+// lastLines returns the last n non-empty lines of s.
+func lastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,372 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"adb-gui/internal/adb"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// slotPanel shows the fastboot mode and A/B slot state of the selected device.
+type slotPanel struct {
+	w       fyne.Window
+	mgr     *adb.Manager
+	bind    binding.String
+	info    *adb.GetVarInfo
+	gen     int
+	status  *widget.Label
+	slots   *widget.Label
+	btnA    *widget.Button
+	btnB    *widget.Button
+	btnMode *widget.Button
+	content fyne.CanvasObject
+	images  map[string]string // partition -> image extracted from a payload
+}
+
+func newSlotPanel(w fyne.Window, mgr *adb.Manager, selectedSerialBind binding.String) *slotPanel {
+	p := &slotPanel{w: w, mgr: mgr, bind: selectedSerialBind, images: map[string]string{}}
+	p.status = widget.NewLabel("")
+	p.status.Wrapping = fyne.TextWrapWord
+	p.slots = widget.NewLabel("")
+	p.slots.TextStyle = fyne.TextStyle{Monospace: true}
+	p.btnA = widget.NewButton(fmt.Sprintf(T("slot_set_active"), adb.SlotA), func() { p.setActive(adb.SlotA) })
+	p.btnB = widget.NewButton(fmt.Sprintf(T("slot_set_active"), adb.SlotB), func() { p.setActive(adb.SlotB) })
+	p.btnMode = widget.NewButton(T("reboot_fastbootd"), p.switchMode)
+	btnRefresh := widget.NewButton(T("refresh"), p.refresh)
+
+	selectedSerialBind.AddListener(binding.NewDataListener(p.refresh))
+
+	p.content = container.NewVBox(
+		widget.NewLabelWithStyle(T("slot_management"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		p.status,
+		p.slots,
+		container.NewHBox(btnRefresh, p.btnA, p.btnB, p.btnMode),
+	)
+	return p
+}
+
+// show updates the panel from getvar output; nil clears it.
+func (p *slotPanel) show(g *adb.GetVarInfo) {
+	p.info = g
+	p.btnA.Disable()
+	p.btnB.Disable()
+	p.slots.SetText("")
+	if g == nil {
+		return
+	}
+	text := fmt.Sprintf(T("slot_mode"), T("slot_mode_"+g.Mode()))
+	if g.Mode() == adb.ModeFastbootd {
+		p.btnMode.SetText(T("reboot_bootloader"))
+	} else {
+		p.btnMode.SetText(T("reboot_fastbootd"))
+	}
+	if g.CurrentSlot == "" {
+		p.status.SetText(text + "  " + T("slot_not_ab"))
+		return
+	}
+	p.status.SetText(text + "  " + fmt.Sprintf(T("getvar_current_slot"), g.CurrentSlot))
+	var sb strings.Builder
+	for _, s := range g.Slots {
+		mark := " "
+		if s.Name == g.CurrentSlot {
+			mark = "*"
+		}
+		fmt.Fprintf(&sb, "%s %s  %s=%s  %s=%s  %s=%s\n", mark, s.Name,
+			T("getvar_successful"), orDash(s.Successful),
+			T("getvar_unbootable"), orDash(s.Unbootable),
+			T("getvar_retry_count"), orDash(s.RetryCount))
+	}
+	p.slots.SetText(strings.TrimRight(sb.String(), "\n"))
+	for _, name := range g.SlotNames() {
+		switch {
+		case name == adb.SlotA && name != g.CurrentSlot:
+			p.btnA.Enable()
+		case name == adb.SlotB && name != g.CurrentSlot:
+			p.btnB.Enable()
+		}
+	}
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func (p *slotPanel) refresh() {
+	serial := mustGet(p.bind)
+	p.gen++
+	myGen := p.gen
+	if serial == "" {
+		p.show(nil)
+		p.status.SetText(T("select_device"))
+		return
+	}
+	p.status.SetText(T("loading"))
+	go func() {
+		g, out, err := p.mgr.GetVarAll(serial)
+		var nf *adb.NotInFastbootError
+		fyne.Do(func() {
+			if myGen != p.gen {
+				return
+			}
+			p.show(g)
+			switch {
+			case errors.As(err, &nf):
+				p.status.SetText(T("slot_not_fastboot"))
+			case err != nil:
+				p.status.SetText(T("error") + ": " + firstLine(strings.TrimSpace(out+"\n"+err.Error())))
+			}
+		})
+	}()
+}
+
+func (p *slotPanel) setActive(slot string) {
+	serial := mustGet(p.bind)
+	withFastbootTarget(p.w, p.mgr, serial, func(info *adb.GetVarInfo) {
+		p.show(info)
+		msg := fmt.Sprintf(T("slot_set_active_confirm"), slot)
+		if st := info.Slot(slot); st != nil && st.Unbootable == "yes" {
+			msg += "\n\n" + fmt.Sprintf(T("slot_warn_unbootable"), slot)
+		}
+		dialog.ShowConfirm(fmt.Sprintf(T("slot_set_active"), slot), msg, func(ok bool) {
+			if !ok {
+				return
+			}
+			go func() {
+				out, err := p.mgr.SetActiveSlot(serial, slot)
+				showCmdResult(fmt.Sprintf(T("slot_set_active"), slot), out, err, p.w)
+				fyne.Do(p.refresh)
+			}()
+		}, p.w)
+	})
+}
+
+// switchMode reboots between the bootloader and fastbootd.
+func (p *slotPanel) switchMode() {
+	serial := mustGet(p.bind)
+	withFastbootTarget(p.w, p.mgr, serial, func(info *adb.GetVarInfo) {
+		p.show(info)
+		toBootloader := info.Mode() == adb.ModeFastbootd
+		go func() {
+			var out string
+			var err error
+			if toBootloader {
+				out, err = p.mgr.ExecFastboot(serial, "reboot-bootloader")
+			} else {
+				out, err = p.mgr.RebootFastbootd(serial)
+			}
+			showCmdResult(T("fastboot_reboot"), out, err, p.w)
+		}()
+	})
+}
+
+// slotChoices returns the flash slot targets and their labels.
+func slotChoices() (values, labels []string) {
+	return []string{adb.SlotDefault, adb.SlotA, adb.SlotB, adb.SlotAll},
+		[]string{T("slot_default"), "_a", "_b", T("slot_all")}
+}
+
+// showFlashPartition asks for a partition, target slot and image, warns about
+// risky slot choices and then flashes.
+func (p *slotPanel) showFlashPartition(fileLabel *widget.Label) {
+	serial := mustGet(p.bind)
+	withFastbootTarget(p.w, p.mgr, serial, func(info *adb.GetVarInfo) {
+		p.show(info)
+		p.showFlashForm(serial, info, fileLabel)
+	})
+}
+
+func (p *slotPanel) showFlashForm(serial string, info *adb.GetVarInfo, fileLabel *widget.Label) {
+	slotValues, slotLabels := slotChoices()
+	partitionEntry := widget.NewEntry()
+	partitionEntry.PlaceHolder = T("partition_placeholder")
+	slotSelect := widget.NewSelect(slotLabels, nil)
+	slotSelect.SetSelectedIndex(0)
+	imageNames := make([]string, 0, len(p.images))
+	for name := range p.images {
+		imageNames = append(imageNames, name)
+	}
+	sort.Strings(imageNames)
+	imageSelect := widget.NewSelect(append([]string{T("flash_choose_file")}, imageNames...), func(s string) {
+		if _, ok := p.images[s]; ok {
+			partitionEntry.SetText(s)
+		}
+	})
+	imageSelect.SetSelectedIndex(0)
+	disableVerity := widget.NewCheck("--disable-verity", nil)
+	disableVerification := widget.NewCheck("--disable-verification", nil)
+	verityChecks := []*widget.Check{disableVerity, disableVerification}
+	partitionEntry.OnChanged = func(s string) {
+		// the flags only apply to vbmeta partitions
+		for _, c := range verityChecks {
+			if strings.HasPrefix(strings.TrimSpace(s), "vbmeta") {
+				c.Enable()
+			} else {
+				c.SetChecked(false)
+				c.Disable()
+			}
+		}
+	}
+	partitionEntry.OnChanged("")
+	items := []*widget.FormItem{
+		widget.NewFormItem(T("partition_name"), partitionEntry),
+		widget.NewFormItem(T("getvar_slot"), slotSelect),
+		widget.NewFormItem(T("flash_vbmeta_flags"), container.NewHBox(disableVerity, disableVerification)),
+	}
+	if len(imageNames) > 0 {
+		items = append(items, widget.NewFormItem(T("flash_image"), imageSelect))
+	}
+	dialog.ShowForm(T("flash_partition"), T("flash"), T("cancel"), items, func(ok bool) {
+		partition := strings.TrimSpace(partitionEntry.Text)
+		if !ok || partition == "" {
+			return
+		}
+		slot := slotValues[max(slotSelect.SelectedIndex(), 0)]
+		opt := adb.FlashOptions{Slot: slot, DisableVerity: disableVerity.Checked, DisableVerification: disableVerification.Checked}
+		maxDownload := info.MaxDownloadSize()
+		withImage := func(path string) {
+			flash := func() {
+				target := partition
+				if slot != adb.SlotDefault {
+					target += " (" + slotLabels[indexOf(slotValues, slot)] + ")"
+				}
+				fileLabel.SetText(fmt.Sprintf("%s: %s", target, path))
+				go func() {
+					out, err := p.mgr.FlashImage(serial, partition, path, opt, maxDownload, func(i, n int) {
+						fyne.Do(func() {
+							fileLabel.SetText(fmt.Sprintf("%s: %s (%s)", target, path, fmt.Sprintf(T("flash_piece"), i, n)))
+						})
+					})
+					showCmdResult(T("fastboot_flash"), out, err, p.w)
+					fyne.Do(p.refresh)
+				}()
+			}
+			if err := info.CheckImageSize(partition, slot, path); err != nil {
+				showFlashSizeError(p.w, err)
+				return
+			}
+			confirmFlashWarnings(p.w, info, partition, slot, func() {
+				confirmDestructive(p.w, T("fastboot_flash"), fmt.Sprintf(T("guard_flash_msg"), filepath.Base(path), partition),
+					serial, info, partition, flash)
+			})
+		}
+		if path, ok := p.images[imageSelect.Selected]; ok {
+			withImage(path)
+			return
+		}
+		dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil || reader == nil {
+				return
+			}
+			path := reader.URI().Path()
+			reader.Close()
+			withImage(path)
+		}, p.w)
+	}, p.w)
+}
+
+// withFastbootTarget makes sure serial is a device in fastboot mode, not an
+// adb device that happens to be selected, and passes its getvar output to fn.
+func withFastbootTarget(w fyne.Window, mgr *adb.Manager, serial string, fn func(info *adb.GetVarInfo)) {
+	if serial == "" {
+		dialog.ShowInformation(T("no_device"), T("please_select_device"), w)
+		return
+	}
+	go func() {
+		info, out, err := mgr.GetVarAll(serial)
+		var nf *adb.NotInFastbootError
+		fyne.Do(func() {
+			switch {
+			case errors.As(err, &nf):
+				dialog.ShowInformation(T("guard_title"), fmt.Sprintf(T("guard_not_fastboot"), serial), w)
+			case err != nil:
+				dialog.ShowError(fmt.Errorf("%v\n%s", err, lastLines(out, 5)), w)
+			default:
+				fn(info)
+			}
+		})
+	}()
+}
+
+// confirmDestructive shows the target device and runs fn once the user
+// confirmed. When word is set it must be typed exactly to enable the button.
+func confirmDestructive(w fyne.Window, title, message, serial string, info *adb.GetVarInfo, word string, fn func()) {
+	msg := widget.NewLabel(message)
+	msg.Wrapping = fyne.TextWrapWord
+	device := widget.NewLabelWithStyle(fmt.Sprintf(T("guard_device"),
+		orDash(info.Vars["product"]), orDash(info.Vars["serialno"]), serial, orDash(info.Unlocked)),
+		fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+	content := container.NewVBox(msg, device)
+
+	var d dialog.Dialog
+	btnOK := widget.NewButton(title, func() {
+		d.Hide()
+		fn()
+	})
+	btnOK.Importance = widget.DangerImportance
+	if word != "" {
+		entry := widget.NewEntry()
+		entry.SetPlaceHolder(word)
+		entry.OnChanged = func(s string) {
+			if s == word {
+				btnOK.Enable()
+			} else {
+				btnOK.Disable()
+			}
+		}
+		btnOK.Disable()
+		content.Add(widget.NewLabel(fmt.Sprintf(T("guard_type_word"), word)))
+		content.Add(entry)
+	}
+	btnCancel := widget.NewButton(T("cancel"), func() { d.Hide() })
+	d = dialog.NewCustomWithoutButtons(T("guard_title"), content, w)
+	d.(*dialog.CustomDialog).SetButtons([]fyne.CanvasObject{btnCancel, btnOK})
+	d.Resize(fyne.NewSize(520, 0))
+	d.Show()
+}
+
+// showFlashSizeError explains why an image was refused.
+func showFlashSizeError(w fyne.Window, err error) {
+	var tooLarge *adb.ImageTooLargeError
+	if errors.As(err, &tooLarge) {
+		err = fmt.Errorf(T("guard_too_large"), formatFileSize(tooLarge.Image), tooLarge.Partition, formatFileSize(tooLarge.Capacity))
+	}
+	dialog.ShowError(err, w)
+}
+
+// confirmFlashWarnings runs flash directly, or after the user confirmed the
+// slot warnings for flashing partition to slot.
+func confirmFlashWarnings(w fyne.Window, info *adb.GetVarInfo, partition, slot string, flash func()) {
+	warnings := info.FlashWarnings(partition, slot)
+	if len(warnings) == 0 {
+		flash()
+		return
+	}
+	lines := make([]string, len(warnings))
+	for i, wn := range warnings {
+		lines[i] = "• " + fmt.Sprintf(T("slot_warn_"+wn.Kind), wn.Subject)
+	}
+	dialog.ShowConfirm(T("slot_warning"), strings.Join(lines, "\n")+"\n\n"+T("slot_flash_anyway"), func(ok bool) {
+		if ok {
+			flash()
+		}
+	}, w)
+}
+
+// addImages makes extracted images available in the flash dialog.
+func (p *slotPanel) addImages(images map[string]string) {
+	for name, path := range images {
+		p.images[name] = path
+	}
+}
@@ -0,0 +1,176 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"adb-gui/internal/adb"
+	"adb-gui/internal/config"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/widget"
+)
+
+// newStringTable builds a read-only table with a bold header row over rows().
+func newStringTable(headers []string, widths []float32, rows func() [][]string) *widget.Table {
+	t := widget.NewTable(
+		func() (int, int) { return len(rows()) + 1, len(headers) },
+		func() fyne.CanvasObject {
+			l := widget.NewLabel("")
+			l.Truncation = fyne.TextTruncateEllipsis
+			return l
+		},
+		func(id widget.TableCellID, o fyne.CanvasObject) {
+			l := o.(*widget.Label)
+			l.TextStyle = fyne.TextStyle{Bold: id.Row == 0}
+			switch r := rows(); {
+			case id.Row == 0:
+				l.SetText(headers[id.Col])
+			case id.Row-1 < len(r) && id.Col < len(r[id.Row-1]):
+				l.SetText(r[id.Row-1][id.Col])
+			default:
+				l.SetText("")
+			}
+		},
+	)
+	for i, wd := range widths {
+		t.SetColumnWidth(i, wd)
+	}
+	return t
+}
+
+// buildGetVarTab shows "fastboot getvar all" as variables, partition and slot
+// tables, with the raw output on its own tab.
+func buildGetVarTab(w fyne.Window, mgr *adb.Manager, selectedSerialBind binding.String, devices *[]adb.Device, cfg *config.Config) fyne.CanvasObject {
+	var (
+		vars, parts, slots [][]string
+		gen                int
+	)
+	summary := widget.NewLabel("")
+	summary.Wrapping = fyne.TextWrapWord
+	raw := widget.NewMultiLineEntry()
+	raw.TextStyle = fyne.TextStyle{Monospace: true}
+	raw.Wrapping = fyne.TextWrapOff
+
+	varTable := newStringTable([]string{T("getvar_name"), T("props_value")}, []float32{260, 520},
+		func() [][]string { return vars })
+	partTable := newStringTable(
+		[]string{T("getvar_partition"), T("getvar_type"), T("getvar_size"), T("getvar_size_hex"), T("getvar_logical"), T("getvar_has_slot")},
+		[]float32{200, 80, 100, 170, 80, 80},
+		func() [][]string { return parts })
+	slotTable := newStringTable(
+		[]string{T("getvar_slot"), T("getvar_successful"), T("getvar_unbootable"), T("getvar_retry_count")},
+		[]float32{80, 110, 110, 110},
+		func() [][]string { return slots })
+
+	show := func(g *adb.GetVarInfo) {
+		vars, parts, slots = nil, nil, nil
+		if g != nil {
+			keys := make([]string, 0, len(g.Vars))
+			for k := range g.Vars {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				vars = append(vars, []string{k, g.Vars[k]})
+			}
+			yesNo := func(b bool) string {
+				if b {
+					return "yes"
+				}
+				return ""
+			}
+			for _, p := range g.Partitions {
+				size := ""
+				if p.Size >= 0 {
+					size = formatFileSize(p.Size)
+				}
+				parts = append(parts, []string{p.Name, p.Type, size, p.SizeHex, yesNo(p.Logical), yesNo(p.HasSlot)})
+			}
+			for _, s := range g.Slots {
+				name := s.Name
+				if s.Name == g.CurrentSlot {
+					name += " *"
+				}
+				slots = append(slots, []string{name, s.Successful, s.Unbootable, s.RetryCount})
+			}
+			lock := g.Unlocked
+			if lock == "" {
+				lock = "?"
+			}
+			text := fmt.Sprintf(T("getvar_summary"), g.Vars["product"], g.Vars["serialno"], lock, g.Secure, len(g.Partitions))
+			if g.CurrentSlot != "" {
+				text += "  " + fmt.Sprintf(T("getvar_current_slot"), g.CurrentSlot)
+			}
+			summary.SetText(text)
+			raw.SetText(g.Raw)
+		}
+		varTable.Refresh()
+		partTable.Refresh()
+		slotTable.Refresh()
+	}
+
+	doRefresh := func() {
+		serial := mustGet(selectedSerialBind)
+		gen++
+		myGen := gen
+		if serial == "" {
+			show(nil)
+			summary.SetText(T("select_device"))
+			raw.SetText("")
+			return
+		}
+		summary.SetText(T("loading"))
+		go func() {
+			g, out, err := mgr.GetVarAll(serial)
+			var nf *adb.NotInFastbootError
+			fyne.Do(func() {
+				if myGen != gen {
+					return
+				}
+				if errors.As(err, &nf) {
+					show(nil)
+					summary.SetText(fmt.Sprintf(T("guard_not_fastboot"), serial))
+					raw.SetText("")
+					return
+				}
+				if err != nil {
+					show(nil)
+					summary.SetText(T("error") + ": " + firstLine(strings.TrimSpace(out+"\n"+err.Error())))
+					raw.SetText(out)
+					return
+				}
+				show(g)
+			})
+		}()
+	}
+
+	btnRefresh := widget.NewButton(T("refresh"), doRefresh)
+	btnSnapshot := widget.NewButton(T("snapshot_save"), func() {
+		saveSnapshot(w, mgr, cfg, mustGet(selectedSerialBind), adb.SnapshotGetVar)
+	})
+	btnCompare := widget.NewButton(T("snapshot_compare"), func() {
+		showPropDiff(mgr, *devices, cfg)
+	})
+	btnCopyRaw := widget.NewButton(T("getvar_copy_raw"), func() {
+		w.Clipboard().SetContent(raw.Text)
+	})
+
+	selectedSerialBind.AddListener(binding.NewDataListener(doRefresh))
+
+	tabs := container.NewAppTabs(
+		container.NewTabItem(T("getvar_variables"), varTable),
+		container.NewTabItem(T("getvar_partitions"), partTable),
+		container.NewTabItem(T("getvar_slots"), slotTable),
+		container.NewTabItem(T("getvar_raw"), raw),
+	)
+	top := container.NewVBox(
+		container.NewHBox(widget.NewLabel(T("getvar")), btnRefresh, btnSnapshot, btnCompare, btnCopyRaw),
+		summary,
+	)
+	return container.NewBorder(top, nil, nil, nil, tabs)
+}
@@ -318,6 +318,403 @@ func (i *I18n) initTranslations() {
 		"cancel":                 "取消",
 		"ok":                     "确定",
 		"close":                  "关闭",
+
+		// Trash
+		"safe_delete":                   "安全删除（移至设备回收站）",
+		"trash":                         "回收站",
+		"undo":                          "撤销",
+		"moved_to_trash":                "已将 %d 项移至回收站。",
+		"restore":                       "恢复",
+		"restore_failed":                "恢复失败",
+		"purge":                         "永久删除",
+		"empty_trash":                   "清空回收站",
+		"trash_empty":                   "回收站为空。",
+		"trash_unavailable":             "当前路径不在用户存储卷中，没有回收站。",
+		"deleted_at":                    "删除于",
+		"confirm_delete_system":         "确认删除系统路径",
+		"confirm_delete_system_message": "以下路径不在用户存储中，将被永久删除且无法恢复：\n\n%s",
+
+		// Preview
+		"preview_none":          "单击文件以预览。",
+		"preview_truncated":     "（已截断，只读）",
+		"use_root":              "使用 root (su)",
+		"save_failed":           "保存失败",
+		"save_complete":         "已保存到设备。",
+		"save_conflict":         "文件已更改",
+		"save_conflict_message": "设备上的文件在加载后已被修改。仍要覆盖吗？",
+
+		// Search
+		"search":                  "搜索…",
+		"stop":                    "停止",
+		"search_glob":             "通配符",
+		"search_regex":            "正则",
+		"search_type_any":         "任意",
+		"search_type_file":        "文件",
+		"search_type_dir":         "目录",
+		"search_type_link":        "符号链接",
+		"search_modified":         "修改时间 (从/到)",
+		"search_date_placeholder": "YYYY-MM-DD 或天数",
+		"searching":               "正在搜索…",
+		"search_results":          "搜索结果",
+		"search_cancelled":        "已取消",
+		"search_truncated":        "结果过多，只显示前 %d 项。",
+
+		// Storage usage
+		"usage":                 "空间分析",
+		"usage_free":            "可用",
+		"usage_total":           "合计",
+		"usage_code":            "代码",
+		"usage_data":            "数据",
+		"usage_cache":           "缓存",
+		"usage_directories":     "目录",
+		"usage_drill_down":      "进入",
+		"usage_open_in_browser": "在文件浏览器中打开",
+		"package":               "包名",
+		"sort":                  "排序:",
+		"loading":               "加载中…",
+
+		// Transfers
+		"upload_folder":      "上传文件夹…",
+		"transfer_cancelled": "传输已取消",
+
+		// Volumes and bookmarks
+		"volume":                "存储卷:",
+		"select_volume":         "选择存储卷",
+		"volume_not_mounted":    "该存储卷未挂载（%s）。",
+		"volume_internal":       "内部存储 (/data)",
+		"volume_emulated":       "内部共享存储",
+		"volume_sdcard":         "SD卡",
+		"volume_usb":            "USB存储",
+		"volume_adopted":        "已合并的存储",
+		"volume_public":         "便携式存储",
+		"bookmarks":             "书签",
+		"edit_bookmarks":        "编辑书签…",
+		"bookmark_current_path": "添加当前路径",
+
+		// Root mode
+		"root_mode":                       "Root 模式",
+		"root_mode_active":                "⚠ 正在以 ROOT 身份操作（%s）",
+		"root_not_available":              "此设备无法获取 root 权限",
+		"root_not_available_try_adb_root": "未检测到 su（Magisk/KernelSU）。是否尝试 \"adb root\"？这仅适用于 userdebug/eng 版本，并会重启 adbd。",
+
+		// Archive transfers
+		"download_archive":    "下载为归档",
+		"upload_extract":      "上传并解压",
+		"unsupported_archive": "仅支持 .tar、.tar.gz/.tgz 和 .zip 归档",
+
+		// Media: screenshots
+		"media":               "媒体",
+		"media_folder":        "媒体保存目录",
+		"screenshot":          "截图",
+		"screenshot_failed":   "截图失败",
+		"capture_all_devices": "全部设备截图",
+		"gallery":             "图库",
+		"annotate_arrow":      "箭头",
+		"annotate_box":        "方框",
+		"annotate_text":       "文字",
+		"annotate_crop":       "裁剪",
+		"text":                "文字",
+		"save_as":             "另存为",
+		"saved_to":            "已保存到：",
+		"copy_to_clipboard":   "复制到剪贴板",
+		"copy_failed":         "复制失败",
+
+		// Media: screen recording
+		"screen_recording":         "屏幕录制",
+		"record":                   "录制",
+		"record_all_devices":       "全部设备录制",
+		"stop_all":                 "全部停止",
+		"clear_finished":           "清除已完成",
+		"default":                  "默认",
+		"native":                   "原始分辨率",
+		"record_bit_rate":          "码率",
+		"record_size":              "尺寸",
+		"record_time_limit":        "时长（秒）",
+		"record_until_stopped":     "直到停止",
+		"record_bugreport_overlay": "叠加时间戳（--bugreport）",
+		"record_segment":           "分段",
+		"record_pulling":           "正在拉取并合并…",
+		"record_empty":             "未录制到内容",
+		"record_already_running":   "该设备正在录制",
+
+		// Media: mirroring
+		"mirror_screen":       "屏幕镜像",
+		"mirror_mode":         "画面传输",
+		"mirror_mode_auto":    "自动（USB 原始 / 无线 PNG）",
+		"mirror_mode_raw":     "原始像素（快，USB）",
+		"mirror_mode_png":     "PNG（省带宽，无线）",
+		"mirror_frame_failed": "获取画面失败",
+		"key_back":            "返回",
+		"key_home":            "主页",
+		"key_recents":         "最近任务",
+		"key_volume_down":     "音量-",
+		"key_volume_up":       "音量+",
+		"key_power":           "电源",
+
+		// Logcat
+		"logcat":                 "日志",
+		"logcat_start":           "开始",
+		"logcat_default_buffers": "默认缓冲区",
+		"logcat_tag":             "标签",
+		"logcat_pause":           "暂停",
+		"logcat_auto_scroll":     "自动滚动",
+		"logcat_clear_device":    "清空设备日志",
+		"logcat_status":          "显示 %d / 缓冲 %d 行",
+		"logcat_pkg_not_running": "%s 未在运行（按回车重新查找进程）",
+		"logcat_level_verbose":   "详细",
+		"logcat_level_debug":     "调试",
+		"logcat_level_info":      "信息",
+		"logcat_level_warn":      "警告",
+		"logcat_level_error":     "错误",
+		"logcat_level_fatal":     "致命",
+		"clear":                  "清空",
+		"export":                 "导出",
+
+		// Crashes
+		"crashes":             "崩溃",
+		"crash_kind":          "类型",
+		"crash_title":         "异常 / 原因",
+		"crash_count":         "次数",
+		"crash_last_seen":     "最近发生",
+		"crash_export_bundle": "导出报告包",
+		"crash_scan_now":      "立即扫描",
+		"crash_watch":         "持续监视已连接设备",
+		"crash_scanning":      "正在扫描崩溃缓冲区和 dropbox…",
+		"crash_scan_done":     "已扫描 %d 台设备，新增 %d 条（%s）",
+
+		// Bugreport
+		"bugreport":                 "错误报告",
+		"bugreport_open":            "打开错误报告",
+		"bugreport_running":         "正在生成错误报告，可能需要几分钟…",
+		"bugreport_failed":          "生成错误报告失败",
+		"bugreport_open_now":        "现在打开？",
+		"bugreport_filter_sections": "筛选章节",
+		"bugreport_search":          "全文搜索（回车）",
+		"bugreport_sections":        "%d 个章节",
+		"all":                       "全部",
+
+		// Shell
+		"shell":                   "终端",
+		"shell_no_sessions":       "没有打开的会话。选择设备后点击“新建会话”。",
+		"shell_new_session":       "新建会话",
+		"shell_new_all":           "为所有设备新建会话",
+		"shell_run_as_root":       "以 root 运行 (su)",
+		"shell_line_mode":         "行模式 (无 PTY)",
+		"shell_broadcast":         "广播到所有会话",
+		"shell_start_failed":      "启动 shell 失败",
+		"shell_exited":            "已退出",
+		"shell_pty_fallback":      "[PTY 会话立即退出，改用行模式重试]",
+		"shell_input_placeholder": "输入命令，回车发送，↑/↓ 浏览历史",
+		"shell_send":              "发送",
+		"copy":                    "复制",
+		"paste":                   "粘贴",
+
+		// Properties
+		"props_search":           "搜索属性名或值",
+		"props_key":              "属性",
+		"props_value":            "值",
+		"props_group_other":      "其他",
+		"props_was":              "原值: %s",
+		"props_setprop":          "设置 (setprop)",
+		"props_status":           "%d 个属性，刷新于 %s",
+		"props_changed":          "%d 个已变化",
+		"props_watch":            "监视变化",
+		"props_clear_highlights": "清除高亮",
+		"props_expand_all":       "全部展开",
+		"props_collapse_all":     "全部折叠",
+
+		// Property snapshots
+		"snapshot_save":          "保存快照",
+		"snapshot_compare":       "比较…",
+		"snapshot_file":          "快照",
+		"snapshot_left":          "左侧",
+		"snapshot_right":         "右侧",
+		"snapshot_change":        "变化",
+		"snapshot_added":         "新增",
+		"snapshot_removed":       "删除",
+		"snapshot_changed":       "修改",
+		"snapshot_build_keys":    "仅构建标识",
+		"snapshot_summary":       "新增 %d，删除 %d，修改 %d",
+		"snapshot_kind_mismatch": "正在比较 getprop 与 getvar 快照，大部分键不会匹配。",
+		"snapshot_run_compare":   "比较",
+
+		// Settings database
+		"settings_db":                     "设置数据库",
+		"settings_status":                 "%d 项 (%s，用户 %d)",
+		"settings_put":                    "写入",
+		"settings_new":                    "新建",
+		"settings_delete_confirm":         "删除 %s 中的 %s？",
+		"settings_quick":                  "快捷开关",
+		"settings_q_stay_awake":           "充电时保持唤醒",
+		"settings_q_show_taps":            "显示点按操作",
+		"settings_q_pointer_location":     "指针位置",
+		"settings_q_dont_keep_activities": "不保留活动",
+		"settings_q_animation":            "动画缩放",
+		"settings_q_private_dns":          "私人 DNS",
+		"settings_presets":                "预设",
+		"settings_apply_preset":           "应用到当前设备",
+		"settings_apply_all":              "应用到所有设备",
+		"settings_edit_presets":           "编辑预设…",
+		"settings_preset_entries":         "条目",
+		"settings_preset_delete":          "删除此预设",
+		"settings_preset_bad_line":        "第 %d 行格式错误: %s",
+		"settings_rollback":               "回滚…",
+		"settings_no_backups":             "没有可回滚的预设记录。",
+		"apply":                           "应用",
+		"name":                            "名称",
+
+		// Fastboot getvar
+		"getvar_name":         "变量",
+		"getvar_partition":    "分区",
+		"getvar_type":         "类型",
+		"getvar_size":         "大小",
+		"getvar_size_hex":     "大小 (十六进制)",
+		"getvar_logical":      "逻辑分区",
+		"getvar_has_slot":     "有槽位",
+		"getvar_slot":         "槽位",
+		"getvar_successful":   "启动成功",
+		"getvar_unbootable":   "不可启动",
+		"getvar_retry_count":  "重试次数",
+		"getvar_summary":      "产品: %s  序列号: %s  已解锁: %s  secure: %s  分区: %d",
+		"getvar_current_slot": "当前槽位: %s",
+		"getvar_copy_raw":     "复制原始输出",
+		"getvar_variables":    "变量",
+		"getvar_partitions":   "分区表",
+		"getvar_slots":        "槽位",
+		"getvar_raw":          "原始输出",
+
+		// Slots
+		"slot_management":         "A/B 槽位",
+		"slot_mode":               "模式: %s",
+		"slot_mode_bootloader":    "Bootloader (fastboot)",
+		"slot_mode_fastbootd":     "用户空间 (fastbootd)",
+		"slot_not_ab":             "设备不支持 A/B 槽位",
+		"slot_not_fastboot":       "未处于 fastboot 模式，重启到 bootloader 后可管理槽位",
+		"slot_set_active":         "设为活动槽位 %s",
+		"slot_set_active_confirm": "下次启动将使用槽位 %s，确定吗？",
+		"reboot_fastbootd":        "重启到 fastbootd",
+		"slot_default":            "默认 (当前槽位)",
+		"slot_all":                "全部槽位 (--slot=all)",
+		"slot_warning":            "槽位警告",
+		"slot_warn_inactive":      "槽位 %s 不是当前活动槽位",
+		"slot_warn_unbootable":    "槽位 %s 被标记为不可启动",
+		"slot_warn_not_slotted":   "分区 %s 没有 A/B 槽位，将忽略槽位选择",
+		"slot_flash_anyway":       "仍然刷写？",
+
+		// Flash planner
+		"planner_title":            "刷机计划",
+		"planner_no_source":        "未选择出厂镜像或更新包",
+		"planner_open_zip":         "打开 Zip…",
+		"planner_open_folder":      "打开文件夹…",
+		"planner_extracting":       "正在解压 %s…",
+		"planner_checks":           "设备检查",
+		"planner_no_getvar":        "无法读取设备 getvar，请确认设备处于 fastboot 模式",
+		"planner_required":         "需要",
+		"planner_device":           "设备",
+		"planner_product_mismatch": "镜像与设备型号不匹配，已禁止刷写",
+		"planner_wipe":             "清除用户数据 (-w)",
+		"planner_skip_current":     "跳过已是所需版本的 bootloader/基带",
+		"planner_run":              "执行计划",
+		"planner_step_flash":       "刷写 %s",
+		"planner_step_reboot":      "重启到 %s",
+		"planner_step_update":      "更新 %s",
+		"planner_state_pending":    "等待",
+		"planner_state_running":    "执行中",
+		"planner_state_done":       "完成",
+		"planner_state_failed":     "失败",
+		"planner_state_skipped":    "未执行",
+		"planner_confirm":          "将在设备 %[2]s 上执行 %[1]d 个步骤，期间请勿断开设备。继续吗？",
+		"planner_confirm_wipe":     "用户数据将被清除！",
+		"planner_done":             "所有步骤已成功完成",
+		"planner_failed_title":     "刷写失败",
+		"planner_failed":           "第 %d/%d 步失败：\n%s\n\n%v",
+		"planner_not_run":          "后续 %d 个步骤未执行",
+		"planner_busy":             "计划正在执行，请等待完成",
+
+		// Payload extractor
+		"payload_title":         "提取 payload.bin…",
+		"payload_no_source":     "未选择 payload.bin 或 OTA 包",
+		"payload_open":          "打开 payload.bin / OTA…",
+		"payload_choose_output": "输出目录…",
+		"payload_output":        "输出到",
+		"payload_info":          "%d 个分区，块大小 %d，%s",
+		"payload_full":          "完整 OTA",
+		"payload_incremental":   "增量 OTA，不支持",
+		"payload_extract":       "提取",
+		"payload_extracting":    "正在提取 %s (%d/%d)…",
+		"payload_done":          "已提取 %d 个镜像到 %s",
+		"flash_image":           "镜像",
+		"flash_choose_file":     "选择文件…",
+
+		// Boot image patching
+		"bootpatch_title":           "修补启动镜像 (Magisk)…",
+		"bootpatch_no_image":        "未选择 boot / init_boot 镜像",
+		"bootpatch_image":           "镜像",
+		"bootpatch_choose":          "选择文件…",
+		"bootpatch_extracted":       "已提取的镜像",
+		"bootpatch_start":           "开始",
+		"bootpatch_open_magisk":     "打开 Magisk",
+		"bootpatch_step_push":       "推送镜像到设备的 Download 目录",
+		"bootpatch_step_patch":      "在 Magisk 中修补，等待 magisk_patched-*.img",
+		"bootpatch_step_pull":       "拉取修补后的镜像",
+		"bootpatch_step_bootloader": "重启到 bootloader",
+		"bootpatch_hint_push":       "正在推送镜像…",
+		"bootpatch_hint_patch":      "在 Magisk 中点击 安装 → 选择并修补一个文件，选择刚推送的镜像。修补完成后会自动继续。",
+		"bootpatch_hint_pull":       "正在拉取修补后的镜像…",
+		"bootpatch_hint_bootloader": "正在重启到 bootloader，等待设备进入 fastboot…",
+		"bootpatch_failed":          "步骤“%s”失败: %v",
+		"bootpatch_patched":         "修补后的镜像",
+		"bootpatch_ready":           "设备已进入 fastboot。可以先临时启动测试，再永久刷写。",
+		"bootpatch_test_boot":       "临时启动 (fastboot boot)",
+		"bootpatch_test_booted":     "已临时启动。确认 root 正常后，重新进入 bootloader 再刷写。",
+		"bootpatch_flash":           "永久刷写",
+
+		// Image tools
+		"imgtools_title":        "镜像工具…",
+		"imgtools_no_image":     "未选择镜像",
+		"imgtools_open":         "打开镜像…",
+		"imgtools_format":       "格式",
+		"imgtools_sparse":       "Android sparse",
+		"imgtools_raw":          "原始 (raw)",
+		"imgtools_file_size":    "文件大小",
+		"imgtools_raw_size":     "展开大小",
+		"imgtools_chunks":       "块段",
+		"imgtools_algorithm":    "算法",
+		"imgtools_rollback":     "回滚索引",
+		"imgtools_location":     "位置",
+		"imgtools_flags":        "标志",
+		"imgtools_release":      "生成工具",
+		"imgtools_chained":      "链式分区",
+		"imgtools_to_raw":       "转换为 raw (simg2img)",
+		"imgtools_to_sparse":    "转换为 sparse (img2simg)",
+		"imgtools_max_size":     "每片最大 (MiB)",
+		"imgtools_split":        "拆分",
+		"imgtools_bad_size":     "请输入有效的大小 (MiB)",
+		"imgtools_split_done":   "已拆分为 %d 个 sparse 文件，保存在 %s",
+		"imgtools_chunk_counts": "原始 %d，填充 %d，无关 %d",
+		"imgtools_avb_footer":   "AVB 尾部",
+		"imgtools_key_bytes":    "%d 字节密钥",
+		"flash_vbmeta_flags":    "vbmeta 选项",
+		"flash_piece":           "第 %d/%d 片",
+
+		// Fastboot safety
+		"guard_title":        "确认危险操作",
+		"guard_not_fastboot": "设备 %s 不在 fastboot 模式（fastboot devices 中未列出）。请先重启到 bootloader。",
+		"guard_device":       "型号: %s\n序列号: %s (%s)\n已解锁: %s",
+		"guard_type_word":    "请输入 %s 以确认：",
+		"guard_unlock_msg":   "解锁 bootloader 会清除设备上的所有用户数据。",
+		"guard_edl_msg":      "设备将进入 EDL (紧急下载) 模式，退出需要专用工具或长按按键。",
+		"guard_flash_msg":    "将 %s 刷写到 %s 分区，原有内容将被覆盖。",
+		"guard_too_large":    "镜像大小 %s 超过分区 %s 的容量 %s，已阻止刷写。",
+
+		// Settings backups
+		"settings_backup_failed": "无法写入设置备份，未应用预设",
+
+		// Upload queue
+		"upload_add_file":    "添加文件…",
+		"upload_add_folder":  "添加文件夹…",
+		"upload_remove":      "移除",
+		"upload_queue_count": "待上传 %d 项",
 	}
 
 	// English translations
@@ -462,6 +859,403 @@ func (i *I18n) initTranslations() {
 		"cancel":                 "Cancel",
 		"ok":                     "OK",
 		"close":                  "Close",
+
+		// Trash
+		"safe_delete":                   "Safe delete (move to on-device trash)",
+		"trash":                         "Trash",
+		"undo":                          "Undo",
+		"moved_to_trash":                "Moved %d item(s) to trash.",
+		"restore":                       "Restore",
+		"restore_failed":                "Restore failed",
+		"purge":                         "Delete Permanently",
+		"empty_trash":                   "Empty Trash",
+		"trash_empty":                   "Trash is empty.",
+		"trash_unavailable":             "The current path is not on a user storage volume and has no trash.",
+		"deleted_at":                    "deleted",
+		"confirm_delete_system":         "Confirm System Path Delete",
+		"confirm_delete_system_message": "The following paths are outside user storage and will be permanently deleted. This cannot be undone:\n\n%s",
+
+		// Preview
+		"preview_none":          "Click a file to preview it.",
+		"preview_truncated":     "(truncated, read-only)",
+		"use_root":              "Use root (su)",
+		"save_failed":           "Save failed",
+		"save_complete":         "Saved to device.",
+		"save_conflict":         "File Changed",
+		"save_conflict_message": "The file on the device was modified after it was loaded. Overwrite anyway?",
+
+		// Search
+		"search":                  "Search…",
+		"stop":                    "Stop",
+		"search_glob":             "Glob",
+		"search_regex":            "Regex",
+		"search_type_any":         "Any",
+		"search_type_file":        "File",
+		"search_type_dir":         "Directory",
+		"search_type_link":        "Symlink",
+		"search_modified":         "Modified (from/to)",
+		"search_date_placeholder": "YYYY-MM-DD or days ago",
+		"searching":               "Searching…",
+		"search_results":          "Results",
+		"search_cancelled":        "Cancelled",
+		"search_truncated":        "Too many results; showing the first %d.",
+
+		// Storage usage
+		"usage":                 "Usage",
+		"usage_free":            "free",
+		"usage_total":           "Total",
+		"usage_code":            "Code",
+		"usage_data":            "Data",
+		"usage_cache":           "Cache",
+		"usage_directories":     "Directories",
+		"usage_drill_down":      "Drill Down",
+		"usage_open_in_browser": "Open in Browser",
+		"package":               "Package",
+		"sort":                  "Sort:",
+		"loading":               "Loading…",
+
+		// Transfers
+		"upload_folder":      "Upload Folder…",
+		"transfer_cancelled": "Transfer cancelled",
+
+		// Volumes and bookmarks
+		"volume":                "Volume:",
+		"select_volume":         "Select volume",
+		"volume_not_mounted":    "This volume is not mounted (%s).",
+		"volume_internal":       "Internal (/data)",
+		"volume_emulated":       "Internal shared storage",
+		"volume_sdcard":         "SD card",
+		"volume_usb":            "USB drive",
+		"volume_adopted":        "Adopted storage",
+		"volume_public":         "Portable storage",
+		"bookmarks":             "Bookmarks",
+		"edit_bookmarks":        "Edit Bookmarks…",
+		"bookmark_current_path": "Add Current Path",
+
+		// Root mode
+		"root_mode":                       "Root mode",
+		"root_mode_active":                "⚠ Operating as ROOT (%s)",
+		"root_not_available":              "Root is not available on this device",
+		"root_not_available_try_adb_root": "No su (Magisk/KernelSU) was found. Try \"adb root\"? This only works on userdebug/eng builds and restarts adbd.",
+
+		// Archive transfers
+		"download_archive":    "Download as Archive",
+		"upload_extract":      "Upload & Extract",
+		"unsupported_archive": "Only .tar, .tar.gz/.tgz and .zip archives are supported",
+
+		// Media: screenshots
+		"media":               "Media",
+		"media_folder":        "Media folder",
+		"screenshot":          "Screenshot",
+		"screenshot_failed":   "Screenshot failed",
+		"capture_all_devices": "Capture All Devices",
+		"gallery":             "Gallery",
+		"annotate_arrow":      "Arrow",
+		"annotate_box":        "Box",
+		"annotate_text":       "Text",
+		"annotate_crop":       "Crop",
+		"text":                "Text",
+		"save_as":             "Save As",
+		"saved_to":            "Saved to:",
+		"copy_to_clipboard":   "Copy to Clipboard",
+		"copy_failed":         "Copy failed",
+
+		// Media: screen recording
+		"screen_recording":         "Screen recording",
+		"record":                   "Record",
+		"record_all_devices":       "Record All Devices",
+		"stop_all":                 "Stop All",
+		"clear_finished":           "Clear Finished",
+		"default":                  "Default",
+		"native":                   "Native",
+		"record_bit_rate":          "Bit rate",
+		"record_size":              "Size",
+		"record_time_limit":        "Length (s)",
+		"record_until_stopped":     "until stopped",
+		"record_bugreport_overlay": "Timestamp overlay (--bugreport)",
+		"record_segment":           "segment",
+		"record_pulling":           "pulling and joining…",
+		"record_empty":             "nothing recorded",
+		"record_already_running":   "already recording",
+
+		// Media: mirroring
+		"mirror_screen":       "Mirror Screen",
+		"mirror_mode":         "Frames",
+		"mirror_mode_auto":    "Auto (raw on USB, PNG on wireless)",
+		"mirror_mode_raw":     "Raw pixels (fast, USB)",
+		"mirror_mode_png":     "PNG (less bandwidth, wireless)",
+		"mirror_frame_failed": "Frame capture failed",
+		"key_back":            "Back",
+		"key_home":            "Home",
+		"key_recents":         "Recents",
+		"key_volume_down":     "Vol -",
+		"key_volume_up":       "Vol +",
+		"key_power":           "Power",
+
+		// Logcat
+		"logcat":                 "Logcat",
+		"logcat_start":           "Start",
+		"logcat_default_buffers": "Default buffers",
+		"logcat_tag":             "Tag",
+		"logcat_pause":           "Pause",
+		"logcat_auto_scroll":     "Auto-scroll",
+		"logcat_clear_device":    "Clear Device Log",
+		"logcat_status":          "Showing %d of %d lines",
+		"logcat_pkg_not_running": "%s is not running (press Enter to look it up again)",
+		"logcat_level_verbose":   "Verbose",
+		"logcat_level_debug":     "Debug",
+		"logcat_level_info":      "Info",
+		"logcat_level_warn":      "Warn",
+		"logcat_level_error":     "Error",
+		"logcat_level_fatal":     "Fatal",
+		"clear":                  "Clear",
+		"export":                 "Export",
+
+		// Crashes
+		"crashes":             "Crashes",
+		"crash_kind":          "Kind",
+		"crash_title":         "Exception / reason",
+		"crash_count":         "Count",
+		"crash_last_seen":     "Last seen",
+		"crash_export_bundle": "Export Bundle",
+		"crash_scan_now":      "Scan Now",
+		"crash_watch":         "Watch connected devices",
+		"crash_scanning":      "Scanning crash buffer and dropbox…",
+		"crash_scan_done":     "Scanned %d device(s), %d new (%s)",
+
+		// Bugreport
+		"bugreport":                 "Bugreport",
+		"bugreport_open":            "Open Bugreport",
+		"bugreport_running":         "Generating bugreport, this can take several minutes…",
+		"bugreport_failed":          "Bugreport failed",
+		"bugreport_open_now":        "Open it now?",
+		"bugreport_filter_sections": "Filter sections",
+		"bugreport_search":          "Search all sections (Enter)",
+		"bugreport_sections":        "%d sections",
+		"all":                       "All",
+
+		// Shell
+		"shell":                   "Shell",
+		"shell_no_sessions":       "No open sessions. Select a device and click New Session.",
+		"shell_new_session":       "New Session",
+		"shell_new_all":           "Sessions for All Devices",
+		"shell_run_as_root":       "Run as root (su)",
+		"shell_line_mode":         "Line mode (no PTY)",
+		"shell_broadcast":         "Broadcast to all sessions",
+		"shell_start_failed":      "Failed to start shell",
+		"shell_exited":            "exited",
+		"shell_pty_fallback":      "[PTY session exited immediately, retrying in line mode]",
+		"shell_input_placeholder": "Type a command, Enter to send, Up/Down for history",
+		"shell_send":              "Send",
+		"copy":                    "Copy",
+		"paste":                   "Paste",
+
+		// Properties
+		"props_search":           "Search keys and values",
+		"props_key":              "Property",
+		"props_value":            "Value",
+		"props_group_other":      "other",
+		"props_was":              "was: %s",
+		"props_setprop":          "Set (setprop)",
+		"props_status":           "%d properties, refreshed %s",
+		"props_changed":          "%d changed",
+		"props_watch":            "Watch",
+		"props_clear_highlights": "Clear Highlights",
+		"props_expand_all":       "Expand All",
+		"props_collapse_all":     "Collapse All",
+
+		// Property snapshots
+		"snapshot_save":          "Save Snapshot",
+		"snapshot_compare":       "Compare…",
+		"snapshot_file":          "Snapshot",
+		"snapshot_left":          "Left",
+		"snapshot_right":         "Right",
+		"snapshot_change":        "Change",
+		"snapshot_added":         "added",
+		"snapshot_removed":       "removed",
+		"snapshot_changed":       "changed",
+		"snapshot_build_keys":    "Build keys only",
+		"snapshot_summary":       "%d added, %d removed, %d changed",
+		"snapshot_kind_mismatch": "Comparing a getprop snapshot with a getvar snapshot; most keys will not match.",
+		"snapshot_run_compare":   "Compare",
+
+		// Settings database
+		"settings_db":                     "Settings DB",
+		"settings_status":                 "%d entries (%s, user %d)",
+		"settings_put":                    "Put",
+		"settings_new":                    "New",
+		"settings_delete_confirm":         "Delete %[2]s from %[1]s?",
+		"settings_quick":                  "Quick Toggles",
+		"settings_q_stay_awake":           "Stay awake while charging",
+		"settings_q_show_taps":            "Show taps",
+		"settings_q_pointer_location":     "Pointer location",
+		"settings_q_dont_keep_activities": "Don't keep activities",
+		"settings_q_animation":            "Animation scale",
+		"settings_q_private_dns":          "Private DNS",
+		"settings_presets":                "Presets",
+		"settings_apply_preset":           "Apply to Device",
+		"settings_apply_all":              "Apply to All Devices",
+		"settings_edit_presets":           "Edit Presets…",
+		"settings_preset_entries":         "Entries",
+		"settings_preset_delete":          "Delete this preset",
+		"settings_preset_bad_line":        "Line %d is not \"namespace key=value\" or \"delete namespace key\": %s",
+		"settings_rollback":               "Roll Back…",
+		"settings_no_backups":             "No recorded preset changes to roll back.",
+		"apply":                           "Apply",
+		"name":                            "Name",
+
+		// Fastboot getvar
+		"getvar_name":         "Variable",
+		"getvar_partition":    "Partition",
+		"getvar_type":         "Type",
+		"getvar_size":         "Size",
+		"getvar_size_hex":     "Size (hex)",
+		"getvar_logical":      "Logical",
+		"getvar_has_slot":     "Has slot",
+		"getvar_slot":         "Slot",
+		"getvar_successful":   "Successful",
+		"getvar_unbootable":   "Unbootable",
+		"getvar_retry_count":  "Retry count",
+		"getvar_summary":      "Product: %s  Serial: %s  Unlocked: %s  Secure: %s  Partitions: %d",
+		"getvar_current_slot": "Current slot: %s",
+		"getvar_copy_raw":     "Copy Raw Output",
+		"getvar_variables":    "Variables",
+		"getvar_partitions":   "Partitions",
+		"getvar_slots":        "Slots",
+		"getvar_raw":          "Raw",
+
+		// Slots
+		"slot_management":         "A/B Slots",
+		"slot_mode":               "Mode: %s",
+		"slot_mode_bootloader":    "bootloader (fastboot)",
+		"slot_mode_fastbootd":     "userspace (fastbootd)",
+		"slot_not_ab":             "Device has no A/B slots",
+		"slot_not_fastboot":       "Not in fastboot mode; reboot to the bootloader to manage slots",
+		"slot_set_active":         "Set Active %s",
+		"slot_set_active_confirm": "The device will boot from slot %s next time. Continue?",
+		"reboot_fastbootd":        "Reboot to fastbootd",
+		"slot_default":            "Default (current slot)",
+		"slot_all":                "All slots (--slot=all)",
+		"slot_warning":            "Slot Warning",
+		"slot_warn_inactive":      "Slot %s is not the active slot",
+		"slot_warn_unbootable":    "Slot %s is marked unbootable",
+		"slot_warn_not_slotted":   "Partition %s has no A/B slots; the slot choice is ignored",
+		"slot_flash_anyway":       "Flash anyway?",
+
+		// Flash planner
+		"planner_title":            "Flash Planner",
+		"planner_no_source":        "No factory image or update zip selected",
+		"planner_open_zip":         "Open Zip…",
+		"planner_open_folder":      "Open Folder…",
+		"planner_extracting":       "Extracting %s…",
+		"planner_checks":           "Device Checks",
+		"planner_no_getvar":        "Could not read getvar from the device; make sure it is in fastboot mode",
+		"planner_required":         "required",
+		"planner_device":           "device",
+		"planner_product_mismatch": "The image is not built for this device; flashing is disabled",
+		"planner_wipe":             "Wipe user data (-w)",
+		"planner_skip_current":     "Skip bootloader/radio already at the required version",
+		"planner_run":              "Run Plan",
+		"planner_step_flash":       "Flash %s",
+		"planner_step_reboot":      "Reboot to %s",
+		"planner_step_update":      "Update %s",
+		"planner_state_pending":    "pending",
+		"planner_state_running":    "running",
+		"planner_state_done":       "done",
+		"planner_state_failed":     "failed",
+		"planner_state_skipped":    "skipped",
+		"planner_confirm":          "%d steps will run on %s. Do not disconnect the device. Continue?",
+		"planner_confirm_wipe":     "All user data will be erased!",
+		"planner_done":             "All steps completed successfully",
+		"planner_failed_title":     "Flashing Failed",
+		"planner_failed":           "Step %d of %d failed:\n%s\n\n%v",
+		"planner_not_run":          "%d remaining steps were not run",
+		"planner_busy":             "The plan is still running; wait for it to finish",
+
+		// Payload extractor
+		"payload_title":         "Extract payload.bin…",
+		"payload_no_source":     "No payload.bin or OTA zip selected",
+		"payload_open":          "Open payload.bin / OTA…",
+		"payload_choose_output": "Output Folder…",
+		"payload_output":        "Output",
+		"payload_info":          "%d partitions, block size %d, %s",
+		"payload_full":          "full OTA",
+		"payload_incremental":   "incremental, not supported",
+		"payload_extract":       "Extract",
+		"payload_extracting":    "Extracting %s (%d/%d)…",
+		"payload_done":          "Extracted %d images to %s",
+		"flash_image":           "Image",
+		"flash_choose_file":     "Choose file…",
+
+		// Boot image patching
+		"bootpatch_title":           "Patch Boot Image (Magisk)…",
+		"bootpatch_no_image":        "No boot or init_boot image selected",
+		"bootpatch_image":           "Image",
+		"bootpatch_choose":          "Choose File…",
+		"bootpatch_extracted":       "Extracted image",
+		"bootpatch_start":           "Start",
+		"bootpatch_open_magisk":     "Open Magisk",
+		"bootpatch_step_push":       "Push image to Download on the device",
+		"bootpatch_step_patch":      "Patch in Magisk and wait for magisk_patched-*.img",
+		"bootpatch_step_pull":       "Pull the patched image back",
+		"bootpatch_step_bootloader": "Reboot to bootloader",
+		"bootpatch_hint_push":       "Pushing the image…",
+		"bootpatch_hint_patch":      "In Magisk tap Install → Select and Patch a File and pick the pushed image. The workflow continues once the patched image appears.",
+		"bootpatch_hint_pull":       "Pulling the patched image…",
+		"bootpatch_hint_bootloader": "Rebooting to the bootloader and waiting for fastboot…",
+		"bootpatch_failed":          "Step \"%s\" failed: %v",
+		"bootpatch_patched":         "Patched image",
+		"bootpatch_ready":           "The device is in fastboot. Test boot first, then flash permanently.",
+		"bootpatch_test_boot":       "Test Boot (fastboot boot)",
+		"bootpatch_test_booted":     "Booted once without flashing. If root works, return to the bootloader and flash.",
+		"bootpatch_flash":           "Flash Permanently",
+
+		// Image tools
+		"imgtools_title":        "Image Tools…",
+		"imgtools_no_image":     "No image selected",
+		"imgtools_open":         "Open Image…",
+		"imgtools_format":       "Format",
+		"imgtools_sparse":       "Android sparse",
+		"imgtools_raw":          "raw",
+		"imgtools_file_size":    "File size",
+		"imgtools_raw_size":     "Expanded size",
+		"imgtools_chunks":       "Chunks",
+		"imgtools_algorithm":    "Algorithm",
+		"imgtools_rollback":     "Rollback index",
+		"imgtools_location":     "location",
+		"imgtools_flags":        "Flags",
+		"imgtools_release":      "Release",
+		"imgtools_chained":      "Chained partitions",
+		"imgtools_to_raw":       "Convert to Raw (simg2img)",
+		"imgtools_to_sparse":    "Convert to Sparse (img2simg)",
+		"imgtools_max_size":     "Max piece (MiB)",
+		"imgtools_split":        "Split",
+		"imgtools_bad_size":     "Enter a valid size in MiB",
+		"imgtools_split_done":   "Split into %d sparse files in %s",
+		"imgtools_chunk_counts": "raw %d, fill %d, don't care %d",
+		"imgtools_avb_footer":   "AVB footer",
+		"imgtools_key_bytes":    "%d-byte key",
+		"flash_vbmeta_flags":    "vbmeta flags",
+		"flash_piece":           "piece %d/%d",
+
+		// Fastboot safety
+		"guard_title":        "Confirm Destructive Operation",
+		"guard_not_fastboot": "Device %s is not in fastboot mode (not listed by fastboot devices). Reboot it to the bootloader first.",
+		"guard_device":       "Product: %s\nSerial:  %s (%s)\nUnlocked: %s",
+		"guard_type_word":    "Type %s to confirm:",
+		"guard_unlock_msg":   "Unlocking the bootloader erases all user data on the device.",
+		"guard_edl_msg":      "The device will enter EDL (emergency download) mode; leaving it needs vendor tools or a long key press.",
+		"guard_flash_msg":    "%s will be flashed to the %s partition, overwriting its contents.",
+		"guard_too_large":    "The image (%s) is larger than partition %s (%s); flashing was blocked.",
+
+		// Settings backups
+		"settings_backup_failed": "Cannot write the settings backup; the preset was not applied",
+
+		// Upload queue
+		"upload_add_file":    "Add File…",
+		"upload_add_folder":  "Add Folder…",
+		"upload_remove":      "Remove",
+		"upload_queue_count": "%d item(s) to upload",
 	}
 }
 
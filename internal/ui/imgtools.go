@@ -0,0 +1,231 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"adb-gui/internal/imgtool"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// describeSparse summarizes the sparse layout of a local image.
+func describeSparse(im *imgtool.Image) string {
+	var sb strings.Builder
+	counts := im.ChunkCounts()
+	if im.Sparse {
+		fmt.Fprintf(&sb, "%s: %s\n", T("imgtools_format"), T("imgtools_sparse"))
+	} else {
+		fmt.Fprintf(&sb, "%s: %s\n", T("imgtools_format"), T("imgtools_raw"))
+	}
+	fmt.Fprintf(&sb, "%s: %s\n", T("imgtools_file_size"), formatFileSize(im.SourceSize))
+	fmt.Fprintf(&sb, "%s: %s (%d × %d)\n", T("imgtools_raw_size"), formatFileSize(im.RawSize()), im.TotalBlocks, im.BlockSize)
+	fmt.Fprintf(&sb, "%s: "+T("imgtools_chunk_counts")+"\n", T("imgtools_chunks"),
+		counts[imgtool.ChunkRaw], counts[imgtool.ChunkFill], counts[imgtool.ChunkDontCare])
+	return sb.String()
+}
+
+// describeVBMeta summarizes an AVB vbmeta structure.
+func describeVBMeta(vb *imgtool.VBMeta, vbErr error) string {
+	var sb strings.Builder
+	if vb == nil {
+		fmt.Fprintf(&sb, "vbmeta: %v\n", vbErr)
+		return sb.String()
+	}
+	where := "vbmeta"
+	if vb.Footer {
+		where = T("imgtools_avb_footer")
+	}
+	fmt.Fprintf(&sb, "vbmeta (%s)\n", where)
+	fmt.Fprintf(&sb, "  %s: %s\n", T("imgtools_algorithm"), vb.Algorithm)
+	fmt.Fprintf(&sb, "  %s: %d (%s %d)\n", T("imgtools_rollback"), vb.RollbackIndex, T("imgtools_location"), vb.RollbackLocation)
+	flags := strings.Join(vb.FlagNames(), ", ")
+	if flags == "" {
+		flags = "-"
+	}
+	fmt.Fprintf(&sb, "  %s: %d (%s)\n", T("imgtools_flags"), vb.Flags, flags)
+	fmt.Fprintf(&sb, "  %s: %s, libavb %s\n", T("imgtools_release"), vb.Release, vb.Version)
+	if chained := vb.Chained(); len(chained) > 0 {
+		fmt.Fprintf(&sb, "  %s:\n", T("imgtools_chained"))
+		for _, d := range chained {
+			fmt.Fprintf(&sb, "    %s (%s %d, "+T("imgtools_key_bytes")+")\n", d.Partition, T("imgtools_location"), d.Location, len(d.PublicKey))
+		}
+	}
+	for _, d := range vb.Descriptors {
+		switch d.Kind {
+		case imgtool.DescHash, imgtool.DescHashtree:
+			fmt.Fprintf(&sb, "  %s %s: %s %s %s\n", d.Kind, d.Partition, formatFileSize(int64(d.ImageSize)), d.Algorithm, d.Digest)
+		case imgtool.DescProperty:
+			fmt.Fprintf(&sb, "  %s %s=%s\n", d.Kind, d.Key, d.Value)
+		case imgtool.DescKernelCmdline:
+			fmt.Fprintf(&sb, "  %s %s\n", d.Kind, d.Key)
+		}
+	}
+	return sb.String()
+}
+
+// showImageTools opens a window to inspect, convert and split local images.
+// maxDownload returns the selected device's max-download-size, or 0.
+func showImageTools(maxDownload func() int64) {
+	win := fyne.CurrentApp().NewWindow(T("imgtools_title"))
+
+	var (
+		im      *imgtool.Image
+		path    string
+		running bool
+		closed  bool
+		openGen int // drops results of an open superseded by a newer one
+	)
+	source := widget.NewLabel(T("imgtools_no_image"))
+	source.Truncation = fyne.TextTruncateEllipsis
+	info := widget.NewMultiLineEntry()
+	info.TextStyle = fyne.TextStyle{Monospace: true}
+	info.Wrapping = fyne.TextWrapOff
+	status := widget.NewLabel("")
+	status.Wrapping = fyne.TextWrapWord
+	maxEntry := widget.NewEntry()
+	maxEntry.SetPlaceHolder("MiB")
+	if n := maxDownload(); n > 0 {
+		maxEntry.SetText(strconv.FormatInt(n>>20, 10))
+	}
+
+	btnRaw := widget.NewButton(T("imgtools_to_raw"), nil)
+	btnSparse := widget.NewButton(T("imgtools_to_sparse"), nil)
+	btnSplit := widget.NewButton(T("imgtools_split"), nil)
+	actions := []*widget.Button{btnRaw, btnSparse, btnSplit}
+	sync := func() {
+		for _, b := range actions {
+			b.Disable()
+		}
+		if im == nil {
+			return
+		}
+		if im.Sparse {
+			btnRaw.Enable()
+		} else {
+			btnSparse.Enable()
+		}
+		btnSplit.Enable()
+	}
+
+	open := func(p string) {
+		if running {
+			return
+		}
+		if im != nil {
+			im.Close()
+			im = nil
+		}
+		sync()
+		openGen++
+		gen := openGen
+		source.SetText(p)
+		info.SetText(T("loading"))
+		status.SetText("")
+		go func() {
+			img, err := imgtool.Open(p, 0)
+			vb, vbErr := imgtool.ReadVBMeta(p)
+			fyne.Do(func() {
+				if closed || gen != openGen {
+					if err == nil {
+						img.Close()
+					}
+					return
+				}
+				text := describeVBMeta(vb, vbErr)
+				if err != nil {
+					info.SetText(err.Error() + "\n\n" + text)
+					return
+				}
+				im, path = img, p
+				info.SetText(describeSparse(img) + "\n" + text)
+				sync()
+			})
+		}()
+	}
+
+	// run executes a conversion in the background with the actions disabled.
+	run := func(fn func() (string, error)) {
+		for _, b := range actions {
+			b.Disable()
+		}
+		status.SetText(T("loading"))
+		running = true
+		go func() {
+			msg, err := fn()
+			fyne.Do(func() {
+				running = false
+				if closed {
+					im.Close()
+					return
+				}
+				sync()
+				if err != nil {
+					status.SetText(T("error") + ": " + err.Error())
+					return
+				}
+				status.SetText(msg)
+			})
+		}()
+	}
+	stem := func() string {
+		return strings.TrimSuffix(path, filepath.Ext(path))
+	}
+	btnRaw.OnTapped = func() {
+		dst := stem() + ".raw.img"
+		run(func() (string, error) {
+			return T("saved_to") + " " + dst, im.WriteRaw(dst, nil)
+		})
+	}
+	btnSparse.OnTapped = func() {
+		dst := stem() + ".simg"
+		run(func() (string, error) {
+			return T("saved_to") + " " + dst, im.WriteSparse(dst)
+		})
+	}
+	btnSplit.OnTapped = func() {
+		mib, err := strconv.ParseInt(strings.TrimSpace(maxEntry.Text), 10, 64)
+		if err != nil || mib <= 0 {
+			dialog.ShowInformation(T("imgtools_split"), T("imgtools_bad_size"), win)
+			return
+		}
+		dir := filepath.Dir(path)
+		run(func() (string, error) {
+			pieces, err := im.Split(dir, filepath.Base(path), mib<<20)
+			return fmt.Sprintf(T("imgtools_split_done"), len(pieces), dir), err
+		})
+	}
+
+	btnOpen := widget.NewButton(T("imgtools_open"), func() {
+		dialog.ShowFileOpen(func(rc fyne.URIReadCloser, err error) {
+			if err != nil || rc == nil {
+				return
+			}
+			p := rc.URI().Path()
+			rc.Close()
+			open(p)
+		}, win)
+	})
+	sync()
+
+	win.SetOnClosed(func() {
+		closed = true
+		if im != nil && !running {
+			im.Close()
+		}
+	})
+	top := container.NewBorder(nil, nil, btnOpen, nil, source)
+	bottom := container.NewVBox(
+		container.NewHBox(btnRaw, btnSparse, widget.NewSeparator(),
+			widget.NewLabel(T("imgtools_max_size")), container.NewGridWrap(fyne.NewSize(100, maxEntry.MinSize().Height), maxEntry), btnSplit),
+		status,
+	)
+	win.SetContent(container.NewBorder(top, bottom, nil, nil, info))
+	win.Resize(fyne.NewSize(800, 600))
+	win.Show()
+}
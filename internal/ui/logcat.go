@@ -0,0 +1,357 @@
+package ui
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"adb-gui/internal/adb"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// maxLogEntries bounds the in-memory ring buffer of log lines.
+const maxLogEntries = 50000
+
+// logFilter selects which entries are shown.
+type logFilter struct {
+	minLevel int
+	tag      string // case-insensitive substring
+	pids     map[int]bool
+	re       *regexp.Regexp
+}
+
+func (f logFilter) match(e *adb.LogEntry) bool {
+	if lvl := e.LevelIndex(); lvl >= 0 && lvl < f.minLevel {
+		return false
+	}
+	if f.tag != "" && !strings.Contains(strings.ToLower(e.Tag), f.tag) {
+		return false
+	}
+	if f.pids != nil && !f.pids[e.PID] {
+		return false
+	}
+	if f.re != nil && !f.re.MatchString(e.Tag+": "+e.Message) {
+		return false
+	}
+	return true
+}
+
+func logImportance(level byte) widget.Importance {
+	switch level {
+	case 'V':
+		return widget.LowImportance
+	case 'I':
+		return widget.SuccessImportance
+	case 'W':
+		return widget.WarningImportance
+	case 'E', 'F':
+		return widget.DangerImportance
+	}
+	return widget.MediumImportance
+}
+
+func buildLogcatTab(w fyne.Window, mgr *adb.Manager, selectedSerialBind binding.String) fyne.CanvasObject {
+	var (
+		all     []*adb.LogEntry // ring buffer, oldest first
+		view    []*adb.LogEntry // entries passing the filter
+		filter  logFilter
+		pendMu  sync.Mutex
+		pending []*adb.LogEntry // received but not yet merged on the UI thread
+		cancel  context.CancelFunc
+		gen     int
+	)
+
+	list := widget.NewList(
+		func() int { return len(view) },
+		func() fyne.CanvasObject {
+			l := widget.NewLabel("")
+			l.TextStyle = fyne.TextStyle{Monospace: true}
+			l.Truncation = fyne.TextTruncateEllipsis
+			return l
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			if i >= len(view) {
+				return
+			}
+			l := o.(*widget.Label)
+			e := view[i]
+			l.Importance = logImportance(e.Level)
+			if e.Level == 0 {
+				l.Text = e.Message
+			} else {
+				l.Text = fmt.Sprintf("%s %5d %5d %c %s: %s", e.Time, e.PID, e.TID, e.Level, e.Tag, e.Message)
+			}
+			l.Refresh()
+		},
+	)
+	status := widget.NewLabel("")
+
+	bufferSelect := widget.NewSelect(append([]string{T("logcat_default_buffers"), "all"}, adb.LogBuffers...), nil)
+	bufferSelect.SetSelected(T("logcat_default_buffers"))
+	// The index of the selected level is the minimum logFilter level
+	levelSelect := widget.NewSelect([]string{T("logcat_level_verbose"), T("logcat_level_debug"), T("logcat_level_info"),
+		T("logcat_level_warn"), T("logcat_level_error"), T("logcat_level_fatal")}, nil)
+	levelSelect.SetSelectedIndex(0)
+	tagEntry := widget.NewEntry()
+	tagEntry.SetPlaceHolder(T("logcat_tag"))
+	pkgEntry := widget.NewEntry()
+	pkgEntry.SetPlaceHolder(T("package"))
+	regexEntry := widget.NewEntry()
+	regexEntry.SetPlaceHolder(T("search_regex"))
+	pauseCheck := widget.NewCheck(T("logcat_pause"), nil)
+	autoScroll := widget.NewCheck(T("logcat_auto_scroll"), nil)
+	autoScroll.SetChecked(true)
+
+	rebuildView := func() {
+		view = view[:0]
+		for _, e := range all {
+			if filter.match(e) {
+				view = append(view, e)
+			}
+		}
+		list.Refresh()
+		if autoScroll.Checked {
+			list.ScrollToBottom()
+		}
+		status.SetText(fmt.Sprintf(T("logcat_status"), len(view), len(all)))
+	}
+
+	// applyFilter rebuilds the filter from the inputs; package names are resolved to PIDs on the device
+	var pkgResolved string
+	applyFilter := func() {
+		f := logFilter{minLevel: max(0, levelSelect.SelectedIndex()), tag: strings.ToLower(strings.TrimSpace(tagEntry.Text))}
+		if s := strings.TrimSpace(regexEntry.Text); s != "" {
+			re, err := regexp.Compile("(?i)" + s)
+			if err != nil {
+				status.SetText(fmt.Sprintf("%s: %v", T("search_regex"), err))
+				return
+			}
+			f.re = re
+		}
+		f.pids = filter.pids
+		pkg := strings.TrimSpace(pkgEntry.Text)
+		if pkg == "" {
+			f.pids = nil
+			pkgResolved = ""
+		}
+		filter = f
+		rebuildView()
+		if pkg != "" && pkg != pkgResolved {
+			serial, _ := selectedSerialBind.Get()
+			go func() {
+				pids, err := mgr.PidOf(serial, pkg)
+				fyne.Do(func() {
+					if strings.TrimSpace(pkgEntry.Text) != pkg {
+						return
+					}
+					pkgResolved = pkg
+					filter.pids = map[int]bool{}
+					for _, p := range pids {
+						filter.pids[p] = true
+					}
+					rebuildView()
+					if err != nil || len(pids) == 0 {
+						status.SetText(fmt.Sprintf(T("logcat_pkg_not_running"), pkg))
+					}
+				})
+			}()
+		}
+	}
+	levelSelect.OnChanged = func(string) { applyFilter() }
+	tagEntry.OnChanged = func(string) { applyFilter() }
+	regexEntry.OnChanged = func(string) { applyFilter() }
+	pkgEntry.OnSubmitted = func(string) { pkgResolved = ""; applyFilter() }
+	pauseCheck.OnChanged = func(paused bool) {
+		if !paused {
+			rebuildView()
+		}
+	}
+
+	// merge moves pending entries into the ring buffer and the filtered view
+	merge := func() {
+		pendMu.Lock()
+		batch := pending
+		pending = nil
+		pendMu.Unlock()
+		if len(batch) == 0 {
+			return
+		}
+		all = append(all, batch...)
+		trimmed := false
+		if len(all) > maxLogEntries+maxLogEntries/10 {
+			// Trim in chunks so the copy cost is amortised
+			drop := len(all) - maxLogEntries
+			all = append(all[:0:0], all[drop:]...)
+			trimmed = true
+		}
+		if pauseCheck.Checked {
+			return
+		}
+		if trimmed {
+			rebuildView()
+			return
+		}
+		for _, e := range batch {
+			if filter.match(e) {
+				view = append(view, e)
+			}
+		}
+		list.Refresh()
+		if autoScroll.Checked {
+			list.ScrollToBottom()
+		}
+		status.SetText(fmt.Sprintf(T("logcat_status"), len(view), len(all)))
+	}
+
+	btnStart := widget.NewButton(T("logcat_start"), nil)
+	stop := func() {
+		if cancel != nil {
+			cancel()
+			cancel = nil
+		}
+		gen++
+		btnStart.SetText(T("logcat_start"))
+	}
+	start := func() {
+		serial, _ := selectedSerialBind.Get()
+		if serial == "" {
+			dialog.ShowInformation(T("no_device"), T("please_select_device"), w)
+			return
+		}
+		var buffers []string
+		switch bufferSelect.Selected {
+		case T("logcat_default_buffers"):
+		case "all":
+			buffers = []string{"all"}
+		default:
+			buffers = []string{bufferSelect.Selected}
+		}
+		ctx, c := context.WithCancel(context.Background())
+		cancel = c
+		gen++
+		myGen := gen
+		btnStart.SetText(T("stop"))
+		go func() {
+			t := time.NewTicker(200 * time.Millisecond)
+			defer t.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-t.C:
+					fyne.Do(merge)
+				}
+			}
+		}()
+		go func() {
+			err := mgr.Logcat(ctx, serial, buffers, func(e adb.LogEntry) {
+				pendMu.Lock()
+				pending = append(pending, &e)
+				pendMu.Unlock()
+			})
+			fyne.Do(func() {
+				merge()
+				if myGen != gen {
+					return
+				}
+				stop()
+				if err != nil {
+					status.SetText(err.Error())
+				}
+			})
+		}()
+	}
+	btnStart.OnTapped = func() {
+		if cancel != nil {
+			stop()
+		} else {
+			start()
+		}
+	}
+	bufferSelect.OnChanged = func(string) {
+		// Restart on the new buffer if running
+		if cancel != nil {
+			stop()
+			start()
+		}
+	}
+
+	btnClear := widget.NewButton(T("clear"), func() {
+		all, view = nil, nil
+		rebuildView()
+	})
+	btnClearDevice := widget.NewButton(T("logcat_clear_device"), func() {
+		serial, _ := selectedSerialBind.Get()
+		if serial == "" {
+			return
+		}
+		go func() {
+			out, err := mgr.ClearLogcat(serial, nil)
+			if err != nil {
+				showCmdResult(T("logcat_clear_device"), out, err, w)
+			}
+		}()
+		all, view = nil, nil
+		rebuildView()
+	})
+	btnExport := widget.NewButton(T("export"), func() {
+		lines := make([]string, len(view))
+		for i, e := range view {
+			lines[i] = e.Raw
+		}
+		fd := dialog.NewFileSave(func(uc fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			if uc == nil {
+				return
+			}
+			bw := bufio.NewWriter(uc)
+			for _, l := range lines {
+				bw.WriteString(l)
+				bw.WriteByte('\n')
+			}
+			werr := bw.Flush()
+			if cerr := uc.Close(); werr == nil {
+				werr = cerr
+			}
+			if werr != nil {
+				dialog.ShowError(werr, w)
+			}
+		}, w)
+		serial, _ := selectedSerialBind.Get()
+		fd.SetFileName(mediaFileName(serial, time.Now(), ".log"))
+		fd.Show()
+	})
+
+	// A different device means a different log; stop the old stream
+	selectedSerialBind.AddListener(binding.NewDataListener(func() {
+		if cancel != nil {
+			stop()
+		}
+		pkgResolved = ""
+	}))
+
+	controls := container.NewHBox(btnStart, bufferSelect, levelSelect, pauseCheck, autoScroll, btnClear, btnClearDevice, btnExport)
+	filters := container.NewGridWithColumns(3, tagEntry, pkgEntry, regexEntry)
+	return container.NewBorder(container.NewVBox(controls, filters), status, nil, nil, list)
+}
+
+func indexOf(list []string, s string) int {
+	for i, v := range list {
+		if v == s {
+			return i
+		}
+	}
+	return -1
+}
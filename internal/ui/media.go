@@ -0,0 +1,314 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"adb-gui/internal/adb"
+	"adb-gui/internal/config"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// mediaFileName builds "<serial>_<timestamp><ext>" with characters unsafe in
+// file names (e.g. the ':' of network serials) replaced.
+func mediaFileName(serial string, t time.Time, ext string) string {
+	return unsafeFileChars.ReplaceAllString(serial, "_") + "_" + t.Format("20060102-150405") + ext
+}
+
+// mediaNameStamp matches the serial and timestamp at the start of a media file name.
+var mediaNameStamp = regexp.MustCompile(`^(.+)_\d{8}-\d{6}`)
+
+// mediaFileSerial returns the serial of a name built by mediaFileName. The
+// timestamp is located first because sanitized serials such as
+// "192.168.1.5_5555" contain underscores themselves. A connected device whose
+// sanitized serial matches is reported with its real serial.
+func mediaFileSerial(name string, devices []adb.Device) string {
+	m := mediaNameStamp.FindStringSubmatch(name)
+	if m == nil {
+		return strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	for _, d := range devices {
+		if unsafeFileChars.ReplaceAllString(d.Serial, "_") == m[1] {
+			return d.Serial
+		}
+	}
+	return m[1]
+}
+
+// saveMediaFile writes data into the configured media folder and returns the file path.
+func saveMediaFile(cfg *config.Config, name string, data []byte) (string, error) {
+	dir := cfg.MediaOutputDir()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	p := filepath.Join(dir, name)
+	return p, os.WriteFile(p, data, 0o644)
+}
+
+// showScreenshotViewer opens a window with the screenshot and annotation tools.
+func showScreenshotViewer(cfg *config.Config, serial string, data []byte, taken time.Time) {
+	img, err := png.Decode(bytes.NewReader(data))
+	win := fyne.CurrentApp().NewWindow(T("screenshot") + " - " + serial)
+	if err != nil {
+		dialog.ShowError(err, win)
+		win.Show()
+		return
+	}
+	ann := newAnnotator(img)
+	ann.OnTextRequest = func(at image.Point) {
+		entry := widget.NewEntry()
+		dialog.ShowForm(T("annotate_text"), T("ok"), T("cancel"), []*widget.FormItem{
+			widget.NewFormItem(T("text"), entry),
+		}, func(ok bool) {
+			if ok {
+				ann.AddText(at, strings.TrimSpace(entry.Text))
+			}
+		}, win)
+		win.Canvas().Focus(entry)
+	}
+
+	tools := map[string]string{
+		T("annotate_arrow"): toolArrow,
+		T("annotate_box"):   toolBox,
+		T("annotate_text"):  toolText,
+		T("annotate_crop"):  toolCrop,
+	}
+	toolRadio := widget.NewRadioGroup([]string{T("annotate_arrow"), T("annotate_box"), T("annotate_text"), T("annotate_crop")}, func(s string) {
+		if t, ok := tools[s]; ok {
+			ann.Tool = t
+		}
+	})
+	toolRadio.Horizontal = true
+	toolRadio.Required = true
+	toolRadio.SetSelected(T("annotate_arrow"))
+
+	encode := func() ([]byte, error) {
+		var buf bytes.Buffer
+		err := png.Encode(&buf, ann.Render())
+		return buf.Bytes(), err
+	}
+	btnUndo := widget.NewButton(T("undo"), ann.Undo)
+	btnSave := widget.NewButton(T("save"), func() {
+		out, err := encode()
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		p, err := saveMediaFile(cfg, mediaFileName(serial, taken, ".png"), out)
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		dialog.ShowInformation(T("screenshot"), T("saved_to")+"\n"+p, win)
+	})
+	btnSaveAs := widget.NewButton(T("save_as"), func() {
+		out, err := encode()
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		fd := dialog.NewFileSave(func(uc fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			if uc == nil {
+				return
+			}
+			_, werr := uc.Write(out)
+			if cerr := uc.Close(); werr == nil {
+				werr = cerr
+			}
+			if werr != nil {
+				dialog.ShowError(werr, win)
+			}
+		}, win)
+		fd.SetFileName(mediaFileName(serial, taken, ".png"))
+		fd.Show()
+	})
+	btnCopy := widget.NewButton(T("copy_to_clipboard"), func() {
+		out, err := encode()
+		if err == nil {
+			err = copyImageToClipboard(out)
+		}
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("%s: %v", T("copy_failed"), err), win)
+		}
+	})
+
+	b := img.Bounds()
+	info := widget.NewLabel(fmt.Sprintf("%d×%d  %s", b.Dx(), b.Dy(), taken.Format("2006-01-02 15:04:05")))
+	toolbar := container.NewHBox(toolRadio, btnUndo, widget.NewSeparator(), btnSave, btnSaveAs, btnCopy)
+	win.SetContent(container.NewBorder(toolbar, info, nil, nil, ann))
+	win.Resize(fyne.NewSize(720, 860))
+	win.Show()
+}
+
+// mediaFiles lists files with the given extension in dir, newest first.
+func mediaFiles(dir, ext string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	type item struct {
+		name string
+		mod  time.Time
+	}
+	var items []item
+	for _, e := range entries {
+		if e.IsDir() || !strings.EqualFold(filepath.Ext(e.Name()), ext) {
+			continue
+		}
+		if info, err := e.Info(); err == nil {
+			items = append(items, item{e.Name(), info.ModTime()})
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].mod.After(items[j].mod) })
+	res := make([]string, len(items))
+	for i, it := range items {
+		res[i] = filepath.Join(dir, it.name)
+	}
+	return res
+}
+
+func buildMediaTab(w fyne.Window, mgr *adb.Manager, selectedSerialBind binding.String, devices *[]adb.Device, cfg *config.Config) fyne.CanvasObject {
+	// Gallery of saved captures in the media folder
+	var gallery []string
+	galleryList := widget.NewList(
+		func() int { return len(gallery) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			o.(*widget.Label).SetText(filepath.Base(gallery[i]))
+		},
+	)
+	folderLabel := widget.NewLabel("")
+	refreshGallery := func() {
+		folderLabel.SetText(cfg.MediaOutputDir())
+		gallery = mediaFiles(cfg.MediaOutputDir(), ".png")
+		galleryList.UnselectAll()
+		galleryList.Refresh()
+	}
+	galleryList.OnSelected = func(id widget.ListItemID) {
+		if id < 0 || id >= len(gallery) {
+			return
+		}
+		p := gallery[id]
+		galleryList.UnselectAll()
+		data, err := os.ReadFile(p)
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		taken := time.Now()
+		if st, err := os.Stat(p); err == nil {
+			taken = st.ModTime()
+		}
+		serial := mediaFileSerial(filepath.Base(p), *devices)
+		showScreenshotViewer(cfg, serial, data, taken)
+	}
+
+	btnScreenshot := widget.NewButton(T("screenshot"), func() {
+		serial, _ := selectedSerialBind.Get()
+		if serial == "" {
+			dialog.ShowInformation(T("no_device"), T("please_select_device"), w)
+			return
+		}
+		prog := dialog.NewCustomWithoutButtons(T("screenshot"), widget.NewProgressBarInfinite(), w)
+		prog.Show()
+		go func() {
+			data, err := mgr.Screenshot(serial)
+			taken := time.Now()
+			fyne.Do(func() {
+				prog.Hide()
+				if err != nil {
+					dialog.ShowError(fmt.Errorf("%s: %v", T("screenshot_failed"), err), w)
+					return
+				}
+				showScreenshotViewer(cfg, serial, data, taken)
+			})
+		}()
+	})
+
+	btnCaptureAll := widget.NewButton(T("capture_all_devices"), func() {
+		var serials []string
+		for _, d := range *devices {
+			if d.State == "device" {
+				serials = append(serials, d.Serial)
+			}
+		}
+		if len(serials) == 0 {
+			dialog.ShowInformation(T("no_device"), T("please_select_device"), w)
+			return
+		}
+		prog := dialog.NewCustomWithoutButtons(T("capture_all_devices"), widget.NewProgressBarInfinite(), w)
+		prog.Show()
+		go func() {
+			// Capture in parallel so the shots are as close in time as possible
+			taken := time.Now()
+			results := make([]string, len(serials))
+			var wg sync.WaitGroup
+			for i, s := range serials {
+				wg.Add(1)
+				go func(i int, serial string) {
+					defer wg.Done()
+					data, err := mgr.Screenshot(serial)
+					if err == nil {
+						var p string
+						p, err = saveMediaFile(cfg, mediaFileName(serial, taken, ".png"), data)
+						if err == nil {
+							results[i] = fmt.Sprintf("✓ %s → %s", serial, p)
+							return
+						}
+					}
+					results[i] = fmt.Sprintf("✗ %s: %v", serial, err)
+				}(i, s)
+			}
+			wg.Wait()
+			fyne.Do(func() {
+				prog.Hide()
+				refreshGallery()
+				showCmdResult(T("capture_all_devices"), strings.Join(results, "\n"), nil, w)
+			})
+		}()
+	})
+
+	btnMirror := widget.NewButton(T("mirror_screen"), func() {
+		serial, _ := selectedSerialBind.Get()
+		if serial == "" {
+			dialog.ShowInformation(T("no_device"), T("please_select_device"), w)
+			return
+		}
+		showMirrorWindow(mgr, serial)
+	})
+
+	btnRefresh := widget.NewButton(T("refresh"), refreshGallery)
+	refreshGallery()
+
+	recordSection := buildRecordSection(w, mgr, selectedSerialBind, devices, cfg)
+
+	top := container.NewVBox(
+		widget.NewLabelWithStyle(T("screenshot"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		container.NewHBox(btnScreenshot, btnCaptureAll, btnMirror),
+		widget.NewSeparator(),
+		recordSection,
+		widget.NewSeparator(),
+		container.NewBorder(nil, nil, widget.NewLabelWithStyle(T("gallery"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}), btnRefresh, folderLabel),
+	)
+	return container.NewBorder(top, nil, nil, nil, galleryList)
+}
@@ -0,0 +1,28 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"adb-gui/internal/adb"
+)
+
+func TestMediaFileSerial(t *testing.T) {
+	taken := time.Date(2024, 5, 6, 7, 8, 9, 0, time.Local)
+	devices := []adb.Device{{Serial: "192.168.1.5:5555"}}
+	tests := []struct {
+		serial string
+		want   string
+	}{
+		{"emulator-5554", "emulator-5554"},
+		{"R58M_123", "R58M_123"},
+		{"192.168.1.5:5555", "192.168.1.5:5555"}, // connected: real serial
+		{"10.0.0.2:5555", "10.0.0.2_5555"},       // not connected: sanitized
+	}
+	for _, tt := range tests {
+		name := mediaFileName(tt.serial, taken, ".png")
+		if got := mediaFileSerial(name, devices); got != tt.want {
+			t.Errorf("mediaFileSerial(%q) = %q, want %q", name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package ui
+
+import (
+	"fmt"
+	"image"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"adb-gui/internal/adb"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// mirrorView shows device frames and turns mouse and keyboard input into
+// callbacks in device pixel coordinates.
+type mirrorView struct {
+	widget.BaseWidget
+
+	OnTap   func(at image.Point)
+	OnSwipe func(from, to image.Point, d time.Duration)
+	OnRune  func(r rune)
+	OnKey   func(k *fyne.KeyEvent)
+
+	img       *canvas.Image
+	frame     image.Rectangle
+	dragging  bool
+	dragFrom  image.Point
+	dragTo    image.Point
+	dragStart time.Time
+}
+
+func newMirrorView() *mirrorView {
+	v := &mirrorView{img: canvas.NewImageFromImage(nil)}
+	v.img.FillMode = canvas.ImageFillContain
+	v.img.ScaleMode = canvas.ImageScaleFastest
+	v.img.SetMinSize(fyne.NewSize(270, 480))
+	v.ExtendBaseWidget(v)
+	return v
+}
+
+func (v *mirrorView) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(v.img)
+}
+
+// SetFrame replaces the displayed frame; must be called on the UI thread.
+func (v *mirrorView) SetFrame(img image.Image) {
+	v.frame = img.Bounds()
+	v.img.Image = img
+	v.img.Refresh()
+}
+
+func (v *mirrorView) toDevice(pos fyne.Position) image.Point {
+	return containedImagePoint(pos, v.Size(), v.frame)
+}
+
+func (v *mirrorView) Tapped(e *fyne.PointEvent) {
+	if c := fyne.CurrentApp().Driver().CanvasForObject(v); c != nil {
+		c.Focus(v)
+	}
+	if v.OnTap != nil && !v.frame.Empty() {
+		v.OnTap(v.toDevice(e.Position))
+	}
+}
+
+func (v *mirrorView) Dragged(e *fyne.DragEvent) {
+	if v.frame.Empty() {
+		return
+	}
+	if !v.dragging {
+		v.dragging = true
+		v.dragStart = time.Now()
+		v.dragFrom = v.toDevice(e.Position.Subtract(e.Dragged))
+	}
+	v.dragTo = v.toDevice(e.Position)
+}
+
+func (v *mirrorView) DragEnd() {
+	if !v.dragging {
+		return
+	}
+	v.dragging = false
+	if v.OnSwipe != nil {
+		d := min(max(time.Since(v.dragStart), 100*time.Millisecond), 2*time.Second)
+		v.OnSwipe(v.dragFrom, v.dragTo, d)
+	}
+}
+
+func (v *mirrorView) FocusGained() {}
+func (v *mirrorView) FocusLost()   {}
+
+func (v *mirrorView) TypedRune(r rune) {
+	if v.OnRune != nil {
+		v.OnRune(r)
+	}
+}
+
+func (v *mirrorView) TypedKey(e *fyne.KeyEvent) {
+	if v.OnKey != nil {
+		v.OnKey(e)
+	}
+}
+
+// mirrorKeys maps host keys to Android key codes.
+var mirrorKeys = map[fyne.KeyName]int{
+	fyne.KeyReturn:    adb.KeyEnter,
+	fyne.KeyEnter:     adb.KeyEnter,
+	fyne.KeyBackspace: adb.KeyDel,
+	fyne.KeyDelete:    adb.KeyForwardDel,
+	fyne.KeyTab:       adb.KeyTab,
+	fyne.KeyEscape:    adb.KeyBack,
+	fyne.KeyUp:        adb.KeyDpadUp,
+	fyne.KeyDown:      adb.KeyDpadDown,
+	fyne.KeyLeft:      adb.KeyDpadLeft,
+	fyne.KeyRight:     adb.KeyDpadRight,
+}
+
+// showMirrorWindow opens a live view of the device screen that forwards taps,
+// drags and typing. Frames come from a screencap loop: raw pixels over USB,
+// PNG over wireless connections where bandwidth matters more than device CPU.
+func showMirrorWindow(mgr *adb.Manager, serial string) {
+	win := fyne.CurrentApp().NewWindow(T("mirror_screen") + " - " + serial)
+	view := newMirrorView()
+	status := widget.NewLabel(T("loading"))
+
+	modeAuto, modeRaw, modePNG := T("mirror_mode_auto"), T("mirror_mode_raw"), T("mirror_mode_png")
+	var raw atomic.Bool
+	modeSelect := widget.NewSelect([]string{modeAuto, modeRaw, modePNG}, func(s string) {
+		switch s {
+		case modeRaw:
+			raw.Store(true)
+		case modePNG:
+			raw.Store(false)
+		default:
+			raw.Store(!adb.IsWireless(serial))
+		}
+	})
+	modeSelect.SetSelected(modeAuto)
+
+	// Input is sent in order by one worker; a full queue drops events rather than lagging
+	var stopped atomic.Bool
+	inputs := make(chan func(), 32)
+	go func() {
+		for f := range inputs {
+			if !stopped.Load() {
+				f()
+			}
+		}
+	}()
+	send := func(f func()) bool {
+		if stopped.Load() {
+			return false
+		}
+		select {
+		case inputs <- f:
+			return true
+		default:
+			return false
+		}
+	}
+	report := func(out string, err error) {
+		if err != nil {
+			fyne.Do(func() { status.SetText(fmt.Sprintf("%v %s", err, firstLine(out))) })
+		}
+	}
+
+	var textMu sync.Mutex
+	var pending string
+	view.OnTap = func(at image.Point) {
+		send(func() { report(mgr.InputTap(serial, at.X, at.Y)) })
+	}
+	view.OnSwipe = func(from, to image.Point, d time.Duration) {
+		send(func() { report(mgr.InputSwipe(serial, from.X, from.Y, to.X, to.Y, d)) })
+	}
+	view.OnRune = func(r rune) {
+		// Batch quickly typed characters into one "input text" call
+		textMu.Lock()
+		first := pending == ""
+		pending += string(r)
+		textMu.Unlock()
+		if !first {
+			return
+		}
+		queued := send(func() {
+			time.Sleep(80 * time.Millisecond)
+			textMu.Lock()
+			t := pending
+			pending = ""
+			textMu.Unlock()
+			report(mgr.InputText(serial, t))
+		})
+		if !queued {
+			// Drop the batch like any other event, so the next rune starts a new one
+			textMu.Lock()
+			pending = ""
+			textMu.Unlock()
+		}
+	}
+	view.OnKey = func(e *fyne.KeyEvent) {
+		if code, ok := mirrorKeys[e.Name]; ok {
+			send(func() { report(mgr.InputKeyEvent(serial, code)) })
+		}
+	}
+
+	keyButton := func(label string, code int) *widget.Button {
+		return widget.NewButton(label, func() {
+			send(func() { report(mgr.InputKeyEvent(serial, code)) })
+		})
+	}
+	nav := container.NewGridWithColumns(6,
+		keyButton(T("key_back"), adb.KeyBack),
+		keyButton(T("key_home"), adb.KeyHome),
+		keyButton(T("key_recents"), adb.KeyAppSwitch),
+		keyButton(T("key_volume_down"), adb.KeyVolumeDown),
+		keyButton(T("key_volume_up"), adb.KeyVolumeUp),
+		keyButton(T("key_power"), adb.KeyPower),
+	)
+
+	top := container.NewBorder(nil, nil, widget.NewLabel(T("mirror_mode")), nil, modeSelect)
+	win.SetContent(container.NewBorder(top, container.NewVBox(nav, status), nil, nil, view))
+	win.Resize(fyne.NewSize(420, 860))
+	win.SetOnClosed(func() {
+		stopped.Store(true)
+		close(inputs)
+	})
+	win.Show()
+	win.Canvas().Focus(view)
+
+	go func() {
+		var fps float64
+		for !stopped.Load() {
+			start := time.Now()
+			img, err := mgr.ScreenFrame(serial, raw.Load())
+			if stopped.Load() {
+				return
+			}
+			if err != nil {
+				fyne.Do(func() { status.SetText(fmt.Sprintf("%s: %v", T("mirror_frame_failed"), err)) })
+				time.Sleep(time.Second)
+				continue
+			}
+			if d := time.Since(start).Seconds(); d > 0 {
+				// Smoothed frame rate
+				if fps == 0 {
+					fps = 1 / d
+				} else {
+					fps = fps*0.8 + 0.2/d
+				}
+			}
+			b := img.Bounds()
+			text := fmt.Sprintf("%d×%d  %.1f fps", b.Dx(), b.Dy(), fps)
+			fyne.DoAndWait(func() {
+				if stopped.Load() {
+					return
+				}
+				view.SetFrame(img)
+				status.SetText(text)
+			})
+		}
+	}()
+}
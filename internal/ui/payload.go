@@ -0,0 +1,243 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"adb-gui/internal/adb"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+)
+
+// showPayloadExtractor opens a window that extracts partition images from a
+// local payload.bin or OTA zip. onExtracted receives partition -> image path
+// for every image written successfully.
+func showPayloadExtractor(onExtracted func(map[string]string)) {
+	win := fyne.CurrentApp().NewWindow(T("payload_title"))
+
+	var (
+		payload *adb.Payload
+		parts   []adb.PayloadPartition
+		checked = map[string]bool{}
+		outDir  string
+		cancel  context.CancelFunc
+		running bool
+		closed  bool
+	)
+
+	source := widget.NewLabel(T("payload_no_source"))
+	source.Truncation = fyne.TextTruncateEllipsis
+	outLabel := widget.NewLabel("")
+	outLabel.Truncation = fyne.TextTruncateEllipsis
+	info := widget.NewLabel("")
+	progress := widget.NewProgressBar()
+	status := widget.NewLabel("")
+	status.Wrapping = fyne.TextWrapWord
+
+	list := widget.NewList(
+		func() int { return len(parts) },
+		func() fyne.CanvasObject {
+			size := widget.NewLabel("")
+			return container.NewBorder(nil, nil, widget.NewCheck("", nil), size)
+		},
+		func(id widget.ListItemID, o fyne.CanvasObject) {
+			c := o.(*fyne.Container)
+			check, size := c.Objects[0].(*widget.Check), c.Objects[1].(*widget.Label)
+			pp := parts[id]
+			label := pp.Name
+			if !pp.Full() {
+				label += " (" + T("payload_incremental") + ")"
+			}
+			check.Text = label
+			check.OnChanged = nil
+			check.SetChecked(checked[pp.Name])
+			check.OnChanged = func(b bool) { checked[pp.Name] = b }
+			if pp.Full() {
+				check.Enable()
+			} else {
+				check.Disable()
+			}
+			size.SetText(formatFileSize(int64(pp.Size)))
+		},
+	)
+
+	setOutDir := func(d string) {
+		outDir = d
+		outLabel.SetText(T("payload_output") + ": " + d)
+	}
+
+	open := func(p string) {
+		if payload != nil {
+			payload.Close()
+			payload = nil
+		}
+		parts = nil
+		checked = map[string]bool{}
+		pl, err := adb.OpenPayload(p)
+		if err != nil {
+			source.SetText(T("payload_no_source"))
+			info.SetText("")
+			list.Refresh()
+			dialog.ShowError(err, win)
+			return
+		}
+		payload = pl
+		parts = pl.Partitions
+		for _, name := range adb.PayloadDefaultImages {
+			if pp := pl.Partition(name); pp != nil && pp.Full() {
+				checked[name] = true
+			}
+		}
+		source.SetText(p)
+		kind := T("payload_full")
+		if pl.MinorVersion != 0 {
+			kind = T("payload_incremental")
+		}
+		info.SetText(fmt.Sprintf(T("payload_info"), len(parts), pl.BlockSize, kind))
+		setOutDir(filepath.Join(filepath.Dir(p), strings.TrimSuffix(filepath.Base(p), filepath.Ext(p))+"_images"))
+		list.Refresh()
+	}
+
+	btnOpen := widget.NewButton(T("payload_open"), func() {
+		fd := dialog.NewFileOpen(func(rc fyne.URIReadCloser, err error) {
+			if err != nil || rc == nil {
+				return
+			}
+			p := rc.URI().Path()
+			rc.Close()
+			open(p)
+		}, win)
+		fd.SetFilter(storage.NewExtensionFileFilter([]string{".bin", ".zip"}))
+		fd.Show()
+	})
+	btnOut := widget.NewButton(T("payload_choose_output"), func() {
+		dialog.ShowFolderOpen(func(lu fyne.ListableURI, err error) {
+			if err != nil || lu == nil {
+				return
+			}
+			setOutDir(lu.Path())
+		}, win)
+	})
+	setAll := func(b bool) {
+		for _, pp := range parts {
+			checked[pp.Name] = b && pp.Full()
+		}
+		list.Refresh()
+	}
+	btnAll := widget.NewButton(T("all"), func() { setAll(true) })
+	btnNone := widget.NewButton(T("clear"), func() { setAll(false) })
+
+	btnStop := widget.NewButton(T("stop"), func() {
+		if cancel != nil {
+			cancel()
+		}
+	})
+	btnStop.Disable()
+	btnExtract := widget.NewButton(T("payload_extract"), nil)
+	btnExtract.Importance = widget.HighImportance
+	btnExtract.OnTapped = func() {
+		if payload == nil {
+			return
+		}
+		var names []string
+		for _, pp := range parts {
+			if checked[pp.Name] {
+				names = append(names, pp.Name)
+			}
+		}
+		if len(names) == 0 {
+			return
+		}
+		if err := os.MkdirAll(outDir, 0o755); err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+		running = true
+		btnExtract.Disable()
+		btnOpen.Disable()
+		btnStop.Enable()
+		pl, dir := payload, outDir
+		go func() {
+			done := map[string]string{}
+			var failed []string
+			for i, name := range names {
+				i, name := i, name
+				if ctx.Err() != nil {
+					break
+				}
+				fyne.Do(func() { status.SetText(fmt.Sprintf(T("payload_extracting"), name, i+1, len(names))) })
+				dst := filepath.Join(dir, adb.PayloadImageName(name))
+				err := pl.Extract(ctx, name, dst, func(n, total int64) {
+					fyne.Do(func() {
+						if total > 0 {
+							progress.SetValue(float64(n) / float64(total))
+						}
+					})
+				})
+				if err != nil && ctx.Err() != nil {
+					break
+				}
+				if err != nil {
+					failed = append(failed, err.Error())
+					continue
+				}
+				done[name] = dst
+			}
+			// checked before cancel below, which always sets ctx.Err
+			stopped := ctx.Err() != nil
+			fyne.Do(func() {
+				cancel()
+				running = false
+				if closed {
+					pl.Close()
+					return
+				}
+				btnExtract.Enable()
+				btnOpen.Enable()
+				btnStop.Disable()
+				progress.SetValue(0)
+				msg := fmt.Sprintf(T("payload_done"), len(done), dir)
+				if stopped {
+					msg = T("transfer_cancelled") + "\n" + msg
+				}
+				if len(failed) > 0 {
+					msg += "\n\n" + strings.Join(failed, "\n")
+				}
+				status.SetText(msg)
+				if len(done) > 0 && onExtracted != nil {
+					onExtracted(done)
+				}
+			})
+		}()
+	}
+
+	win.SetCloseIntercept(func() {
+		closed = true
+		if running {
+			cancel()
+		} else if payload != nil {
+			payload.Close()
+		}
+		win.Close()
+	})
+
+	top := container.NewVBox(
+		container.NewBorder(nil, nil, btnOpen, nil, source),
+		info,
+		container.NewBorder(nil, nil, btnOut, nil, outLabel),
+		container.NewHBox(btnAll, btnNone),
+	)
+	bottom := container.NewVBox(progress, status, container.NewHBox(btnExtract, btnStop))
+	win.SetContent(container.NewBorder(top, bottom, nil, nil, list))
+	win.Resize(fyne.NewSize(700, 600))
+	win.Show()
+}
@@ -0,0 +1,203 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"adb-gui/internal/adb"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Preview size limits: text is loaded whole (so it can be edited), images are
+// pulled into memory, anything else only shows the first bytes as hex.
+const (
+	previewTextLimit  = 1 << 20
+	previewImageLimit = 20 << 20
+	previewHexLimit   = 4 << 10
+)
+
+var previewTextExts = map[string]bool{
+	".txt": true, ".log": true, ".conf": true, ".cfg": true, ".ini": true, ".prop": true,
+	".xml": true, ".json": true, ".yaml": true, ".yml": true, ".sh": true, ".rc": true,
+	".md": true, ".csv": true, ".properties": true, ".html": true, ".js": true, ".css": true,
+}
+
+var previewImageExts = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true,
+}
+
+// looksLikeText reports whether b is valid UTF-8 without NUL bytes.
+func looksLikeText(b []byte) bool {
+	return utf8.Valid(b) && !bytes.Contains(b, []byte{0})
+}
+
+// buildPreviewPane creates the Storage tab preview pane. The returned function
+// loads a remote file (or clears the pane when remotePath is empty).
+func buildPreviewPane(w fyne.Window, mgr *adb.Manager) (fyne.CanvasObject, func(serial, remotePath string)) {
+	var curSerial, curPath string
+	var curStamp adb.FileStamp
+	loadGen := 0
+
+	header := widget.NewLabel(T("preview_none"))
+	header.Truncation = fyne.TextTruncateEllipsis
+	rootCheck := widget.NewCheck(T("use_root"), nil)
+
+	textEntry := widget.NewMultiLineEntry()
+	textEntry.TextStyle = fyne.TextStyle{Monospace: true}
+	textEntry.Wrapping = fyne.TextWrapOff
+	hexLabel := widget.NewLabel("")
+	hexLabel.TextStyle = fyne.TextStyle{Monospace: true}
+	hexScroll := container.NewScroll(hexLabel)
+	img := canvas.NewImageFromImage(nil)
+	img.FillMode = canvas.ImageFillContain
+
+	body := container.NewStack()
+	showOnly := func(o fyne.CanvasObject) {
+		body.Objects = []fyne.CanvasObject{o}
+		body.Refresh()
+	}
+
+	var load func(serial, remotePath string)
+	var save func(force bool)
+
+	btnSave := widget.NewButton(T("save"), func() { save(false) })
+	btnSave.Disable()
+	btnReload := widget.NewButton(T("refresh"), func() {
+		if curPath != "" {
+			load(curSerial, curPath)
+		}
+	})
+	rootCheck.OnChanged = func(bool) {
+		if curPath != "" {
+			load(curSerial, curPath)
+		}
+	}
+
+	save = func(force bool) {
+		if curPath == "" {
+			return
+		}
+		serial, p := curSerial, curPath
+		expect := curStamp
+		if force {
+			expect = adb.FileStamp{}
+		}
+		data := []byte(textEntry.Text)
+		asRoot := rootCheck.Checked
+		go func() {
+			stamp, out, err := mgr.WriteFile(serial, p, data, expect, asRoot)
+			fyne.Do(func() {
+				if errors.Is(err, adb.ErrRemoteChanged) {
+					dialog.ShowConfirm(T("save_conflict"), T("save_conflict_message"), func(ok bool) {
+						if ok {
+							save(true)
+						}
+					}, w)
+					return
+				}
+				if err != nil {
+					dialog.ShowError(fmt.Errorf("%s: %v\n%s", T("save_failed"), err, out), w)
+					return
+				}
+				if p == curPath {
+					curStamp = stamp
+				}
+				dialog.ShowInformation(T("save"), T("save_complete"), w)
+			})
+		}()
+	}
+
+	load = func(serial, remotePath string) {
+		loadGen++
+		gen := loadGen
+		curSerial, curPath = serial, remotePath
+		curStamp = adb.FileStamp{}
+		btnSave.Disable()
+		if remotePath == "" {
+			header.SetText(T("preview_none"))
+			showOnly(widget.NewLabel(""))
+			return
+		}
+		header.SetText(remotePath + "  …")
+		asRoot := rootCheck.Checked
+		go func() {
+			size, mtime, err := mgr.StatFile(serial, remotePath, asRoot)
+			if err != nil && !asRoot && strings.Contains(strings.ToLower(err.Error()), "permission denied") && mgr.HasSu(serial) {
+				// Root-owned file: retry through su when available
+				asRoot = true
+				size, mtime, err = mgr.StatFile(serial, remotePath, asRoot)
+			}
+			ext := strings.ToLower(path.Ext(remotePath))
+			var data []byte
+			if err == nil {
+				limit := int64(previewHexLimit)
+				switch {
+				case previewImageExts[ext]:
+					limit = previewImageLimit
+				case previewTextExts[ext] || size <= previewTextLimit:
+					limit = previewTextLimit
+				}
+				data, err = mgr.ReadFile(serial, remotePath, limit, asRoot)
+			}
+			fyne.Do(func() {
+				if gen != loadGen {
+					return
+				}
+				if asRoot != rootCheck.Checked {
+					rootCheck.Checked = asRoot
+					rootCheck.Refresh()
+				}
+				if err != nil {
+					header.SetText(remotePath)
+					showOnly(widget.NewLabel(T("error") + ": " + err.Error()))
+					return
+				}
+				curStamp = adb.FileStamp{Size: size, Mtime: mtime}
+				header.SetText(fmt.Sprintf("%s  (%s)", remotePath, formatFileSize(size)))
+				if previewImageExts[ext] {
+					if im, _, derr := image.Decode(bytes.NewReader(data)); derr == nil {
+						img.Image = im
+						img.Refresh()
+						showOnly(img)
+						return
+					}
+				}
+				if looksLikeText(data) {
+					textEntry.SetText(string(data))
+					showOnly(textEntry)
+					// Only whole files may be saved back
+					if size <= previewTextLimit {
+						btnSave.Enable()
+					} else {
+						header.SetText(header.Text + "  " + T("preview_truncated"))
+					}
+					return
+				}
+				if len(data) > previewHexLimit {
+					data = data[:previewHexLimit]
+				}
+				hexLabel.SetText(hex.Dump(data))
+				hexScroll.ScrollToTop()
+				showOnly(hexScroll)
+			})
+		}()
+	}
+
+	showOnly(widget.NewLabel(""))
+	top := container.NewBorder(nil, nil, nil, container.NewHBox(rootCheck, btnReload, btnSave), header)
+	return container.NewBorder(top, nil, nil, nil, body), load
+}
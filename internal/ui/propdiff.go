@@ -0,0 +1,310 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"adb-gui/internal/adb"
+	"adb-gui/internal/config"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+)
+
+// snapshotDir is where property snapshots are saved.
+func snapshotDir(cfg *config.Config) (string, error) {
+	return cfg.DataDir("snapshots")
+}
+
+// saveSnapshot captures getprop or getvar output of a device to the snapshot folder.
+func saveSnapshot(w fyne.Window, mgr *adb.Manager, cfg *config.Config, serial, kind string) {
+	if serial == "" {
+		dialog.ShowInformation(T("no_device"), T("please_select_device"), w)
+		return
+	}
+	go func() {
+		snap, err := mgr.TakeSnapshot(serial, kind)
+		var p, dir string
+		if err == nil {
+			dir, err = snapshotDir(cfg)
+		}
+		if err == nil {
+			err = os.MkdirAll(dir, 0o755)
+		}
+		if err == nil {
+			p = filepath.Join(dir, mediaFileName(serial, snap.Taken, "_"+kind+".json"))
+			err = snap.Save(p)
+		}
+		fyne.Do(func() {
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			dialog.ShowInformation(T("snapshot_save"), T("saved_to")+"\n"+p, w)
+		})
+	}()
+}
+
+// diffSource is one side of a comparison: a live device or a saved snapshot.
+type diffSource struct {
+	label string
+	load  func() (*adb.Snapshot, error)
+}
+
+// showPropDiff opens a window comparing two snapshots or live devices.
+func showPropDiff(mgr *adb.Manager, devices []adb.Device, cfg *config.Config) {
+	win := fyne.CurrentApp().NewWindow(T("snapshot_compare"))
+
+	var sources []diffSource
+	for _, d := range devices {
+		serial := d.Serial
+		switch d.State {
+		case "device":
+			sources = append(sources, diffSource{serial + " (getprop)", func() (*adb.Snapshot, error) {
+				return mgr.TakeSnapshot(serial, adb.SnapshotProps)
+			}})
+		case "fastboot":
+			sources = append(sources, diffSource{serial + " (getvar)", func() (*adb.Snapshot, error) {
+				return mgr.TakeSnapshot(serial, adb.SnapshotGetVar)
+			}})
+		}
+	}
+	addFile := func(p string) string {
+		label := T("snapshot_file") + ": " + filepath.Base(p)
+		for _, s := range sources {
+			if s.label == label {
+				return label
+			}
+		}
+		sources = append(sources, diffSource{label, func() (*adb.Snapshot, error) { return adb.LoadSnapshot(p) }})
+		return label
+	}
+	dir, _ := snapshotDir(cfg)
+	for _, p := range mediaFiles(dir, ".json") {
+		addFile(p)
+	}
+	labels := func() []string {
+		res := make([]string, len(sources))
+		for i, s := range sources {
+			res[i] = s.label
+		}
+		return res
+	}
+	find := func(label string) *diffSource {
+		for i := range sources {
+			if sources[i].label == label {
+				return &sources[i]
+			}
+		}
+		return nil
+	}
+
+	leftSelect := widget.NewSelect(labels(), nil)
+	rightSelect := widget.NewSelect(labels(), nil)
+	if len(sources) > 0 {
+		leftSelect.SetSelectedIndex(0)
+	}
+	if len(sources) > 1 {
+		rightSelect.SetSelectedIndex(1)
+	}
+	browse := func(sel *widget.Select) func() {
+		return func() {
+			fd := dialog.NewFileOpen(func(rc fyne.URIReadCloser, err error) {
+				if err != nil || rc == nil {
+					return
+				}
+				p := rc.URI().Path()
+				rc.Close()
+				label := addFile(p)
+				leftSelect.Options = labels()
+				rightSelect.Options = labels()
+				sel.SetSelected(label)
+			}, win)
+			fd.SetFilter(storage.NewExtensionFileFilter([]string{".json"}))
+			if lister, err := storage.ListerForURI(storage.NewFileURI(dir)); err == nil {
+				fd.SetLocation(lister)
+			}
+			fd.Show()
+		}
+	}
+
+	// Result table with filters
+	var all, shown []adb.DiffEntry
+	var leftSnap, rightSnap *adb.Snapshot
+	headers := []string{T("snapshot_change"), T("props_key"), T("snapshot_left"), T("snapshot_right")}
+	table := widget.NewTable(
+		func() (int, int) { return len(shown) + 1, len(headers) },
+		func() fyne.CanvasObject {
+			l := widget.NewLabel("")
+			l.Truncation = fyne.TextTruncateEllipsis
+			return l
+		},
+		func(id widget.TableCellID, o fyne.CanvasObject) {
+			l := o.(*widget.Label)
+			l.TextStyle = fyne.TextStyle{Bold: id.Row == 0}
+			l.Importance = widget.MediumImportance
+			if id.Row == 0 {
+				l.Text = headers[id.Col]
+				l.Refresh()
+				return
+			}
+			e := shown[id.Row-1]
+			switch e.Kind {
+			case adb.DiffAdded:
+				l.Importance = widget.SuccessImportance
+			case adb.DiffRemoved:
+				l.Importance = widget.DangerImportance
+			default:
+				l.Importance = widget.WarningImportance
+			}
+			switch id.Col {
+			case 0:
+				l.Text = T("snapshot_" + e.Kind)
+			case 1:
+				l.Text = e.Key
+			case 2:
+				l.Text = strings.ReplaceAll(e.Old, "\n", "⏎")
+			case 3:
+				l.Text = strings.ReplaceAll(e.New, "\n", "⏎")
+			}
+			l.Refresh()
+		},
+	)
+	for i, wd := range []float32{80, 320, 300, 300} {
+		table.SetColumnWidth(i, wd)
+	}
+
+	showAdded := widget.NewCheck(T("snapshot_added"), nil)
+	showRemoved := widget.NewCheck(T("snapshot_removed"), nil)
+	showChanged := widget.NewCheck(T("snapshot_changed"), nil)
+	buildOnly := widget.NewCheck(T("snapshot_build_keys"), nil)
+	search := widget.NewEntry()
+	search.SetPlaceHolder(T("props_search"))
+	summary := widget.NewLabel("")
+
+	applyFilter := func() {
+		q := strings.ToLower(strings.TrimSpace(search.Text))
+		show := map[string]bool{adb.DiffAdded: showAdded.Checked, adb.DiffRemoved: showRemoved.Checked, adb.DiffChanged: showChanged.Checked}
+		shown = shown[:0]
+		counts := map[string]int{}
+		for _, e := range all {
+			if buildOnly.Checked && !adb.IsBuildKey(e.Key) {
+				continue
+			}
+			if q != "" && !strings.Contains(strings.ToLower(e.Key+"\n"+e.Old+"\n"+e.New), q) {
+				continue
+			}
+			counts[e.Kind]++
+			if show[e.Kind] {
+				shown = append(shown, e)
+			}
+		}
+		table.Refresh()
+		if leftSnap != nil {
+			summary.SetText(fmt.Sprintf(T("snapshot_summary"), counts[adb.DiffAdded], counts[adb.DiffRemoved], counts[adb.DiffChanged]))
+		}
+	}
+	for _, c := range []*widget.Check{showAdded, showRemoved, showChanged} {
+		c.SetChecked(true)
+		c.OnChanged = func(bool) { applyFilter() }
+	}
+	buildOnly.OnChanged = func(bool) { applyFilter() }
+	search.OnChanged = func(string) { applyFilter() }
+
+	describe := func(s *adb.Snapshot) string {
+		return fmt.Sprintf("%s %s %s", s.Serial, s.Taken.Format("2006-01-02 15:04"), s.Fingerprint)
+	}
+	leftInfo := widget.NewLabel("")
+	rightInfo := widget.NewLabel("")
+	leftInfo.Truncation = fyne.TextTruncateEllipsis
+	rightInfo.Truncation = fyne.TextTruncateEllipsis
+
+	btnCompare := widget.NewButton(T("snapshot_run_compare"), nil)
+	btnCompare.OnTapped = func() {
+		l, r := find(leftSelect.Selected), find(rightSelect.Selected)
+		if l == nil || r == nil {
+			return
+		}
+		btnCompare.Disable()
+		summary.SetText(T("loading"))
+		go func() {
+			type result struct {
+				snap *adb.Snapshot
+				err  error
+			}
+			lc, rc := make(chan result, 1), make(chan result, 1)
+			go func() { s, err := l.load(); lc <- result{s, err} }()
+			go func() { s, err := r.load(); rc <- result{s, err} }()
+			lr, rr := <-lc, <-rc
+			fyne.Do(func() {
+				btnCompare.Enable()
+				for _, res := range []result{lr, rr} {
+					if res.err != nil {
+						summary.SetText("")
+						dialog.ShowError(res.err, win)
+						return
+					}
+				}
+				if lr.snap.Kind != rr.snap.Kind {
+					dialog.ShowInformation(T("snapshot_compare"), T("snapshot_kind_mismatch"), win)
+				}
+				leftSnap, rightSnap = lr.snap, rr.snap
+				leftInfo.SetText(T("snapshot_left") + ": " + describe(leftSnap))
+				rightInfo.SetText(T("snapshot_right") + ": " + describe(rightSnap))
+				all = adb.DiffValues(leftSnap.Values, rightSnap.Values)
+				applyFilter()
+			})
+		}()
+	}
+
+	btnExport := widget.NewButton(T("export"), func() {
+		if leftSnap == nil {
+			return
+		}
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "--- %s\n+++ %s\n", describe(leftSnap), describe(rightSnap))
+		for _, e := range shown {
+			switch e.Kind {
+			case adb.DiffAdded:
+				fmt.Fprintf(&sb, "+ %s=%s\n", e.Key, e.New)
+			case adb.DiffRemoved:
+				fmt.Fprintf(&sb, "- %s=%s\n", e.Key, e.Old)
+			default:
+				fmt.Fprintf(&sb, "~ %s: %s -> %s\n", e.Key, e.Old, e.New)
+			}
+		}
+		text := sb.String()
+		fd := dialog.NewFileSave(func(uc fyne.URIWriteCloser, err error) {
+			if err != nil || uc == nil {
+				return
+			}
+			_, werr := uc.Write([]byte(text))
+			if cerr := uc.Close(); werr == nil {
+				werr = cerr
+			}
+			if werr != nil {
+				dialog.ShowError(werr, win)
+			}
+		}, win)
+		fd.SetFileName("diff_" + time.Now().Format("20060102-150405") + ".txt")
+		fd.Show()
+	})
+
+	pickers := container.NewGridWithColumns(2,
+		container.NewBorder(nil, nil, widget.NewLabel(T("snapshot_left")), widget.NewButton("…", browse(leftSelect)), leftSelect),
+		container.NewBorder(nil, nil, widget.NewLabel(T("snapshot_right")), widget.NewButton("…", browse(rightSelect)), rightSelect),
+	)
+	filters := container.NewBorder(nil, nil,
+		container.NewHBox(btnCompare, showAdded, showRemoved, showChanged, buildOnly),
+		btnExport, search)
+	top := container.NewVBox(pickers, filters, container.NewGridWithColumns(2, leftInfo, rightInfo))
+	win.SetContent(container.NewBorder(top, summary, nil, nil, table))
+	win.Resize(fyne.NewSize(1100, 700))
+	win.Show()
+}
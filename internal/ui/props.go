@@ -0,0 +1,302 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"adb-gui/internal/adb"
+	"adb-gui/internal/config"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/widget"
+)
+
+// propRow is a line in the property table: either a group header or a property.
+type propRow struct {
+	group  string
+	key    string // empty for group headers
+	header bool
+	count  int // properties in the group (headers only)
+}
+
+// propWatchIntervals are the refresh periods offered in watch mode.
+var propWatchIntervals = []string{"2s", "5s", "10s", "30s"}
+
+// buildParametersTab shows system properties grouped by prefix, with search,
+// setprop editing and a watch mode that highlights changed values.
+func buildParametersTab(w fyne.Window, mgr *adb.Manager, selectedSerialBind binding.String, devices *[]adb.Device, cfg *config.Config) fyne.CanvasObject {
+	var (
+		props     map[string]string
+		previous  map[string]string // values before the change, for highlighted keys
+		rows      []propRow
+		collapsed = map[string]bool{}
+		selected  string
+		fetchGen  int
+	)
+
+	status := widget.NewLabel("")
+	search := widget.NewEntry()
+	search.SetPlaceHolder(T("props_search"))
+
+	headers := []string{T("props_key"), T("props_value")}
+	table := widget.NewTable(
+		func() (int, int) { return len(rows) + 1, len(headers) },
+		func() fyne.CanvasObject {
+			l := widget.NewLabel("")
+			l.Truncation = fyne.TextTruncateEllipsis
+			return l
+		},
+		func(id widget.TableCellID, o fyne.CanvasObject) {
+			l := o.(*widget.Label)
+			l.Importance = widget.MediumImportance
+			l.TextStyle = fyne.TextStyle{}
+			switch {
+			case id.Row == 0:
+				l.TextStyle.Bold = true
+				l.Text = headers[id.Col]
+			case id.Row-1 >= len(rows):
+				l.Text = ""
+			default:
+				r := rows[id.Row-1]
+				if r.header {
+					l.TextStyle.Bold = true
+					l.Text = ""
+					if id.Col == 0 {
+						arrow := "▾"
+						if collapsed[r.group] {
+							arrow = "▸"
+						}
+						name := r.group + "*"
+						if r.group == adb.PropGroups[len(adb.PropGroups)-1] {
+							name = T("props_group_other")
+						}
+						l.Text = fmt.Sprintf("%s %s (%d)", arrow, name, r.count)
+					}
+					break
+				}
+				old, changed := previous[r.key]
+				if changed {
+					l.Importance = widget.WarningImportance
+				}
+				if id.Col == 0 {
+					l.Text = r.key
+				} else {
+					// Multi-line values are shown on one line; the editor shows them in full
+					l.Text = strings.ReplaceAll(props[r.key], "\n", "⏎")
+					if changed {
+						l.Text += "   (" + fmt.Sprintf(T("props_was"), old) + ")"
+					}
+				}
+			}
+			l.Refresh()
+		},
+	)
+	table.SetColumnWidth(0, 360)
+	table.SetColumnWidth(1, 560)
+
+	rebuildRows := func() {
+		q := strings.ToLower(strings.TrimSpace(search.Text))
+		byGroup := map[string][]string{}
+		for k, v := range props {
+			if q != "" && !strings.Contains(strings.ToLower(k), q) && !strings.Contains(strings.ToLower(v), q) {
+				continue
+			}
+			g := adb.PropGroup(k)
+			byGroup[g] = append(byGroup[g], k)
+		}
+		rows = rows[:0]
+		for _, g := range adb.PropGroups {
+			keys := byGroup[g]
+			if len(keys) == 0 {
+				continue
+			}
+			sort.Strings(keys)
+			rows = append(rows, propRow{group: g, header: true, count: len(keys)})
+			if collapsed[g] && q == "" {
+				continue
+			}
+			for _, k := range keys {
+				rows = append(rows, propRow{group: g, key: k})
+			}
+		}
+		table.Refresh()
+	}
+	search.OnChanged = func(string) { rebuildRows() }
+
+	// Editor for the selected property
+	keyLabel := widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	valueEntry := widget.NewMultiLineEntry()
+	valueEntry.SetMinRowsVisible(2)
+	btnSet := widget.NewButton(T("props_setprop"), nil)
+	btnSet.Disable()
+	btnCopy := widget.NewButton(T("copy"), func() {
+		if selected != "" {
+			w.Clipboard().SetContent(selected + "=" + props[selected])
+		}
+	})
+	showSelected := func() {
+		keyLabel.SetText(selected)
+		valueEntry.SetText(props[selected])
+		if selected != "" && adb.PropWritable(selected) {
+			btnSet.Enable()
+			valueEntry.Enable()
+		} else {
+			btnSet.Disable()
+			valueEntry.Disable()
+		}
+	}
+	table.OnSelected = func(id widget.TableCellID) {
+		if id.Row == 0 || id.Row-1 >= len(rows) {
+			return
+		}
+		r := rows[id.Row-1]
+		if r.header {
+			collapsed[r.group] = !collapsed[r.group]
+			table.UnselectAll()
+			rebuildRows()
+			return
+		}
+		selected = r.key
+		showSelected()
+	}
+
+	// doRefresh fetches properties; in watch mode changed values are highlighted
+	doRefresh := func(watching bool) {
+		serial, _ := selectedSerialBind.Get()
+		fetchGen++
+		gen := fetchGen
+		if serial == "" {
+			props, previous = nil, nil
+			rebuildRows()
+			status.SetText(T("select_device"))
+			return
+		}
+		go func() {
+			data, _, err := mgr.GetProps(serial)
+			fyne.Do(func() {
+				if gen != fetchGen {
+					return
+				}
+				if err != nil {
+					status.SetText(T("error") + ": " + err.Error())
+					return
+				}
+				if !watching || props == nil {
+					previous = map[string]string{}
+				} else {
+					for k, v := range data {
+						if old, ok := props[k]; ok && old == v {
+							continue
+						}
+						if _, marked := previous[k]; !marked {
+							previous[k] = props[k]
+						}
+					}
+				}
+				// Don't overwrite a value the user is editing
+				untouched := selected == "" || valueEntry.Text == props[selected]
+				props = data
+				rebuildRows()
+				if untouched {
+					showSelected()
+				}
+				text := fmt.Sprintf(T("props_status"), len(props), time.Now().Format("15:04:05"))
+				if len(previous) > 0 {
+					text += "  " + fmt.Sprintf(T("props_changed"), len(previous))
+				}
+				status.SetText(text)
+			})
+		}()
+	}
+
+	btnSet.OnTapped = func() {
+		serial, _ := selectedSerialBind.Get()
+		key, value := selected, valueEntry.Text
+		if serial == "" || key == "" {
+			return
+		}
+		go func() {
+			out, err := mgr.SetProp(serial, key, value, false)
+			if err != nil {
+				showCmdResult(T("props_setprop"), out, err, w)
+				return
+			}
+			fyne.Do(func() { doRefresh(true) })
+		}()
+	}
+
+	var watchStop chan struct{}
+	intervalSelect := widget.NewSelect(propWatchIntervals, nil)
+	intervalSelect.SetSelected(propWatchIntervals[1])
+	watchCheck := widget.NewCheck(T("props_watch"), nil)
+	startWatch := func() {
+		if watchStop != nil {
+			close(watchStop)
+			watchStop = nil
+		}
+		if !watchCheck.Checked {
+			return
+		}
+		every, err := time.ParseDuration(intervalSelect.Selected)
+		if err != nil {
+			every = 5 * time.Second
+		}
+		stop := make(chan struct{})
+		watchStop = stop
+		go func() {
+			t := time.NewTicker(every)
+			defer t.Stop()
+			for {
+				select {
+				case <-stop:
+					return
+				case <-t.C:
+					fyne.Do(func() { doRefresh(true) })
+				}
+			}
+		}()
+	}
+	watchCheck.OnChanged = func(bool) { startWatch() }
+	intervalSelect.OnChanged = func(string) { startWatch() }
+
+	btnRefresh := widget.NewButton(T("refresh"), func() { doRefresh(watchCheck.Checked) })
+	btnClearMarks := widget.NewButton(T("props_clear_highlights"), func() {
+		previous = map[string]string{}
+		table.Refresh()
+	})
+	btnExpand := widget.NewButton(T("props_expand_all"), func() {
+		collapsed = map[string]bool{}
+		rebuildRows()
+	})
+	btnCollapse := widget.NewButton(T("props_collapse_all"), func() {
+		for _, g := range adb.PropGroups {
+			collapsed[g] = true
+		}
+		rebuildRows()
+	})
+
+	btnSnapshot := widget.NewButton(T("snapshot_save"), func() {
+		saveSnapshot(w, mgr, cfg, mustGet(selectedSerialBind), adb.SnapshotProps)
+	})
+	btnCompare := widget.NewButton(T("snapshot_compare"), func() {
+		showPropDiff(mgr, *devices, cfg)
+	})
+
+	selectedSerialBind.AddListener(binding.NewDataListener(func() {
+		props, previous, selected = nil, nil, ""
+		table.UnselectAll()
+		showSelected()
+		doRefresh(false)
+	}))
+
+	controls := container.NewBorder(nil, nil,
+		container.NewHBox(widget.NewLabel(T("parameters")), btnRefresh, btnExpand, btnCollapse, btnSnapshot, btnCompare),
+		container.NewHBox(watchCheck, intervalSelect, btnClearMarks),
+		search)
+	editor := container.NewBorder(nil, nil, keyLabel, container.NewHBox(btnSet, btnCopy), valueEntry)
+	return container.NewBorder(controls, container.NewVBox(editor, status), nil, nil, table)
+}
@@ -0,0 +1,217 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"adb-gui/internal/adb"
+	"adb-gui/internal/config"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// buildRecordSection returns the screen recording controls and the list of
+// per-device recordings. Each device can record once at a time; several
+// devices can record in parallel.
+func buildRecordSection(w fyne.Window, mgr *adb.Manager, selectedSerialBind binding.String, devices *[]adb.Device, cfg *config.Config) fyne.CanvasObject {
+	bitRates := map[string]int{T("default"): 0, "4 Mbps": 4_000_000, "8 Mbps": 8_000_000, "12 Mbps": 12_000_000, "20 Mbps": 20_000_000}
+	bitRateSelect := widget.NewSelect([]string{T("default"), "4 Mbps", "8 Mbps", "12 Mbps", "20 Mbps"}, nil)
+	bitRateSelect.SetSelected(T("default"))
+	sizeSelect := widget.NewSelect([]string{T("native"), "1920x1080", "1280x720", "720x480"}, nil)
+	sizeSelect.SetSelected(T("native"))
+	limitEntry := widget.NewEntry()
+	limitEntry.SetPlaceHolder(T("record_until_stopped"))
+	bugreportCheck := widget.NewCheck(T("record_bugreport_overlay"), nil)
+
+	options := func() (adb.RecordOptions, error) {
+		opts := adb.RecordOptions{BitRate: bitRates[bitRateSelect.Selected], BugReport: bugreportCheck.Checked}
+		if sizeSelect.Selected != T("native") {
+			opts.Size = sizeSelect.Selected
+		}
+		if s := strings.TrimSpace(limitEntry.Text); s != "" {
+			secs, err := strconv.Atoi(s)
+			if err != nil || secs <= 0 {
+				return opts, fmt.Errorf("%s: %s", T("record_time_limit"), s)
+			}
+			opts.TimeLimit = time.Duration(secs) * time.Second
+		}
+		return opts, nil
+	}
+
+	// Recordings of this session, newest last; finished ones stay until cleared
+	var recordings []*adb.Recording
+	active := func(serial string) bool {
+		for _, r := range recordings {
+			if phase, _ := r.Status(); r.Serial == serial && phase != adb.RecordDone {
+				return true
+			}
+		}
+		return false
+	}
+
+	var recList *widget.List
+	recList = widget.NewList(
+		func() int { return len(recordings) },
+		func() fyne.CanvasObject {
+			lbl := widget.NewLabel("")
+			lbl.Truncation = fyne.TextTruncateEllipsis
+			return container.NewBorder(nil, nil, nil, widget.NewButton(T("stop"), nil), lbl)
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			r := recordings[i]
+			row := o.(*fyne.Container)
+			lbl := row.Objects[0].(*widget.Label)
+			btn := row.Objects[1].(*widget.Button)
+			phase, segs := r.Status()
+			switch phase {
+			case adb.RecordRecording:
+				el := time.Since(r.Started).Truncate(time.Second)
+				lbl.SetText(fmt.Sprintf("● %s  %s  %s %d", r.Serial, el, T("record_segment"), segs))
+				lbl.Importance = widget.DangerImportance
+				btn.Enable()
+			case adb.RecordPulling:
+				lbl.SetText(fmt.Sprintf("%s  %s", r.Serial, T("record_pulling")))
+				lbl.Importance = widget.MediumImportance
+				btn.Disable()
+			default:
+				files, note, err := r.Result()
+				text := fmt.Sprintf("✓ %s  %s", r.Serial, strings.Join(files, ", "))
+				if err != nil {
+					text = fmt.Sprintf("✗ %s  %v", r.Serial, err)
+				} else if len(files) == 0 {
+					text = fmt.Sprintf("%s  %s", r.Serial, T("record_empty"))
+				} else if note != "" {
+					text += "  (" + note + ")"
+				}
+				lbl.SetText(text)
+				lbl.Importance = widget.MediumImportance
+				btn.Disable()
+			}
+			lbl.Refresh()
+			btn.OnTapped = func() {
+				btn.Disable()
+				go r.Stop()
+			}
+		},
+	)
+
+	// Tick while anything is running so elapsed time and phases stay current
+	ticking := false
+	startTicker := func() {
+		if ticking {
+			return
+		}
+		ticking = true
+		go func() {
+			t := time.NewTicker(time.Second)
+			defer t.Stop()
+			for range t.C {
+				busy := false
+				fyne.DoAndWait(func() {
+					recList.Refresh()
+					for _, r := range recordings {
+						if phase, _ := r.Status(); phase != adb.RecordDone {
+							busy = true
+						}
+					}
+					if !busy {
+						ticking = false
+					}
+				})
+				if !busy {
+					return
+				}
+			}
+		}()
+	}
+
+	start := func(serials []string) {
+		opts, err := options()
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		var errs []string
+		for _, serial := range serials {
+			if active(serial) {
+				errs = append(errs, fmt.Sprintf("%s: %s", serial, T("record_already_running")))
+				continue
+			}
+			local := filepath.Join(cfg.MediaOutputDir(), mediaFileName(serial, time.Now(), ".mp4"))
+			r, err := mgr.StartRecording(serial, opts, local)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", serial, err))
+				continue
+			}
+			recordings = append(recordings, r)
+		}
+		recList.Refresh()
+		startTicker()
+		if len(errs) > 0 {
+			dialog.ShowInformation(T("record"), strings.Join(errs, "\n"), w)
+		}
+	}
+
+	btnRecord := widget.NewButton(T("record"), func() {
+		serial, _ := selectedSerialBind.Get()
+		if serial == "" {
+			dialog.ShowInformation(T("no_device"), T("please_select_device"), w)
+			return
+		}
+		start([]string{serial})
+	})
+	btnRecordAll := widget.NewButton(T("record_all_devices"), func() {
+		var serials []string
+		for _, d := range *devices {
+			if d.State == "device" {
+				serials = append(serials, d.Serial)
+			}
+		}
+		if len(serials) == 0 {
+			dialog.ShowInformation(T("no_device"), T("please_select_device"), w)
+			return
+		}
+		start(serials)
+	})
+	btnStopAll := widget.NewButton(T("stop_all"), func() {
+		for _, r := range recordings {
+			go r.Stop()
+		}
+	})
+	btnClear := widget.NewButton(T("clear_finished"), func() {
+		var keep []*adb.Recording
+		for _, r := range recordings {
+			if phase, _ := r.Status(); phase != adb.RecordDone {
+				keep = append(keep, r)
+			}
+		}
+		recordings = keep
+		recList.Refresh()
+	})
+
+	form := container.NewHBox(
+		widget.NewLabel(T("record_bit_rate")), bitRateSelect,
+		widget.NewLabel(T("record_size")), sizeSelect,
+		widget.NewLabel(T("record_time_limit")), container.NewGridWrap(fyne.NewSize(140, limitEntry.MinSize().Height), limitEntry),
+		bugreportCheck,
+	)
+	// Give the list a few rows of height inside the VBox
+	listMin := canvas.NewRectangle(color.Transparent)
+	listMin.SetMinSize(fyne.NewSize(0, 110))
+	listBox := container.NewStack(listMin, recList)
+	return container.NewVBox(
+		widget.NewLabelWithStyle(T("screen_recording"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		form,
+		container.NewHBox(btnRecord, btnRecordAll, btnStopAll, btnClear),
+		listBox,
+	)
+}
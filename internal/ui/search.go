@@ -0,0 +1,296 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"adb-gui/internal/adb"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// maxSearchResults caps how many rows a search keeps so a search from "/" cannot exhaust memory.
+const maxSearchResults = 5000
+
+// parseSizeInput parses "", "512", "10K", "10KB", "1.5M" or "2GB" into bytes
+// (0 means unset).
+func parseSizeInput(input string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(input))
+	if s == "" {
+		return 0, nil
+	}
+	s = strings.TrimSuffix(s, "B")
+	mult := 1.0
+	switch {
+	case strings.HasSuffix(s, "K"):
+		mult = 1024
+	case strings.HasSuffix(s, "M"):
+		mult = 1024 * 1024
+	case strings.HasSuffix(s, "G"):
+		mult = 1024 * 1024 * 1024
+	}
+	if mult > 1 {
+		s = s[:len(s)-1]
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid size %q", input)
+	}
+	return int64(v * mult), nil
+}
+
+// parseDateInput parses "" (unset), "YYYY-MM-DD" or a number of days before now.
+func parseDateInput(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	if d, err := strconv.ParseFloat(s, 64); err == nil && d >= 0 {
+		return time.Now().Add(-time.Duration(d * 24 * float64(time.Hour))), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", s)
+}
+
+// showSearchDialog shows a recursive find panel rooted at root. open navigates the
+// file browser to a result and deletePaths deletes results using the browser's delete rules.
+func showSearchDialog(
+	w fyne.Window,
+	mgr *adb.Manager,
+	serial, root string,
+	open func(remotePath string, isDir bool),
+	deletePaths func(serial string, remote []string, after func(err error)),
+) {
+	var results []adb.FileEntry
+	var cancel context.CancelFunc
+	var d dialog.Dialog
+	searchGen := 0
+
+	rootEntry := widget.NewEntry()
+	rootEntry.SetText(root)
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("*.log")
+	nameMode := widget.NewSelect([]string{T("search_glob"), T("search_regex")}, nil)
+	nameMode.SetSelected(T("search_glob"))
+	typeSelect := widget.NewSelect([]string{T("search_type_any"), T("search_type_file"), T("search_type_dir"), T("search_type_link")}, nil)
+	typeSelect.SetSelected(T("search_type_any"))
+	minSize := widget.NewEntry()
+	minSize.SetPlaceHolder("10K")
+	maxSize := widget.NewEntry()
+	maxSize.SetPlaceHolder("1G")
+	newer := widget.NewEntry()
+	newer.SetPlaceHolder(T("search_date_placeholder"))
+	older := widget.NewEntry()
+	older.SetPlaceHolder(T("search_date_placeholder"))
+	status := widget.NewLabel("")
+
+	var list *widget.List
+	list = widget.NewList(
+		func() int { return len(results) },
+		func() fyne.CanvasObject {
+			name := widget.NewLabel("path")
+			name.Truncation = fyne.TextTruncateEllipsis
+			btnOpen := widget.NewButtonWithIcon("", theme.FolderOpenIcon(), nil)
+			btnDownload := widget.NewButtonWithIcon("", theme.DownloadIcon(), nil)
+			btnDelete := widget.NewButtonWithIcon("", theme.DeleteIcon(), nil)
+			return container.NewBorder(nil, nil, nil, container.NewHBox(btnOpen, btnDownload, btnDelete), name)
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			if i < 0 || i >= len(results) {
+				return
+			}
+			e := results[i]
+			lbl := findFirstLabel(o)
+			btns := findButtons(o, 3)
+			if lbl == nil || len(btns) < 3 {
+				return
+			}
+			size := "--"
+			if !e.IsDir {
+				size = formatFileSize(e.Size)
+			}
+			lbl.SetText(fmt.Sprintf("%s    %s    %s", e.Name, size, e.ModTime))
+			if e.IsDir {
+				lbl.TextStyle = fyne.TextStyle{Bold: true}
+			} else {
+				lbl.TextStyle = fyne.TextStyle{}
+			}
+			lbl.Refresh()
+			btns[0].OnTapped = func() {
+				if cancel != nil {
+					cancel()
+				}
+				d.Hide()
+				open(e.Name, e.IsDir)
+			}
+			btns[1].OnTapped = func() {
+				dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+					if err != nil || uri == nil {
+						return
+					}
+					localDir := uri.Path()
+					go func() {
+						out, err := mgr.Pull(serial, e.Name, localDir, true)
+						fyne.Do(func() {
+							if err != nil {
+								dialog.ShowError(fmt.Errorf("%s: %v\n%s", T("download_failed"), err, out), w)
+							} else {
+								dialog.ShowInformation(T("download"), T("download_complete")+"\n"+out, w)
+							}
+						})
+					}()
+				}, w)
+			}
+			btns[2].OnTapped = func() {
+				deletePaths(serial, []string{e.Name}, func(err error) {
+					if err != nil {
+						return
+					}
+					for j := range results {
+						if results[j].Name == e.Name {
+							results = append(results[:j], results[j+1:]...)
+							break
+						}
+					}
+					list.Refresh()
+				})
+			}
+		},
+	)
+
+	var btnSearch, btnStop *widget.Button
+	btnStop = widget.NewButton(T("stop"), func() {
+		if cancel != nil {
+			cancel()
+		}
+	})
+	btnStop.Disable()
+	btnSearch = widget.NewButton(T("search"), func() {
+		opts := adb.FindOptions{Root: strings.TrimSpace(rootEntry.Text)}
+		if opts.Root == "" {
+			dialog.ShowInformation(T("error"), T("please_enter_path"), w)
+			return
+		}
+		if pat := strings.TrimSpace(nameEntry.Text); pat != "" {
+			if nameMode.Selected == T("search_regex") {
+				re, err := regexp.Compile(pat)
+				if err != nil {
+					dialog.ShowError(err, w)
+					return
+				}
+				opts.Regex = re
+			} else {
+				opts.Name = pat
+			}
+		}
+		switch typeSelect.Selected {
+		case T("search_type_file"):
+			opts.Type = "f"
+		case T("search_type_dir"):
+			opts.Type = "d"
+		case T("search_type_link"):
+			opts.Type = "l"
+		}
+		var err1, err2, err3, err4 error
+		opts.MinSize, err1 = parseSizeInput(minSize.Text)
+		opts.MaxSize, err2 = parseSizeInput(maxSize.Text)
+		opts.NewerThan, err3 = parseDateInput(newer.Text)
+		opts.OlderThan, err4 = parseDateInput(older.Text)
+		if err := errors.Join(err1, err2, err3, err4); err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+
+		if cancel != nil {
+			cancel()
+		}
+		ctx, c := context.WithCancel(context.Background())
+		cancel = c
+		searchGen++
+		gen := searchGen
+		results = nil
+		list.Refresh()
+		status.SetText(T("searching"))
+		btnStop.Enable()
+
+		go func() {
+			// Batch rows so a fast find does not flood the UI thread
+			var pending []adb.FileEntry
+			last := time.Now()
+			flush := func() {
+				batch := pending
+				pending = nil
+				fyne.Do(func() {
+					if gen != searchGen {
+						return
+					}
+					results = append(results, batch...)
+					status.SetText(fmt.Sprintf("%s %d", T("searching"), len(results)))
+					list.Refresh()
+				})
+			}
+			count := 0
+			err := mgr.Find(ctx, serial, opts, func(e adb.FileEntry) {
+				if count >= maxSearchResults {
+					c()
+					return
+				}
+				count++
+				pending = append(pending, e)
+				if len(pending) >= 50 || time.Since(last) > 300*time.Millisecond {
+					flush()
+					last = time.Now()
+				}
+			})
+			if len(pending) > 0 {
+				flush()
+			}
+			fyne.Do(func() {
+				if gen != searchGen {
+					return
+				}
+				btnStop.Disable()
+				switch {
+				case count >= maxSearchResults:
+					status.SetText(fmt.Sprintf(T("search_truncated"), maxSearchResults))
+				case errors.Is(err, context.Canceled):
+					status.SetText(fmt.Sprintf("%s (%d)", T("search_cancelled"), len(results)))
+				case err != nil:
+					status.SetText(T("error") + ": " + err.Error())
+				default:
+					status.SetText(fmt.Sprintf("%s: %d", T("search_results"), len(results)))
+				}
+			})
+		}()
+	})
+
+	form := widget.NewForm(
+		widget.NewFormItem(T("path"), rootEntry),
+		widget.NewFormItem(T("file_name"), container.NewBorder(nil, nil, nil, nameMode, nameEntry)),
+		widget.NewFormItem(T("type"), typeSelect),
+		widget.NewFormItem(T("file_size"), container.NewGridWithColumns(2, minSize, maxSize)),
+		widget.NewFormItem(T("search_modified"), container.NewGridWithColumns(2, newer, older)),
+	)
+	top := container.NewVBox(form, container.NewHBox(btnSearch, btnStop, status))
+
+	d = dialog.NewCustom(T("search")+" - "+path.Clean(root), T("close"), container.NewBorder(top, nil, nil, nil, list), w)
+	d.SetOnClosed(func() {
+		if cancel != nil {
+			cancel()
+		}
+	})
+	d.Resize(fyne.NewSize(900, 600))
+	d.Show()
+}
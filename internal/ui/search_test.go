@@ -0,0 +1,34 @@
+package ui
+
+import "testing"
+
+func TestParseSizeInput(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"512", 512, false},
+		{"10K", 10 << 10, false},
+		{"10KB", 10 << 10, false},
+		{"10kb", 10 << 10, false},
+		{"1.5M", 3 << 19, false},
+		{"1.5MB", 3 << 19, false},
+		{"2GB", 2 << 30, false},
+		{"512B", 512, false},
+		{"-1", 0, true},
+		{"KB", 0, true},
+		{"10KK", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseSizeInput(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseSizeInput(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseSizeInput(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,563 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"adb-gui/internal/adb"
+	"adb-gui/internal/config"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// quickToggle is an on/off developer option backed by one settings entry.
+type quickToggle struct {
+	label     string // i18n key
+	namespace string
+	key       string
+	on, off   string
+}
+
+var quickToggles = []quickToggle{
+	{"settings_q_stay_awake", "global", "stay_on_while_plugged_in", "7", "0"},
+	{"settings_q_show_taps", "system", "show_touches", "1", "0"},
+	{"settings_q_pointer_location", "system", "pointer_location", "1", "0"},
+	{"settings_q_dont_keep_activities", "global", "always_finish_activities", "1", "0"},
+}
+
+// animationScaleKeys are set together by the animation scale quick setting.
+var animationScaleKeys = []string{"window_animation_scale", "transition_animation_scale", "animator_duration_scale"}
+
+// settingsBackupDir is where the original values overwritten by presets are kept.
+func settingsBackupDir(cfg *config.Config) (string, error) {
+	return cfg.DataDir("settings-backups")
+}
+
+// writableDir creates dir if needed and checks that files can be written to it.
+func writableDir(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".probe-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// presetText formats preset entries for editing, one per line:
+// "namespace key=value", or "delete namespace key".
+func presetText(entries []config.SettingEntry) string {
+	var sb strings.Builder
+	for _, e := range entries {
+		if e.Delete {
+			fmt.Fprintf(&sb, "delete %s %s\n", e.Namespace, e.Key)
+		} else {
+			fmt.Fprintf(&sb, "%s %s=%s\n", e.Namespace, e.Key, e.Value)
+		}
+	}
+	return sb.String()
+}
+
+// parsePresetText is the inverse of presetText.
+func parsePresetText(text string) ([]config.SettingEntry, error) {
+	var res []config.SettingEntry
+	for i, ln := range strings.Split(text, "\n") {
+		ln = strings.TrimSpace(ln)
+		if ln == "" || strings.HasPrefix(ln, "#") {
+			continue
+		}
+		del := false
+		if rest, ok := strings.CutPrefix(ln, "delete "); ok {
+			del, ln = true, strings.TrimSpace(rest)
+		}
+		ns, rest, _ := strings.Cut(ln, " ")
+		if indexOf(adb.SettingsNamespaces, ns) < 0 {
+			return nil, fmt.Errorf(T("settings_preset_bad_line"), i+1, ln)
+		}
+		e := config.SettingEntry{Namespace: ns, Delete: del}
+		if del {
+			e.Key = strings.TrimSpace(rest)
+		} else {
+			k, v, ok := strings.Cut(rest, "=")
+			if !ok {
+				return nil, fmt.Errorf(T("settings_preset_bad_line"), i+1, ln)
+			}
+			e.Key, e.Value = strings.TrimSpace(k), v
+		}
+		if e.Key == "" {
+			return nil, fmt.Errorf(T("settings_preset_bad_line"), i+1, ln)
+		}
+		res = append(res, e)
+	}
+	return res, nil
+}
+
+func presetChanges(p config.SettingsPreset) []adb.SettingChange {
+	res := make([]adb.SettingChange, len(p.Entries))
+	for i, e := range p.Entries {
+		res[i] = adb.SettingChange{Namespace: e.Namespace, Key: e.Key, Value: e.Value, Delete: e.Delete}
+	}
+	return res
+}
+
+// buildSettingsTab edits the settings provider (system, secure, global) of the selected device.
+func buildSettingsTab(w fyne.Window, mgr *adb.Manager, selectedSerialBind binding.String, devices *[]adb.Device, cfg *config.Config) fyne.CanvasObject {
+	var (
+		entries map[string]string
+		keys    []string
+		gen     int
+	)
+	status := widget.NewLabel("")
+	nsSelect := widget.NewSelect(adb.SettingsNamespaces, nil)
+	userSelect := widget.NewSelect([]string{"0"}, nil)
+	userSelect.SetSelected("0")
+	search := widget.NewEntry()
+	search.SetPlaceHolder(T("props_search"))
+
+	currentUser := func() int {
+		id, _ := strconv.Atoi(strings.Fields(userSelect.Selected + " 0")[0])
+		return id
+	}
+
+	headers := []string{T("props_key"), T("props_value")}
+	table := widget.NewTable(
+		func() (int, int) { return len(keys) + 1, len(headers) },
+		func() fyne.CanvasObject {
+			l := widget.NewLabel("")
+			l.Truncation = fyne.TextTruncateEllipsis
+			return l
+		},
+		func(id widget.TableCellID, o fyne.CanvasObject) {
+			l := o.(*widget.Label)
+			l.TextStyle = fyne.TextStyle{Bold: id.Row == 0}
+			switch {
+			case id.Row == 0:
+				l.SetText(headers[id.Col])
+			case id.Row-1 >= len(keys):
+				l.SetText("")
+			case id.Col == 0:
+				l.SetText(keys[id.Row-1])
+			default:
+				l.SetText(strings.ReplaceAll(entries[keys[id.Row-1]], "\n", "⏎"))
+			}
+		},
+	)
+	table.SetColumnWidth(0, 300)
+	table.SetColumnWidth(1, 320)
+
+	filterKeys := func() {
+		q := strings.ToLower(strings.TrimSpace(search.Text))
+		keys = keys[:0]
+		for k, v := range entries {
+			if q == "" || strings.Contains(strings.ToLower(k), q) || strings.Contains(strings.ToLower(v), q) {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		table.Refresh()
+	}
+	search.OnChanged = func(string) { filterKeys() }
+
+	// Editor for the selected or a new entry
+	keyEntry := widget.NewEntry()
+	keyEntry.SetPlaceHolder(T("props_key"))
+	valueEntry := widget.NewEntry()
+	valueEntry.SetPlaceHolder(T("props_value"))
+	table.OnSelected = func(id widget.TableCellID) {
+		if id.Row > 0 && id.Row-1 < len(keys) {
+			k := keys[id.Row-1]
+			keyEntry.SetText(k)
+			valueEntry.SetText(entries[k])
+		}
+	}
+
+	// Quick developer toggles; loading suppresses their handlers while values are read
+	loading := false
+	var quickChecks []*widget.Check
+	animSelect := widget.NewSelect([]string{"0", "0.5", "1", "1.5", "2"}, nil)
+	dnsMode := widget.NewSelect([]string{"off", "opportunistic", "hostname"}, nil)
+	dnsHost := widget.NewEntry()
+	dnsHost.SetPlaceHolder("dns.google")
+
+	var refresh func()
+	run := func(title string, f func(serial string, user int) (string, error)) {
+		serial := mustGet(selectedSerialBind)
+		if serial == "" {
+			dialog.ShowInformation(T("no_device"), T("please_select_device"), w)
+			return
+		}
+		user := currentUser()
+		go func() {
+			out, err := f(serial, user)
+			if err != nil {
+				showCmdResult(title, out, err, w)
+			}
+			fyne.Do(refresh)
+		}()
+	}
+
+	refresh = func() {
+		serial := mustGet(selectedSerialBind)
+		gen++
+		myGen := gen
+		if serial == "" || nsSelect.Selected == "" {
+			entries = nil
+			filterKeys()
+			status.SetText(T("select_device"))
+			return
+		}
+		ns, user := nsSelect.Selected, currentUser()
+		status.SetText(T("loading"))
+		go func() {
+			data, out, err := mgr.ListSettings(serial, ns, user)
+			quick := map[string]string{}
+			for _, q := range quickToggles {
+				v, _, _ := mgr.GetSetting(serial, q.namespace, q.key, user)
+				quick[q.key] = v
+			}
+			for _, k := range append(animationScaleKeys[:1:1], "private_dns_mode", "private_dns_specifier") {
+				v, _, _ := mgr.GetSetting(serial, "global", k, user)
+				quick[k] = v
+			}
+			fyne.Do(func() {
+				if myGen != gen {
+					return
+				}
+				if err != nil {
+					status.SetText(T("error") + ": " + strings.TrimSpace(out))
+					return
+				}
+				entries = data
+				filterKeys()
+				status.SetText(fmt.Sprintf(T("settings_status"), len(entries), ns, user))
+				loading = true
+				for i, q := range quickToggles {
+					quickChecks[i].SetChecked(quick[q.key] != "" && quick[q.key] != q.off)
+				}
+				animSelect.SetSelected(quick[animationScaleKeys[0]])
+				dnsMode.SetSelected(quick["private_dns_mode"])
+				dnsHost.SetText(quick["private_dns_specifier"])
+				loading = false
+			})
+		}()
+	}
+
+	for _, q := range quickToggles {
+		q := q
+		quickChecks = append(quickChecks, widget.NewCheck(T(q.label), func(on bool) {
+			if loading {
+				return
+			}
+			v := q.off
+			if on {
+				v = q.on
+			}
+			run(T(q.label), func(serial string, user int) (string, error) {
+				return mgr.PutSetting(serial, q.namespace, q.key, v, user)
+			})
+		}))
+	}
+	animSelect.OnChanged = func(v string) {
+		if loading {
+			return
+		}
+		run(T("settings_q_animation"), func(serial string, user int) (string, error) {
+			var log strings.Builder
+			for _, k := range animationScaleKeys {
+				out, err := mgr.PutSetting(serial, "global", k, v, user)
+				log.WriteString(out)
+				if err != nil {
+					return log.String(), err
+				}
+			}
+			return log.String(), nil
+		})
+	}
+	btnDNS := widget.NewButton(T("apply"), func() {
+		mode, host := dnsMode.Selected, strings.TrimSpace(dnsHost.Text)
+		if mode == "" {
+			return
+		}
+		run(T("settings_q_private_dns"), func(serial string, user int) (string, error) {
+			if mode == "hostname" {
+				if out, err := mgr.PutSetting(serial, "global", "private_dns_specifier", host, user); err != nil {
+					return out, err
+				}
+			}
+			return mgr.PutSetting(serial, "global", "private_dns_mode", mode, user)
+		})
+	})
+
+	btnPut := widget.NewButton(T("settings_put"), func() {
+		ns, k, v := nsSelect.Selected, strings.TrimSpace(keyEntry.Text), valueEntry.Text
+		if ns == "" || k == "" {
+			return
+		}
+		run(T("settings_put"), func(serial string, user int) (string, error) {
+			return mgr.PutSetting(serial, ns, k, v, user)
+		})
+	})
+	btnNew := widget.NewButton(T("settings_new"), func() {
+		table.UnselectAll()
+		keyEntry.SetText("")
+		valueEntry.SetText("")
+		w.Canvas().Focus(keyEntry)
+	})
+	btnDelete := widget.NewButton(T("delete"), func() {
+		ns, k := nsSelect.Selected, strings.TrimSpace(keyEntry.Text)
+		if ns == "" || k == "" {
+			return
+		}
+		dialog.ShowConfirm(T("delete"), fmt.Sprintf(T("settings_delete_confirm"), ns, k), func(ok bool) {
+			if !ok {
+				return
+			}
+			run(T("delete"), func(serial string, user int) (string, error) {
+				return mgr.DeleteSetting(serial, ns, k, user)
+			})
+		}, w)
+	})
+
+	// Presets
+	presetNames := func() []string {
+		var res []string
+		for _, p := range cfg.Presets() {
+			res = append(res, p.Name)
+		}
+		return res
+	}
+	presetSelect := widget.NewSelect(presetNames(), nil)
+	findPreset := func(name string) (config.SettingsPreset, bool) {
+		for _, p := range cfg.Presets() {
+			if p.Name == name {
+				return p, true
+			}
+		}
+		return config.SettingsPreset{}, false
+	}
+	applyPreset := func(serials []string) {
+		p, ok := findPreset(presetSelect.Selected)
+		if !ok || len(serials) == 0 {
+			return
+		}
+		user := currentUser()
+		go func() {
+			// Without a backup there is nothing to roll back to; do not apply.
+			dir, err := settingsBackupDir(cfg)
+			if err == nil {
+				err = writableDir(dir)
+			}
+			if err != nil {
+				fyne.Do(func() {
+					dialog.ShowError(fmt.Errorf("%s: %w", T("settings_backup_failed"), err), w)
+				})
+				return
+			}
+			var report []string
+			for _, s := range serials {
+				b, out, err := mgr.ApplySettings(s, user, p.Name, presetChanges(p))
+				if len(b.Original) > 0 {
+					if e := b.Save(filepath.Join(dir, mediaFileName(s, b.Applied, ".json"))); e != nil && err == nil {
+						err = e
+					}
+				}
+				line := s + ": " + T("success")
+				if err != nil {
+					line = fmt.Sprintf("%s: %v %s", s, err, strings.TrimSpace(out))
+				}
+				report = append(report, line)
+			}
+			fyne.Do(func() {
+				dialog.ShowInformation(T("settings_apply_preset"), strings.Join(report, "\n"), w)
+				refresh()
+			})
+		}()
+	}
+	btnApply := widget.NewButton(T("settings_apply_preset"), func() {
+		if s := mustGet(selectedSerialBind); s != "" {
+			applyPreset([]string{s})
+		}
+	})
+	btnApplyAll := widget.NewButton(T("settings_apply_all"), func() {
+		var serials []string
+		for _, d := range *devices {
+			if d.State == "device" {
+				serials = append(serials, d.Serial)
+			}
+		}
+		applyPreset(serials)
+	})
+	btnEditPresets := widget.NewButton(T("settings_edit_presets"), func() {
+		showPresetEditor(w, cfg, presetSelect.Selected, func(selected string) {
+			presetSelect.Options = presetNames()
+			presetSelect.SetSelected(selected)
+		})
+	})
+	btnRollback := widget.NewButton(T("settings_rollback"), func() {
+		showSettingsRollback(w, mgr, cfg, refresh)
+	})
+
+	nsSelect.OnChanged = func(string) { refresh() }
+	userSelect.OnChanged = func(string) { refresh() }
+	btnRefresh := widget.NewButton(T("refresh"), func() { refresh() })
+
+	selectedSerialBind.AddListener(binding.NewDataListener(func() {
+		serial := mustGet(selectedSerialBind)
+		userSelect.Options = []string{"0"}
+		if serial != "" {
+			go func() {
+				users, _, _ := mgr.Users(serial)
+				fyne.Do(func() {
+					var opts []string
+					for _, u := range users {
+						opts = append(opts, fmt.Sprintf("%d %s", u.ID, u.Name))
+					}
+					userSelect.Options = opts
+					if len(opts) > 0 && indexOf(opts, userSelect.Selected) < 0 {
+						userSelect.SetSelected(opts[0])
+					}
+					userSelect.Refresh()
+				})
+			}()
+		}
+		refresh()
+	}))
+	nsSelect.SetSelected("global")
+
+	quick := container.NewVBox(widget.NewLabelWithStyle(T("settings_quick"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+	for _, c := range quickChecks {
+		quick.Add(c)
+	}
+	quick.Add(container.NewBorder(nil, nil, widget.NewLabel(T("settings_q_animation")), nil, animSelect))
+	quick.Add(container.NewBorder(nil, nil, widget.NewLabel(T("settings_q_private_dns")), btnDNS,
+		container.NewGridWithColumns(2, dnsMode, dnsHost)))
+	presets := container.NewVBox(
+		widget.NewLabelWithStyle(T("settings_presets"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		presetSelect,
+		container.NewGridWithColumns(2, btnApply, btnApplyAll),
+		container.NewGridWithColumns(2, btnEditPresets, btnRollback),
+	)
+	side := container.NewVScroll(container.NewVBox(quick, widget.NewSeparator(), presets))
+
+	controls := container.NewBorder(nil, nil,
+		container.NewHBox(nsSelect, widget.NewLabel(T("user")), userSelect, btnRefresh), nil, search)
+	editor := container.NewBorder(nil, nil, nil, container.NewHBox(btnPut, btnNew, btnDelete),
+		container.NewGridWithColumns(2, keyEntry, valueEntry))
+	main := container.NewBorder(controls, container.NewVBox(editor, status), nil, nil, table)
+	split := container.NewHSplit(main, side)
+	split.Offset = 0.68
+	return split
+}
+
+// showPresetEditor edits the preset list as text, one preset at a time.
+func showPresetEditor(w fyne.Window, cfg *config.Config, selected string, onSaved func(selected string)) {
+	presets := cfg.Presets()
+	nameEntry := widget.NewEntry()
+	body := widget.NewMultiLineEntry()
+	body.SetMinRowsVisible(10)
+	body.SetPlaceHolder("global window_animation_scale=0\nsystem show_touches=1\ndelete secure my_key")
+	for _, p := range presets {
+		if p.Name == selected {
+			nameEntry.SetText(p.Name)
+			body.SetText(presetText(p.Entries))
+		}
+	}
+	deleteCheck := widget.NewCheck(T("settings_preset_delete"), nil)
+	items := []*widget.FormItem{
+		widget.NewFormItem(T("name"), nameEntry),
+		widget.NewFormItem(T("settings_preset_entries"), body),
+		widget.NewFormItem("", deleteCheck),
+	}
+	d := dialog.NewForm(T("settings_edit_presets"), T("save"), T("cancel"), items, func(ok bool) {
+		if !ok {
+			return
+		}
+		name := strings.TrimSpace(nameEntry.Text)
+		if name == "" {
+			return
+		}
+		var out []config.SettingsPreset
+		for _, p := range presets {
+			if p.Name != name {
+				out = append(out, p)
+			}
+		}
+		if !deleteCheck.Checked {
+			entries, err := parsePresetText(body.Text)
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			out = append(out, config.SettingsPreset{Name: name, Entries: entries})
+		} else {
+			name = ""
+		}
+		if out == nil {
+			out = []config.SettingsPreset{}
+		}
+		cfg.SettingsPresets = out
+		if err := config.Save(cfg); err != nil {
+			dialog.ShowError(err, w)
+		}
+		onSaved(name)
+	}, w)
+	d.Resize(fyne.NewSize(620, 460))
+	d.Show()
+}
+
+// showSettingsRollback lists recorded preset applications and restores one.
+func showSettingsRollback(w fyne.Window, mgr *adb.Manager, cfg *config.Config, onDone func()) {
+	var backups []*adb.SettingsBackup
+	var paths []string
+	dir, _ := settingsBackupDir(cfg)
+	for _, p := range mediaFiles(dir, ".json") {
+		if b, err := adb.LoadSettingsBackup(p); err == nil {
+			backups = append(backups, b)
+			paths = append(paths, p)
+		}
+	}
+	if len(backups) == 0 {
+		dialog.ShowInformation(T("settings_rollback"), T("settings_no_backups"), w)
+		return
+	}
+	sel := -1
+	list := widget.NewList(
+		func() int { return len(backups) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			b := backups[i]
+			o.(*widget.Label).SetText(fmt.Sprintf("%s  %s  %s  (%d)", b.Applied.Format("2006-01-02 15:04"), b.Serial, b.Preset, len(b.Original)))
+		},
+	)
+	list.OnSelected = func(i widget.ListItemID) { sel = i }
+	var d dialog.Dialog
+	btnRestore := widget.NewButton(T("settings_rollback"), func() {
+		if sel < 0 {
+			return
+		}
+		b, p := backups[sel], paths[sel]
+		d.Hide()
+		go func() {
+			out, err := mgr.Rollback(b)
+			if err == nil {
+				// A backup is only good for one rollback
+				_ = os.Remove(p)
+			}
+			showCmdResult(T("settings_rollback"), out, err, w)
+			fyne.Do(onDone)
+		}()
+	})
+	d = dialog.NewCustom(T("settings_rollback"), T("close"), container.NewBorder(nil, btnRestore, nil, nil, list), w)
+	d.Resize(fyne.NewSize(560, 380))
+	d.Show()
+}
@@ -0,0 +1,302 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"adb-gui/internal/adb"
+	"adb-gui/internal/config"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// ptyFallbackWindow is how soon a PTY session must fail to be retried in line mode.
+const ptyFallbackWindow = 3 * time.Second
+
+// historyEntry is a single-line entry that walks the command history with Up/Down.
+type historyEntry struct {
+	widget.Entry
+	history func() []string
+	pos     int    // index into history; len(history) is the line being edited
+	draft   string // the edited line, restored when walking back down
+}
+
+func newHistoryEntry(history func() []string) *historyEntry {
+	e := &historyEntry{history: history}
+	e.ExtendBaseWidget(e)
+	e.resetHistory()
+	return e
+}
+
+func (e *historyEntry) resetHistory() {
+	e.pos = len(e.history())
+	e.draft = ""
+}
+
+func (e *historyEntry) TypedKey(k *fyne.KeyEvent) {
+	h := e.history()
+	if e.pos > len(h) {
+		e.pos = len(h)
+	}
+	switch k.Name {
+	case fyne.KeyUp:
+		if e.pos == len(h) {
+			e.draft = e.Text
+		}
+		if e.pos > 0 {
+			e.pos--
+			e.setLine(h[e.pos])
+		}
+	case fyne.KeyDown:
+		if e.pos < len(h)-1 {
+			e.pos++
+			e.setLine(h[e.pos])
+		} else if e.pos == len(h)-1 {
+			e.pos++
+			e.setLine(e.draft)
+		}
+	default:
+		e.Entry.TypedKey(k)
+	}
+}
+
+func (e *historyEntry) setLine(s string) {
+	e.SetText(s)
+	e.CursorColumn = len([]rune(s))
+	e.Refresh()
+}
+
+// shellTab is one session in the Shell tab.
+type shellTab struct {
+	serial   string
+	root     bool
+	sess     *adb.ShellSession
+	term     *terminal
+	view     *widget.List
+	item     *container.TabItem
+	closed   bool // closed by the user
+	ended    bool // the shell exited or failed to start
+	fellBack bool // already retried in line mode
+}
+
+func (s *shellTab) label() string {
+	l := s.serial
+	if s.root {
+		l += " #"
+	}
+	if s.ended {
+		l += " (" + T("shell_exited") + ")"
+	}
+	return l
+}
+
+// output appends shell output; must run on the UI thread.
+func (s *shellTab) output(b []byte) {
+	s.term.Write(b)
+	s.view.Refresh()
+	s.view.ScrollToBottom()
+}
+
+// buildShellTab hosts interactive adb shell sessions, one tab per device session.
+func buildShellTab(w fyne.Window, mgr *adb.Manager, selectedSerialBind binding.String, devices *[]adb.Device, cfg *config.Config) fyne.CanvasObject {
+	var sessions []*shellTab
+	tabs := container.NewDocTabs()
+	placeholder := widget.NewLabel(T("shell_no_sessions"))
+	placeholder.Alignment = fyne.TextAlignCenter
+	body := container.NewStack(placeholder)
+
+	current := func() *shellTab {
+		sel := tabs.Selected()
+		for _, s := range sessions {
+			if s.item == sel {
+				return s
+			}
+		}
+		return nil
+	}
+
+	rootCheck := widget.NewCheck(T("shell_run_as_root"), nil)
+	lineModeCheck := widget.NewCheck(T("shell_line_mode"), nil)
+	broadcastCheck := widget.NewCheck(T("shell_broadcast"), nil)
+
+	// start runs a session in s, falling back to line mode if the PTY shell dies right away
+	var start func(s *shellTab, pty bool)
+	start = func(s *shellTab, pty bool) {
+		go func() {
+			sess, err := mgr.StartShell(s.serial, pty, s.root)
+			if err != nil {
+				fyne.Do(func() {
+					s.output([]byte(fmt.Sprintf("\r\n%s: %v\r\n", T("shell_start_failed"), err)))
+					s.ended = true
+					s.item.Text = s.label()
+					tabs.Refresh()
+				})
+				return
+			}
+			begin := time.Now()
+			fyne.Do(func() {
+				s.sess = sess
+				if s.closed {
+					sess.Close()
+				}
+				s.item.Text = s.label()
+				tabs.Refresh()
+			})
+			buf := make([]byte, 32*1024)
+			for {
+				n, rerr := sess.Read(buf)
+				if n > 0 {
+					chunk := append([]byte(nil), buf[:n]...)
+					fyne.Do(func() { s.output(chunk) })
+				}
+				if rerr != nil {
+					break
+				}
+			}
+			werr := sess.Wait()
+			fyne.Do(func() {
+				s.sess = nil
+				if s.closed {
+					return
+				}
+				if sess.PTY && !s.fellBack && werr != nil && time.Since(begin) < ptyFallbackWindow {
+					s.fellBack = true
+					s.output([]byte("\r\n" + T("shell_pty_fallback") + "\r\n"))
+					start(s, false)
+					return
+				}
+				msg := T("shell_exited")
+				if werr != nil {
+					msg += ": " + werr.Error()
+				}
+				s.output([]byte("\r\n[" + msg + "]\r\n"))
+				s.ended = true
+				s.item.Text = s.label()
+				tabs.Refresh()
+			})
+		}()
+	}
+
+	open := func(serial string) {
+		s := &shellTab{serial: serial, root: rootCheck.Checked, term: newTerminal()}
+		s.view = newTerminalView(s.term)
+		s.item = container.NewTabItem(s.label(), s.view)
+		sessions = append(sessions, s)
+		tabs.Append(s.item)
+		tabs.Select(s.item)
+		body.Objects = []fyne.CanvasObject{tabs}
+		body.Refresh()
+		start(s, !lineModeCheck.Checked)
+	}
+	tabs.OnClosed = func(item *container.TabItem) {
+		for i, s := range sessions {
+			if s.item == item {
+				s.closed = true
+				if s.sess != nil {
+					s.sess.Close()
+				}
+				sessions = append(sessions[:i], sessions[i+1:]...)
+				break
+			}
+		}
+		if len(sessions) == 0 {
+			body.Objects = []fyne.CanvasObject{placeholder}
+			body.Refresh()
+		}
+	}
+
+	btnNew := widget.NewButton(T("shell_new_session"), func() {
+		serial := mustGet(selectedSerialBind)
+		if serial == "" {
+			dialog.ShowInformation(T("no_device"), T("please_select_device"), w)
+			return
+		}
+		open(serial)
+	})
+	btnNewAll := widget.NewButton(T("shell_new_all"), func() {
+		n := 0
+		for _, d := range *devices {
+			if d.State == "device" {
+				open(d.Serial)
+				n++
+			}
+		}
+		if n == 0 {
+			dialog.ShowInformation(T("no_device"), T("please_select_device"), w)
+			return
+		}
+		broadcastCheck.SetChecked(true)
+	})
+
+	// targets are the sessions input goes to: all live sessions when broadcasting
+	targets := func() []*shellTab {
+		if broadcastCheck.Checked {
+			return sessions
+		}
+		if s := current(); s != nil {
+			return []*shellTab{s}
+		}
+		return nil
+	}
+	send := func(data string, echo bool) {
+		for _, s := range targets() {
+			if s.sess == nil {
+				continue
+			}
+			// Line-mode shells have no terminal to echo what was typed
+			if echo && !s.sess.PTY {
+				s.output([]byte(strings.ReplaceAll(data, "\n", "\r\n")))
+			}
+			if _, err := s.sess.Write([]byte(data)); err != nil {
+				s.output([]byte("\r\n" + err.Error() + "\r\n"))
+			}
+		}
+	}
+
+	input := newHistoryEntry(func() []string { return cfg.ShellHistory })
+	input.SetPlaceHolder(T("shell_input_placeholder"))
+	submit := func() {
+		line := input.Text
+		input.SetText("")
+		if strings.TrimSpace(line) != "" {
+			cfg.AddShellHistory(line)
+			if err := config.Save(cfg); err != nil {
+				log.Printf("save config: %v", err)
+			}
+		}
+		input.resetHistory()
+		send(line+"\n", true)
+	}
+	input.OnSubmitted = func(string) { submit() }
+
+	btnSend := widget.NewButton(T("shell_send"), submit)
+	btnCtrlC := widget.NewButton("Ctrl+C", func() { send("\x03", false) })
+	btnCtrlD := widget.NewButton("Ctrl+D", func() { send("\x04", false) })
+	btnCopy := widget.NewButton(T("copy"), func() {
+		if s := current(); s != nil {
+			w.Clipboard().SetContent(s.term.Text())
+		}
+	})
+	btnPaste := widget.NewButton(T("paste"), func() {
+		if text := w.Clipboard().Content(); text != "" {
+			send(strings.ReplaceAll(text, "\r\n", "\n"), true)
+		}
+	})
+	btnClear := widget.NewButton(T("clear"), func() {
+		if s := current(); s != nil {
+			s.term.Clear()
+			s.view.Refresh()
+		}
+	})
+
+	controls := container.NewHBox(btnNew, btnNewAll, rootCheck, lineModeCheck, broadcastCheck)
+	inputRow := container.NewBorder(nil, nil, nil,
+		container.NewHBox(btnSend, btnCtrlC, btnCtrlD, btnCopy, btnPaste, btnClear), input)
+	return container.NewBorder(controls, inputRow, nil, nil, body)
+}
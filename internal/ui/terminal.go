@@ -0,0 +1,333 @@
+package ui
+
+import (
+	"image/color"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+// maxTermLines bounds the scrollback of a terminal.
+const maxTermLines = 5000
+
+// ansiColors are the 16 standard terminal colors (normal, then bright).
+var ansiColors = [16]color.NRGBA{
+	{0, 0, 0, 255}, {205, 49, 49, 255}, {13, 188, 121, 255}, {229, 229, 16, 255},
+	{36, 114, 200, 255}, {188, 63, 188, 255}, {17, 168, 205, 255}, {229, 229, 229, 255},
+	{102, 102, 102, 255}, {241, 76, 76, 255}, {35, 209, 139, 255}, {245, 245, 67, 255},
+	{59, 142, 234, 255}, {214, 112, 214, 255}, {41, 184, 219, 255}, {255, 255, 255, 255},
+}
+
+// xterm256 maps an xterm 256-color index to RGB.
+func xterm256(n int) color.Color {
+	switch {
+	case n < 16:
+		return ansiColors[n]
+	case n < 232:
+		n -= 16
+		level := func(v int) uint8 {
+			if v == 0 {
+				return 0
+			}
+			return uint8(55 + v*40)
+		}
+		return color.NRGBA{level(n / 36), level(n / 6 % 6), level(n % 6), 255}
+	default:
+		g := uint8(8 + (n-232)*10)
+		return color.NRGBA{g, g, g, 255}
+	}
+}
+
+// terminal is a line-oriented screen buffer fed with raw shell output. It
+// understands the subset of VT100/ANSI used by Android shells and common
+// tools: SGR colors, carriage return, backspace, tabs, cursor left/right/up
+// and erase in line/display. Other escape sequences are dropped.
+type terminal struct {
+	lines    [][]widget.TextGridCell
+	row, col int
+	style    *widget.CustomTextGridStyle // nil means default colors
+
+	pending []byte // incomplete UTF-8 or escape sequence from the last write
+}
+
+func newTerminal() *terminal {
+	return &terminal{lines: [][]widget.TextGridCell{nil}}
+}
+
+// Write appends output to the buffer. It must be called on the UI thread.
+func (t *terminal) Write(p []byte) {
+	data := append(t.pending, p...)
+	t.pending = nil
+	for i := 0; i < len(data); {
+		b := data[i]
+		switch {
+		case b == 0x1b:
+			n := t.escape(data[i:])
+			if n == 0 {
+				t.pending = append([]byte{}, data[i:]...)
+				return
+			}
+			i += n
+			continue
+		case b == '\r':
+			t.col = 0
+		case b == '\n':
+			t.newline()
+		case b == '\b':
+			if t.col > 0 {
+				t.col--
+			}
+		case b == '\t':
+			t.col = (t.col/8 + 1) * 8
+		case b < 0x20 || b == 0x7f:
+			// BEL and other controls
+		default:
+			if !utf8.FullRune(data[i:]) {
+				t.pending = append([]byte{}, data[i:]...)
+				return
+			}
+			r, n := utf8.DecodeRune(data[i:])
+			t.put(r)
+			i += n
+			continue
+		}
+		i++
+	}
+}
+
+// Text returns the buffer as plain text.
+func (t *terminal) Text() string {
+	var sb strings.Builder
+	for i, ln := range t.lines {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		sb.WriteString(strings.TrimRight(cellsText(ln), " "))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// Clear empties the buffer.
+func (t *terminal) Clear() {
+	t.lines = [][]widget.TextGridCell{nil}
+	t.row, t.col = 0, 0
+}
+
+func cellsText(cells []widget.TextGridCell) string {
+	rs := make([]rune, len(cells))
+	for i, c := range cells {
+		rs[i] = c.Rune
+		if rs[i] == 0 {
+			rs[i] = ' '
+		}
+	}
+	return string(rs)
+}
+
+func (t *terminal) put(r rune) {
+	ln := t.lines[t.row]
+	for len(ln) <= t.col {
+		ln = append(ln, widget.TextGridCell{Rune: ' '})
+	}
+	ln[t.col] = widget.TextGridCell{Rune: r}
+	if t.style != nil {
+		// A typed nil would not read as "no style" to the text grid
+		ln[t.col].Style = t.style
+	}
+	t.lines[t.row] = ln
+	t.col++
+}
+
+func (t *terminal) newline() {
+	t.row++
+	t.col = 0
+	if t.row == len(t.lines) {
+		t.lines = append(t.lines, nil)
+	}
+	if len(t.lines) > maxTermLines+maxTermLines/10 {
+		drop := len(t.lines) - maxTermLines
+		t.lines = append(t.lines[:0:0], t.lines[drop:]...)
+		t.row -= drop
+	}
+}
+
+// escape handles the sequence at the start of data and returns its length,
+// or 0 if it is incomplete.
+func (t *terminal) escape(data []byte) int {
+	if len(data) < 2 {
+		return 0
+	}
+	switch data[1] {
+	case '[':
+		for j := 2; j < len(data); j++ {
+			if data[j] >= 0x40 && data[j] <= 0x7e {
+				t.csi(string(data[2:j]), data[j])
+				return j + 1
+			}
+		}
+		return 0
+	case ']':
+		// OSC (window title etc.), terminated by BEL or ESC \
+		for j := 2; j < len(data); j++ {
+			if data[j] == 0x07 {
+				return j + 1
+			}
+			if data[j] == 0x1b && j+1 < len(data) && data[j+1] == '\\' {
+				return j + 2
+			}
+		}
+		return 0
+	case '(', ')':
+		if len(data) < 3 {
+			return 0
+		}
+		return 3
+	}
+	return 2
+}
+
+func (t *terminal) csi(params string, final byte) {
+	private := strings.HasPrefix(params, "?")
+	params = strings.TrimLeft(params, "?>=")
+	var args []int
+	for _, p := range strings.Split(params, ";") {
+		n, _ := strconv.Atoi(p)
+		args = append(args, n)
+	}
+	arg := func(i, def int) int {
+		if i < len(args) && args[i] > 0 {
+			return args[i]
+		}
+		return def
+	}
+	if private {
+		return
+	}
+	switch final {
+	case 'm':
+		t.sgr(args)
+	case 'C':
+		t.col += arg(0, 1)
+	case 'D':
+		t.col = max(0, t.col-arg(0, 1))
+	case 'A':
+		t.row = max(0, t.row-arg(0, 1))
+	case 'B':
+		for n := arg(0, 1); n > 0; n-- {
+			t.newline()
+		}
+	case 'G':
+		t.col = arg(0, 1) - 1
+	case 'K':
+		ln := t.lines[t.row]
+		switch arg(0, 0) {
+		case 0:
+			if t.col < len(ln) {
+				t.lines[t.row] = ln[:t.col]
+			}
+		case 1:
+			for i := 0; i < t.col && i < len(ln); i++ {
+				ln[i] = widget.TextGridCell{Rune: ' '}
+			}
+		case 2:
+			t.lines[t.row] = nil
+		}
+	case 'J':
+		if arg(0, 0) >= 2 {
+			// "clear": start a fresh page instead of wiping the scrollback
+			if len(t.lines[t.row]) > 0 {
+				t.newline()
+			}
+		} else if arg(0, 0) == 0 {
+			t.lines = t.lines[:t.row+1]
+			if t.col < len(t.lines[t.row]) {
+				t.lines[t.row] = t.lines[t.row][:t.col]
+			}
+		}
+	case 'H', 'f':
+		// Absolute positioning is only honored for "home", as used by clear
+		if arg(0, 1) == 1 && arg(1, 1) == 1 {
+			t.col = 0
+		}
+	}
+}
+
+func (t *terminal) sgr(args []int) {
+	st := widget.CustomTextGridStyle{}
+	if t.style != nil {
+		st = *t.style
+	}
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		switch {
+		case a == 0:
+			st = widget.CustomTextGridStyle{}
+		case a == 1:
+			st.TextStyle.Bold = true
+		case a == 3:
+			st.TextStyle.Italic = true
+		case a == 4:
+			st.TextStyle.Underline = true
+		case a == 22:
+			st.TextStyle.Bold = false
+		case a == 23:
+			st.TextStyle.Italic = false
+		case a == 24:
+			st.TextStyle.Underline = false
+		case a >= 30 && a <= 37:
+			st.FGColor = ansiColors[a-30]
+		case a >= 90 && a <= 97:
+			st.FGColor = ansiColors[a-90+8]
+		case a == 39:
+			st.FGColor = nil
+		case a >= 40 && a <= 47:
+			st.BGColor = ansiColors[a-40]
+		case a >= 100 && a <= 107:
+			st.BGColor = ansiColors[a-100+8]
+		case a == 49:
+			st.BGColor = nil
+		case a == 38 || a == 48:
+			var c color.Color
+			if i+2 < len(args) && args[i+1] == 5 {
+				c = xterm256(args[i+2] & 0xff)
+				i += 2
+			} else if i+4 < len(args) && args[i+1] == 2 {
+				c = color.NRGBA{uint8(args[i+2]), uint8(args[i+3]), uint8(args[i+4]), 255}
+				i += 4
+			}
+			if a == 38 {
+				st.FGColor = c
+			} else {
+				st.BGColor = c
+			}
+		}
+	}
+	if st == (widget.CustomTextGridStyle{}) {
+		t.style = nil
+		return
+	}
+	t.style = &st
+}
+
+// newTerminalView shows a terminal as a virtualized list of one-row text grids.
+func newTerminalView(t *terminal) *widget.List {
+	return widget.NewList(
+		func() int { return len(t.lines) },
+		func() fyne.CanvasObject {
+			g := widget.NewTextGrid()
+			g.Scroll = fyne.ScrollNone
+			return g
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			if i >= len(t.lines) {
+				return
+			}
+			g := o.(*widget.TextGrid)
+			g.Rows = []widget.TextGridRow{{Cells: t.lines[i]}}
+			g.Refresh()
+		},
+	)
+}
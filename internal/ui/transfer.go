@@ -0,0 +1,286 @@
+package ui
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+)
+
+// transferItem is one file or directory queued for push/pull.
+type transferItem struct {
+	Path string // local path for uploads, remote path for downloads
+	Size int64  // bytes, 0 if unknown
+}
+
+// transferDialog is the shared progress window for uploads, downloads and archive transfers.
+// All methods are safe to call from background goroutines.
+type transferDialog struct {
+	d         dialog.Dialog
+	item      *widget.Label
+	detail    *widget.Label
+	bar       *widget.ProgressBar
+	cancelled atomic.Bool
+	// OnCancel, if set, is called on the UI thread when Cancel is pressed,
+	// e.g. to abort a single long-running stream.
+	OnCancel func()
+}
+
+func showTransferDialog(w fyne.Window, title string) *transferDialog {
+	t := &transferDialog{
+		item:   widget.NewLabel(""),
+		detail: widget.NewLabel(""),
+		bar:    widget.NewProgressBar(),
+	}
+	t.item.Truncation = fyne.TextTruncateEllipsis
+	btnCancel := widget.NewButton(T("cancel"), func() {
+		t.cancelled.Store(true)
+		if t.OnCancel != nil {
+			t.OnCancel()
+		}
+	})
+	content := container.NewVBox(t.item, t.bar, t.detail, btnCancel)
+	t.d = dialog.NewCustomWithoutButtons(title, content, w)
+	t.d.Resize(fyne.NewSize(520, 200))
+	t.d.Show()
+	return t
+}
+
+// Update shows the current item and overall progress (done/total bytes).
+// A total of 0 shows an indeterminate byte counter.
+func (t *transferDialog) Update(item string, done, total int64) {
+	fyne.Do(func() {
+		t.item.SetText(item)
+		if total > 0 {
+			t.bar.SetValue(float64(done) / float64(total))
+			t.detail.SetText(fmt.Sprintf("%s / %s", formatFileSize(done), formatFileSize(total)))
+		} else {
+			t.detail.SetText(formatFileSize(done))
+		}
+	})
+}
+
+// Cancelled reports whether the user pressed Cancel.
+func (t *transferDialog) Cancelled() bool {
+	return t.cancelled.Load()
+}
+
+func (t *transferDialog) Close() {
+	fyne.Do(func() {
+		t.d.Hide()
+	})
+}
+
+// localSize returns the size of a local file or the total size of a directory tree.
+func localSize(p string) int64 {
+	var total int64
+	_ = filepath.WalkDir(p, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !d.IsDir() {
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	return total
+}
+
+// localTransferItems builds upload items for local paths, skipping ones that do not exist.
+func localTransferItems(paths []string) []transferItem {
+	var items []transferItem
+	for _, p := range paths {
+		if _, err := os.Stat(p); err != nil {
+			continue
+		}
+		items = append(items, transferItem{Path: p, Size: localSize(p)})
+	}
+	return items
+}
+
+// transferPollInterval is how often the bytes of the running item are measured.
+const transferPollInterval = 500 * time.Millisecond
+
+// runTransfers runs op for every item in the background while showing progress
+// in bytes, then calls onDone on the UI thread with the combined output and the
+// first error. measure, if set, returns how many bytes of the running item have
+// arrived so far; it is polled so the bar also moves within a large item.
+// Remaining items are skipped if the user cancels.
+func runTransfers(w fyne.Window, title string, items []transferItem, op func(transferItem) (string, error), measure func(transferItem) int64, onDone func(out string, err error)) {
+	var total int64
+	for _, it := range items {
+		total += max(it.Size, 1)
+	}
+	t := showTransferDialog(w, title)
+	go func() {
+		var outs []string
+		var firstErr error
+		var done int64
+		for i, it := range items {
+			if t.Cancelled() {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("%s", T("transfer_cancelled"))
+				}
+				break
+			}
+			label := fmt.Sprintf("(%d/%d) %s", i+1, len(items), it.Path)
+			t.Update(label, done, total)
+			stop := make(chan struct{})
+			polled := make(chan struct{})
+			go func(base int64) {
+				defer close(polled)
+				if measure == nil || it.Size <= 0 {
+					return
+				}
+				tick := time.NewTicker(transferPollInterval)
+				defer tick.Stop()
+				for {
+					select {
+					case <-stop:
+						return
+					case <-tick.C:
+						t.Update(label, base+min(measure(it), it.Size), total)
+					}
+				}
+			}(done)
+			out, err := op(it)
+			close(stop)
+			<-polled
+			outs = append(outs, strings.TrimSpace(out))
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+			done += max(it.Size, 1)
+		}
+		t.Update("", done, total)
+		t.Close()
+		fyne.Do(func() {
+			onDone(strings.Join(outs, "\n"), firstErr)
+		})
+	}()
+}
+
+// showUploadQueue collects several local files and folders for one upload.
+// The file picker takes one file at a time, so it is reopened in the last
+// folder for each addition. Sizes are measured in the background when a path
+// is queued; onUpload receives the queued items.
+func showUploadQueue(w fyne.Window, onUpload func(items []transferItem)) {
+	var items []transferItem
+	measuring := 0
+	var lastDir fyne.ListableURI
+	sel := -1
+	list := widget.NewList(
+		func() int { return len(items) },
+		func() fyne.CanvasObject {
+			l := widget.NewLabel("")
+			l.Truncation = fyne.TextTruncateEllipsis
+			return l
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			size := T("loading")
+			if items[i].Size >= 0 {
+				size = formatFileSize(items[i].Size)
+			}
+			o.(*widget.Label).SetText(fmt.Sprintf("%s  (%s)", items[i].Path, size))
+		},
+	)
+	list.OnSelected = func(i widget.ListItemID) { sel = i }
+	count := widget.NewLabel("")
+	var btnStart *widget.Button
+	changed := func() {
+		list.UnselectAll()
+		sel = -1
+		list.Refresh()
+		count.SetText(fmt.Sprintf(T("upload_queue_count"), len(items)))
+		if len(items) > 0 && measuring == 0 {
+			btnStart.Enable()
+		} else {
+			btnStart.Disable()
+		}
+	}
+	add := func(p string) {
+		if strings.TrimSpace(p) == "" {
+			return
+		}
+		for _, it := range items {
+			if it.Path == p {
+				return
+			}
+		}
+		items = append(items, transferItem{Path: p, Size: -1})
+		measuring++
+		if l, err := storage.ListerForURI(storage.NewFileURI(filepath.Dir(p))); err == nil {
+			lastDir = l
+		}
+		changed()
+		go func() {
+			size := localSize(p)
+			fyne.Do(func() {
+				measuring--
+				for i := range items {
+					if items[i].Path == p {
+						items[i].Size = size
+					}
+				}
+				changed()
+			})
+		}()
+	}
+	btnAddFile := widget.NewButton(T("upload_add_file"), func() {
+		fd := dialog.NewFileOpen(func(rc fyne.URIReadCloser, err error) {
+			if err != nil || rc == nil {
+				return
+			}
+			rc.Close()
+			add(rc.URI().Path())
+		}, w)
+		if lastDir != nil {
+			fd.SetLocation(lastDir)
+		}
+		fd.Show()
+	})
+	btnAddFolder := widget.NewButton(T("upload_add_folder"), func() {
+		fd := dialog.NewFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err != nil || uri == nil {
+				return
+			}
+			add(uri.Path())
+		}, w)
+		if lastDir != nil {
+			fd.SetLocation(lastDir)
+		}
+		fd.Show()
+	})
+	btnRemove := widget.NewButton(T("upload_remove"), func() {
+		if sel >= 0 && sel < len(items) {
+			items = append(items[:sel], items[sel+1:]...)
+			changed()
+		}
+	})
+
+	var d *dialog.CustomDialog
+	btnStart = widget.NewButton(T("upload"), func() {
+		d.Hide()
+		onUpload(items)
+	})
+	btnStart.Importance = widget.HighImportance
+	btnCancel := widget.NewButton(T("cancel"), func() { d.Hide() })
+	top := container.NewHBox(btnAddFile, btnAddFolder, btnRemove)
+	d = dialog.NewCustomWithoutButtons(T("upload"), container.NewBorder(top, count, nil, nil, list), w)
+	d.SetButtons([]fyne.CanvasObject{btnCancel, btnStart})
+	d.Resize(fyne.NewSize(640, 420))
+	changed()
+	d.Show()
+	btnAddFile.OnTapped()
+}
@@ -0,0 +1,141 @@
+package ui
+
+import (
+	"fmt"
+
+	"adb-gui/internal/adb"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// showTrashDialog lists the trash of the volume containing volumePath and lets the
+// user restore or permanently purge entries. onChanged is called after any change
+// so the file browser can reload.
+func showTrashDialog(w fyne.Window, mgr *adb.Manager, serial, volumePath string, onChanged func()) {
+	var entries []adb.TrashEntry
+	selected := map[string]bool{}
+	status := widget.NewLabel("")
+
+	list := widget.NewList(
+		func() int { return len(entries) },
+		func() fyne.CanvasObject {
+			return container.NewHBox(widget.NewCheck("", nil), widget.NewLabel("path"))
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			if i < 0 || i >= len(entries) {
+				return
+			}
+			e := entries[i]
+			box := o.(*fyne.Container)
+			check := box.Objects[0].(*widget.Check)
+			label := box.Objects[1].(*widget.Label)
+			label.SetText(fmt.Sprintf("%s    (%s %s)", e.OriginalPath, T("deleted_at"), e.DeletedAt.Format("2006-01-02 15:04:05")))
+			check.SetChecked(selected[e.ID])
+			check.OnChanged = func(v bool) {
+				selected[e.ID] = v
+			}
+		},
+	)
+
+	var reload func()
+	reload = func() {
+		go func() {
+			list2, out, err := mgr.ListTrash(serial, volumePath)
+			fyne.Do(func() {
+				if err != nil {
+					status.SetText(fmt.Sprintf("%s: %v %s", T("error"), err, out))
+					return
+				}
+				entries = list2
+				selected = map[string]bool{}
+				if len(entries) == 0 {
+					status.SetText(T("trash_empty"))
+				} else {
+					status.SetText(fmt.Sprintf("%d", len(entries)))
+				}
+				list.Refresh()
+			})
+		}()
+	}
+
+	getSelected := func() []adb.TrashEntry {
+		var res []adb.TrashEntry
+		for _, e := range entries {
+			if selected[e.ID] {
+				res = append(res, e)
+			}
+		}
+		return res
+	}
+
+	btnRestore := widget.NewButton(T("restore"), func() {
+		sel := getSelected()
+		if len(sel) == 0 {
+			return
+		}
+		go func() {
+			out, err := mgr.RestoreMultipleFromTrash(serial, sel)
+			fyne.Do(func() {
+				if err != nil {
+					dialog.ShowError(fmt.Errorf("%s: %v\n%s", T("restore_failed"), err, out), w)
+				}
+				reload()
+				onChanged()
+			})
+		}()
+	})
+	btnPurge := widget.NewButton(T("purge"), func() {
+		sel := getSelected()
+		if len(sel) == 0 {
+			return
+		}
+		dialog.ShowConfirm(T("purge"), fmt.Sprintf(T("confirm_delete_message"), len(sel)), func(ok bool) {
+			if !ok {
+				return
+			}
+			go func() {
+				var firstErr error
+				for _, e := range sel {
+					if _, err := mgr.PurgeTrash(serial, e); err != nil && firstErr == nil {
+						firstErr = err
+					}
+				}
+				fyne.Do(func() {
+					if firstErr != nil {
+						dialog.ShowError(fmt.Errorf("%s: %v", T("delete_failed"), firstErr), w)
+					}
+					reload()
+				})
+			}()
+		}, w)
+	})
+	btnEmpty := widget.NewButton(T("empty_trash"), func() {
+		if len(entries) == 0 {
+			return
+		}
+		dialog.ShowConfirm(T("empty_trash"), fmt.Sprintf(T("confirm_delete_message"), len(entries)), func(ok bool) {
+			if !ok {
+				return
+			}
+			go func() {
+				out, err := mgr.EmptyTrash(serial, volumePath)
+				fyne.Do(func() {
+					if err != nil {
+						dialog.ShowError(fmt.Errorf("%s: %v\n%s", T("delete_failed"), err, out), w)
+					}
+					reload()
+				})
+			}()
+		}, w)
+	})
+
+	root, _ := adb.VolumeRoot(volumePath)
+	top := container.NewHBox(widget.NewLabel(root), btnRestore, btnPurge, btnEmpty, status)
+	d := dialog.NewCustom(T("trash"), T("close"), container.NewBorder(top, nil, nil, nil, list), w)
+	d.Resize(fyne.NewSize(800, 500))
+	d.Show()
+	reload()
+}
@@ -1,10 +1,12 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
 	"image/color"
 	"log"
 	"path"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
@@ -238,22 +240,39 @@ func BuildUI(w fyne.Window, a fyne.App, mgr *adb.Manager, cfg *config.Config) {
 
 	// Right: tabs dependent on selected device
 	appsTab := buildApplicationsTab(w, mgr, selectedSerialBind, &devices)
-	storageTab := buildStorageTab(w, mgr, selectedSerialBind)
-	paramsTab := buildParametersTab(w, mgr, selectedSerialBind)
-	getVarTab := buildGetVarTab(w, mgr, selectedSerialBind)
-	cmdsTab := buildCommandsTab(w, mgr, selectedSerialBind)
+	storageTab, onStorageDrop := buildStorageTab(w, mgr, selectedSerialBind, cfg)
+	mediaTab := buildMediaTab(w, mgr, selectedSerialBind, &devices, cfg)
+	logcatTab := buildLogcatTab(w, mgr, selectedSerialBind)
+	crashesTab := buildCrashesTab(w, mgr, &devices)
+	paramsTab := buildParametersTab(w, mgr, selectedSerialBind, &devices, cfg)
+	settingsTab := buildSettingsTab(w, mgr, selectedSerialBind, &devices, cfg)
+	getVarTab := buildGetVarTab(w, mgr, selectedSerialBind, &devices, cfg)
+	cmdsTab := buildCommandsTab(w, mgr, selectedSerialBind, cfg)
+	shellTab := buildShellTab(w, mgr, selectedSerialBind, &devices, cfg)
 	fastbootTab := buildFastbootTab(w, mgr, selectedSerialBind)
 
 	rightTabs := container.NewAppTabs(
 		container.NewTabItem(T("applications"), appsTab),
 		container.NewTabItem(T("storage"), storageTab),
+		container.NewTabItem(T("media"), mediaTab),
+		container.NewTabItem(T("logcat"), logcatTab),
+		container.NewTabItem(T("crashes"), crashesTab),
 		container.NewTabItem(T("parameters"), paramsTab),
+		container.NewTabItem(T("settings_db"), settingsTab),
 		container.NewTabItem(T("getvar"), getVarTab),
 		container.NewTabItem(T("commands"), cmdsTab),
+		container.NewTabItem(T("shell"), shellTab),
 		container.NewTabItem(T("fastboot"), fastbootTab),
 	)
 	rightTabs.SetTabLocation(container.TabLocationTop)
 
+	// Files dropped from the OS are uploaded when the Storage tab is showing
+	w.SetOnDropped(func(pos fyne.Position, uris []fyne.URI) {
+		if rightTabs.Selected() != nil && rightTabs.Selected().Content == storageTab {
+			onStorageDrop(pos, uris)
+		}
+	})
+
 	// Split layout (left devices, right tabs)
 	split := container.NewHSplit(leftPanel, rightTabs)
 	split.Offset = 0.25
@@ -943,7 +962,9 @@ func refreshApps(w fyne.Window, mgr *adb.Manager, selectedSerialBind binding.Str
 }
 
 // Storage tab: list users and their default storage, browse directories
-func buildStorageTab(w fyne.Window, mgr *adb.Manager, selectedSerialBind binding.String) fyne.CanvasObject {
+// The returned function handles OS file drops (window position and URIs) while the tab is visible.
+func buildStorageTab(w fyne.Window, mgr *adb.Manager, selectedSerialBind binding.String, cfg *config.Config) (fyne.CanvasObject, func(fyne.Position, []fyne.URI)) {
+	var onDrop func(fyne.Position, []fyne.URI)
 	usersBind := binding.NewStringList()
 	files := []adb.FileEntry{}
 	selectedIndex := -1
@@ -956,6 +977,7 @@ func buildStorageTab(w fyne.Window, mgr *adb.Manager, selectedSerialBind binding
 	var curPathBind binding.String
 	var loadDir func(string)
 	var applySort func()
+	previewPane, loadPreview := buildPreviewPane(w, mgr)
 	// Apply sorting according to current sortMode
 	applySort = func() {
 		mode := sortMode
@@ -1166,6 +1188,11 @@ func buildStorageTab(w fyne.Window, mgr *adb.Manager, selectedSerialBind binding
 				loadDir(path.Join(p, files[id.Row].Name))
 			}
 		}
+		// Single click on a file shows it in the preview pane
+		if id.Row >= 0 && id.Row < len(files) && !files[id.Row].IsDir && loadPreview != nil && curPathBind != nil {
+			p, _ := curPathBind.Get()
+			loadPreview(mustGet(selectedSerialBind), path.Join(p, files[id.Row].Name))
+		}
 		// Update click tracking and always unselect to allow repeated selection events
 		selectedIndex = id.Row
 		lastClickIdx = id.Row
@@ -1263,6 +1290,7 @@ func buildStorageTab(w fyne.Window, mgr *adb.Manager, selectedSerialBind binding
 			dialog.ShowInformation(T("no_device"), T("please_select_device"), w)
 			return
 		}
+		loadPreview(serial, "")
 		go func() {
 			list, _, err := mgr.ListDir(serial, p)
 			fyne.Do(func() {
@@ -1330,9 +1358,180 @@ func buildStorageTab(w fyne.Window, mgr *adb.Manager, selectedSerialBind binding
 		navigateToPath("")
 	})
 
+	// Storage volumes (internal, SD cards, USB OTG, adopted) for quick switching
+	var volumes []adb.StorageVolume
+	volumeSelect := widget.NewSelect([]string{}, nil)
+	volumeSelect.PlaceHolder = T("select_volume")
+	volumeLabel := func(v adb.StorageVolume) string {
+		name := T("volume_" + v.Kind)
+		if v.UUID != "" && v.Kind != "emulated" {
+			name += " " + v.UUID
+		}
+		s := fmt.Sprintf("%s  %s", name, v.MountPoint)
+		if v.Total > 0 {
+			s += fmt.Sprintf("  (%s / %s %s)", formatFileSize(v.Free), formatFileSize(v.Total), T("usage_free"))
+		}
+		if !v.Mounted() {
+			s += "  [" + v.State + "]"
+		}
+		return s
+	}
+	refreshVolumes := func() {
+		serial, _ := selectedSerialBind.Get()
+		if serial == "" {
+			return
+		}
+		go func() {
+			vols, _, err := mgr.StorageVolumes(serial)
+			fyne.Do(func() {
+				if err != nil {
+					volumes = nil
+					volumeSelect.Options = []string{}
+					volumeSelect.Refresh()
+					return
+				}
+				volumes = vols
+				opts := make([]string, 0, len(vols))
+				for _, v := range vols {
+					opts = append(opts, volumeLabel(v))
+				}
+				volumeSelect.Options = opts
+				volumeSelect.Refresh()
+			})
+		}()
+	}
+	volumeSelect.OnChanged = func(sel string) {
+		for _, v := range volumes {
+			if volumeLabel(v) != sel {
+				continue
+			}
+			if !v.Mounted() || v.MountPoint == "" {
+				dialog.ShowInformation(T("select_volume"), fmt.Sprintf(T("volume_not_mounted"), v.State), w)
+				return
+			}
+			navigateToPath(v.MountPoint)
+			return
+		}
+	}
+
+	// Bookmarks persisted in config
+	bookmarkSelect := widget.NewSelect(cfg.StorageBookmarks(), nil)
+	bookmarkSelect.PlaceHolder = T("bookmarks")
+	bookmarkSelect.OnChanged = func(sel string) {
+		if sel == "" {
+			return
+		}
+		navigateToPath(sel)
+		bookmarkSelect.ClearSelected()
+	}
+	btnBookmarks := widget.NewButton(T("edit_bookmarks"), func() {
+		entry := widget.NewMultiLineEntry()
+		entry.SetText(strings.Join(cfg.StorageBookmarks(), "\n"))
+		entry.SetMinRowsVisible(8)
+		btnAddCurrent := widget.NewButton(T("bookmark_current_path"), func() {
+			cur, _ := curPathBind.Get()
+			text := strings.TrimRight(entry.Text, "\n")
+			if text != "" {
+				text += "\n"
+			}
+			entry.SetText(text + cur)
+		})
+		content := container.NewBorder(nil, btnAddCurrent, nil, nil, entry)
+		dialog.ShowCustomConfirm(T("edit_bookmarks"), T("save"), T("cancel"), content, func(ok bool) {
+			if !ok {
+				return
+			}
+			marks := []string{}
+			for _, ln := range strings.Split(entry.Text, "\n") {
+				if ln = strings.TrimSpace(ln); ln != "" {
+					marks = append(marks, ln)
+				}
+			}
+			cfg.Bookmarks = marks
+			if err := config.Save(cfg); err != nil {
+				dialog.ShowError(err, w)
+			}
+			bookmarkSelect.Options = cfg.StorageBookmarks()
+			bookmarkSelect.Refresh()
+		}, w)
+	})
+
+	// Root mode: per-device toggle that runs listing, reads, writes, deletes and transfers privileged
+	rootProviders := map[string]string{}
+	rootIndicator := widget.NewLabel("")
+	rootIndicator.Importance = widget.DangerImportance
+	rootIndicator.TextStyle = fyne.TextStyle{Bold: true}
+	rootCheck := widget.NewCheck(T("root_mode"), nil)
+	updateRootIndicator := func(serial string) {
+		if mgr.RootMode(serial) == adb.RootNone {
+			rootIndicator.SetText("")
+			return
+		}
+		rootIndicator.SetText(fmt.Sprintf(T("root_mode_active"), rootProviders[serial]))
+	}
+	setRootCheck := func(v bool) {
+		// Update without firing OnChanged
+		rootCheck.Checked = v
+		rootCheck.Refresh()
+	}
+	enableRoot := func(serial string, info adb.RootInfo) {
+		rootProviders[serial] = info.Provider
+		mgr.SetRootMode(serial, info.Method)
+		setRootCheck(true)
+		updateRootIndicator(serial)
+		cur, _ := curPathBind.Get()
+		loadDir(cur)
+	}
+	rootCheck.OnChanged = func(on bool) {
+		serial, _ := selectedSerialBind.Get()
+		if serial == "" {
+			setRootCheck(false)
+			dialog.ShowInformation(T("no_device"), T("please_select_device"), w)
+			return
+		}
+		if !on {
+			mgr.SetRootMode(serial, adb.RootNone)
+			updateRootIndicator(serial)
+			cur, _ := curPathBind.Get()
+			loadDir(cur)
+			return
+		}
+		setRootCheck(false)
+		go func() {
+			info, _ := mgr.DetectRoot(serial)
+			fyne.Do(func() {
+				if info.Method != adb.RootNone {
+					enableRoot(serial, info)
+					return
+				}
+				// No su: offer "adb root", which only works on userdebug/eng builds
+				dialog.ShowConfirm(T("root_mode"), T("root_not_available_try_adb_root"), func(ok bool) {
+					if !ok {
+						return
+					}
+					go func() {
+						out, err := mgr.RestartADBDAsRoot(serial)
+						info, _ := mgr.DetectRoot(serial)
+						fyne.Do(func() {
+							if err != nil || info.Method == adb.RootNone {
+								dialog.ShowError(fmt.Errorf("%s: %v\n%s", T("root_not_available"), err, out), w)
+								return
+							}
+							enableRoot(serial, info)
+						})
+					}()
+				}, w)
+			})
+		}()
+	}
+
 	// React to device selection changes: reload users and default path
 	selectedSerialBind.AddListener(binding.NewDataListener(func() {
+		serial, _ := selectedSerialBind.Get()
+		setRootCheck(mgr.RootMode(serial) != adb.RootNone)
+		updateRootIndicator(serial)
 		refreshUsers()
+		refreshVolumes()
 	}))
 
 	// Sorting and transfer controls
@@ -1366,56 +1565,101 @@ func buildStorageTab(w fyne.Window, mgr *adb.Manager, selectedSerialBind binding
 	// Note: Don't set initial selection here to avoid triggering callback before files are loaded
 	// Initial sorting is applied in loadDir function after files are loaded
 
+	// uploadItems pushes local files/folders into the current directory through the transfer dialog.
+	uploadItems := func(items []transferItem) {
+		serial, _ := selectedSerialBind.Get()
+		if serial == "" {
+			dialog.ShowInformation(T("no_device"), T("please_select_device"), w)
+			return
+		}
+		if len(items) == 0 {
+			dialog.ShowInformation(T("upload"), T("invalid_local_path"), w)
+			return
+		}
+		cur, _ := curPathBind.Get()
+		runTransfers(w, T("upload"), items, func(it transferItem) (string, error) {
+			return mgr.Push(serial, it.Path, cur)
+		}, func(it transferItem) int64 {
+			return mgr.RemoteSize(serial, []string{path.Join(cur, filepath.Base(it.Path))})
+		}, func(out string, e error) {
+			if e != nil {
+				dialog.ShowError(fmt.Errorf("%s: %v\n%s", T("upload_failed"), e, out), w)
+			} else {
+				dialog.ShowInformation(T("upload"), T("upload_complete"), w)
+			}
+			loadDir(cur)
+		})
+	}
+	// uploadPaths measures local paths off the UI thread, then uploads them.
+	uploadPaths := func(localPaths []string) {
+		go func() {
+			items := localTransferItems(localPaths)
+			fyne.Do(func() { uploadItems(items) })
+		}()
+	}
+
 	btnUpload := widget.NewButton(T("upload"), func() {
 		serial, _ := selectedSerialBind.Get()
 		if serial == "" {
 			dialog.ShowInformation(T("no_device"), T("please_select_device"), w)
 			return
 		}
-		fd := dialog.NewFileOpen(func(rc fyne.URIReadCloser, err error) {
+		showUploadQueue(w, uploadItems)
+	})
+
+	btnUploadFolder := widget.NewButton(T("upload_folder"), func() {
+		serial, _ := selectedSerialBind.Get()
+		if serial == "" {
+			dialog.ShowInformation(T("no_device"), T("please_select_device"), w)
+			return
+		}
+		fd := dialog.NewFolderOpen(func(uri fyne.ListableURI, err error) {
 			if err != nil {
 				dialog.ShowError(err, w)
 				return
 			}
-			if rc == nil {
-				return
-			}
-			defer rc.Close()
-			lp := rc.URI().Path()
-			if strings.TrimSpace(lp) == "" {
-				dialog.ShowInformation(T("upload"), T("invalid_local_path"), w)
+			if uri == nil {
 				return
 			}
-			cur, _ := curPathBind.Get()
-			go func() {
-				out, e := mgr.Push(serial, lp, cur)
-				fyne.Do(func() {
-					if e != nil {
-						dialog.ShowError(fmt.Errorf("%s: %v\n%s", T("upload_failed"), e, out), w)
-					} else {
-						dialog.ShowInformation(T("upload"), T("upload_complete"), w)
-						loadDir(cur)
-					}
-				})
-			}()
+			uploadPaths([]string{uri.Path()})
 		}, w)
 		fd.Show()
 	})
 
+	// onDrop handles files/folders dropped from the OS onto the file table.
+	// Dragging rows out to the OS is not possible with Fyne; see README.
+	onDrop = func(pos fyne.Position, uris []fyne.URI) {
+		drv := fyne.CurrentApp().Driver()
+		tablePos := drv.AbsolutePositionForObject(filesList)
+		tableSize := filesList.Size()
+		if pos.X < tablePos.X || pos.Y < tablePos.Y || pos.X > tablePos.X+tableSize.Width || pos.Y > tablePos.Y+tableSize.Height {
+			return
+		}
+		var localPaths []string
+		for _, u := range uris {
+			if u.Scheme() == "file" {
+				localPaths = append(localPaths, u.Path())
+			}
+		}
+		if len(localPaths) > 0 {
+			uploadPaths(localPaths)
+		}
+	}
+
 	btnDownload := widget.NewButton(T("download"), func() {
 		serial, _ := selectedSerialBind.Get()
 		if serial == "" {
 			dialog.ShowInformation(T("no_device"), T("please_select_device"), w)
 			return
 		}
-		// collect selected names
-		var names []string
+		// collect selected entries
+		var selected []adb.FileEntry
 		for _, f := range files {
 			if selectedNames[f.Name] {
-				names = append(names, f.Name)
+				selected = append(selected, f)
 			}
 		}
-		if len(names) == 0 {
+		if len(selected) == 0 {
 			dialog.ShowInformation(T("download"), T("please_select_files"), w)
 			return
 		}
@@ -1429,75 +1673,254 @@ func buildStorageTab(w fyne.Window, mgr *adb.Manager, selectedSerialBind binding
 			}
 			localDir := uri.Path()
 			cur, _ := curPathBind.Get()
-			// build remote paths
-			var remote []string
-			for _, n := range names {
-				remote = append(remote, path.Join(cur, n))
-			}
 			go func() {
-				out, e := mgr.PullMultiple(serial, remote, localDir, true)
-				fyne.Do(func() {
-					if e != nil {
-						dialog.ShowError(fmt.Errorf("%s: %v\n%s", T("download_failed"), e, out), w)
-					} else {
-						dialog.ShowInformation(T("download"), T("download_complete")+"\n"+out, w)
+				// directory sizes come from du so progress can be shown in bytes
+				var items []transferItem
+				for _, f := range selected {
+					it := transferItem{Path: path.Join(cur, f.Name), Size: f.Size}
+					if f.IsDir {
+						it.Size = mgr.RemoteSize(serial, []string{it.Path})
 					}
+					items = append(items, it)
+				}
+				fyne.Do(func() {
+					runTransfers(w, T("download"), items, func(it transferItem) (string, error) {
+						return mgr.Pull(serial, it.Path, localDir, true)
+					}, func(it transferItem) int64 {
+						return localSize(filepath.Join(localDir, path.Base(it.Path)))
+					}, func(out string, e error) {
+						if e != nil {
+							dialog.ShowError(fmt.Errorf("%s: %v\n%s", T("download_failed"), e, out), w)
+						} else {
+							dialog.ShowInformation(T("download"), T("download_complete")+"\n"+out, w)
+						}
+					})
 				})
 			}()
 		}, w)
 		dd.Show()
 	})
 
-	// Delete button for selected files/directories
-	btnDelete := widget.NewButton(T("delete"), func() {
+	// Archive transfers: one tar stream instead of a per-file adb pull/push
+	btnDownloadArchive := widget.NewButton(T("download_archive"), func() {
 		serial, _ := selectedSerialBind.Get()
 		if serial == "" {
 			dialog.ShowInformation(T("no_device"), T("please_select_device"), w)
 			return
 		}
-		// collect selected names
 		var names []string
 		for _, f := range files {
 			if selectedNames[f.Name] {
-				names = append(names, f.Name)
+				names = append(names, strings.TrimSuffix(f.Name, "/"))
 			}
 		}
 		if len(names) == 0 {
-			dialog.ShowInformation(T("delete"), T("please_select_files"), w)
+			dialog.ShowInformation(T("download_archive"), T("please_select_files"), w)
+			return
+		}
+		cur, _ := curPathBind.Get()
+		showArchiveDownload(w, mgr, serial, cur, names)
+	})
+	btnUploadArchive := widget.NewButton(T("upload_extract"), func() {
+		serial, _ := selectedSerialBind.Get()
+		if serial == "" {
+			dialog.ShowInformation(T("no_device"), T("please_select_device"), w)
+			return
+		}
+		cur, _ := curPathBind.Get()
+		showArchiveUpload(w, mgr, serial, cur, func() { loadDir(cur) })
+	})
+
+	// Undo bar shown after a safe delete; hidden again after a few seconds
+	undoLabel := widget.NewLabel("")
+	undoBtn := widget.NewButton(T("undo"), nil)
+	undoBar := container.NewHBox(undoLabel, undoBtn)
+	undoBar.Hide()
+	undoGen := 0
+	showUndo := func(serial string, entries []adb.TrashEntry) {
+		undoGen++
+		gen := undoGen
+		undoLabel.SetText(fmt.Sprintf(T("moved_to_trash"), len(entries)))
+		undoBtn.OnTapped = func() {
+			undoBar.Hide()
+			go func() {
+				out, err := mgr.RestoreMultipleFromTrash(serial, entries)
+				fyne.Do(func() {
+					if err != nil {
+						dialog.ShowError(fmt.Errorf("%s: %v\n%s", T("restore_failed"), err, out), w)
+					}
+					cur, _ := curPathBind.Get()
+					loadDir(cur)
+				})
+			}()
+		}
+		undoBar.Show()
+		go func() {
+			time.Sleep(10 * time.Second)
+			fyne.Do(func() {
+				if gen == undoGen {
+					undoBar.Hide()
+				}
+			})
+		}()
+	}
+
+	// deletePaths removes remote paths honouring safe-delete mode, then calls after
+	// (on the UI thread) with the first error so the caller can refresh its view.
+	deletePaths := func(serial string, remote []string, after func(err error)) {
+		// Split targets: user storage can go to trash, anything else is always a hard delete
+		var userPaths, systemPaths []string
+		for _, rp := range remote {
+			if adb.IsUserStorage(rp) {
+				userPaths = append(userPaths, rp)
+			} else {
+				systemPaths = append(systemPaths, rp)
+			}
+		}
+
+		doDelete := func() {
+			go func() {
+				var outs []string
+				var firstErr error
+				var trashed []adb.TrashEntry
+				hard := systemPaths
+				if cfg.SafeDelete && len(userPaths) > 0 {
+					entries, out, err := mgr.MoveMultipleToTrash(serial, userPaths)
+					trashed = entries
+					outs = append(outs, out)
+					firstErr = err
+				} else {
+					hard = append(append([]string{}, userPaths...), systemPaths...)
+				}
+				if len(hard) > 0 {
+					out, err := mgr.DeleteMultiple(serial, hard)
+					outs = append(outs, out)
+					if err != nil && firstErr == nil {
+						firstErr = err
+					}
+				}
+				fyne.Do(func() {
+					if firstErr != nil {
+						dialog.ShowError(fmt.Errorf("%s: %v\n%s", T("delete_failed"), firstErr, strings.Join(outs, "\n")), w)
+					} else if len(trashed) == 0 {
+						dialog.ShowInformation(T("delete"), T("delete_complete"), w)
+					}
+					if len(trashed) > 0 {
+						showUndo(serial, trashed)
+					}
+					if after != nil {
+						after(firstErr)
+					}
+				})
+			}()
+		}
+
+		// Paths outside user storage cannot be undone: name them explicitly
+		if len(systemPaths) > 0 {
+			dialog.ShowConfirm(
+				T("confirm_delete_system"),
+				fmt.Sprintf(T("confirm_delete_system_message"), strings.Join(systemPaths, "\n")),
+				func(confirm bool) {
+					if confirm {
+						doDelete()
+					}
+				},
+				w,
+			)
+			return
+		}
+		// Safe delete can be undone, so no confirmation is needed
+		if cfg.SafeDelete {
+			doDelete()
 			return
 		}
 
 		// Show confirmation dialog
 		confirmDialog := dialog.NewConfirm(
 			T("confirm_delete"),
-			fmt.Sprintf(T("confirm_delete_message"), len(names)),
+			fmt.Sprintf(T("confirm_delete_message"), len(remote)),
 			func(confirm bool) {
 				if confirm {
-					// Perform deletion
-					go func() {
-						cur, _ := curPathBind.Get()
-						// build remote paths
-						var remote []string
-						for _, n := range names {
-							remote = append(remote, path.Join(cur, n))
-						}
-
-						out, err := mgr.DeleteMultiple(serial, remote)
-						fyne.Do(func() {
-							if err != nil {
-								dialog.ShowError(fmt.Errorf("%s: %v\n%s", T("delete_failed"), err, out), w)
-							} else {
-								dialog.ShowInformation(T("delete"), T("delete_complete"), w)
-								// Refresh the file list
-								loadDir(cur)
-							}
-						})
-					}()
+					doDelete()
 				}
 			},
 			w,
 		)
 		confirmDialog.Show()
+	}
+
+	// Delete button for selected files/directories
+	btnDelete := widget.NewButton(T("delete"), func() {
+		serial, _ := selectedSerialBind.Get()
+		if serial == "" {
+			dialog.ShowInformation(T("no_device"), T("please_select_device"), w)
+			return
+		}
+		// collect selected names
+		var names []string
+		for _, f := range files {
+			if selectedNames[f.Name] {
+				names = append(names, f.Name)
+			}
+		}
+		if len(names) == 0 {
+			dialog.ShowInformation(T("delete"), T("please_select_files"), w)
+			return
+		}
+		cur, _ := curPathBind.Get()
+		// build remote paths
+		var remote []string
+		for _, n := range names {
+			remote = append(remote, path.Join(cur, n))
+		}
+		deletePaths(serial, remote, func(error) {
+			// Refresh the file list
+			loadDir(cur)
+		})
+	})
+
+	btnUsage := widget.NewButton(T("usage"), func() {
+		serial, _ := selectedSerialBind.Get()
+		if serial == "" {
+			dialog.ShowInformation(T("no_device"), T("please_select_device"), w)
+			return
+		}
+		cur, _ := curPathBind.Get()
+		showUsageDialog(w, mgr, serial, cur, loadDir)
+	})
+
+	btnSearch := widget.NewButton(T("search"), func() {
+		serial, _ := selectedSerialBind.Get()
+		if serial == "" {
+			dialog.ShowInformation(T("no_device"), T("please_select_device"), w)
+			return
+		}
+		cur, _ := curPathBind.Get()
+		showSearchDialog(w, mgr, serial, cur, func(remotePath string, isDir bool) {
+			if isDir {
+				loadDir(remotePath)
+				return
+			}
+			loadDir(path.Dir(remotePath))
+			loadPreview(serial, remotePath)
+		}, deletePaths)
+	})
+
+	btnTrash := widget.NewButton(T("trash"), func() {
+		serial, _ := selectedSerialBind.Get()
+		if serial == "" {
+			dialog.ShowInformation(T("no_device"), T("please_select_device"), w)
+			return
+		}
+		cur, _ := curPathBind.Get()
+		if _, ok := adb.VolumeRoot(cur); !ok {
+			dialog.ShowInformation(T("trash"), T("trash_unavailable"), w)
+			return
+		}
+		showTrashDialog(w, mgr, serial, cur, func() {
+			p, _ := curPathBind.Get()
+			loadDir(p)
+		})
 	})
 
 	// Top controls
@@ -1511,7 +1934,7 @@ func buildStorageTab(w fyne.Window, mgr *adb.Manager, selectedSerialBind binding
 		selectedNames = map[string]bool{}
 		filesList.Refresh()
 	})
-	controls := container.NewHBox(userSelect, btnUp, btnRefresh, sortSelect, btnSelAllFiles, btnSelNoneFiles, btnUpload, btnDownload, btnDelete)
+	controls := container.NewHBox(userSelect, btnUp, btnRefresh, sortSelect, btnSelAllFiles, btnSelNoneFiles, btnUpload, btnUploadFolder, btnUploadArchive, btnDownload, btnDownloadArchive, btnDelete, btnTrash, btnSearch, btnUsage)
 	// Make path entry expand to full width; keep label at left and "Open" at right
 	pathRow := container.NewBorder(nil, nil, widget.NewLabel(T("path")), btnOpen, pathEntry)
 	// Add column headers for file list with proper alignment
@@ -1548,117 +1971,20 @@ func buildStorageTab(w fyne.Window, mgr *adb.Manager, selectedSerialBind binding
 
 	top := container.NewVBox(
 		container.NewHBox(widget.NewLabel(T("user")), controls),
+		container.NewBorder(nil, nil, widget.NewLabel(T("volume")), container.NewHBox(bookmarkSelect, btnBookmarks, rootCheck, rootIndicator), volumeSelect),
 		pathRow,
 		columnHeaders,
 	)
 
-	return container.NewBorder(top, nil, nil, nil, filesList)
-}
-
-// Parameters tab: show getprop key/value
-func buildParametersTab(w fyne.Window, mgr *adb.Manager, selectedSerialBind binding.String) fyne.CanvasObject {
-	return buildKeyValueTab(w, T("parameters"), selectedSerialBind, func(serial string) (map[string]string, error) {
-		props, _, err := mgr.GetProps(serial)
-		return props, err
-	})
-}
+	// File list on the left, preview/editor on the right
+	body := container.NewHSplit(filesList, previewPane)
+	body.Offset = 0.6
 
-func buildGetVarTab(w fyne.Window, mgr *adb.Manager, selectedSerialBind binding.String) fyne.CanvasObject {
-	return buildKeyValueTab(w, T("getvar"), selectedSerialBind, func(serial string) (map[string]string, error) {
-		vars, _, err := mgr.GetVarAll(serial)
-		return vars, err
-	})
-}
-
-// buildKeyValueTab creates a generic tab for displaying key-value pairs with multi-select and copy.
-func buildKeyValueTab(w fyne.Window, title string, selectedSerialBind binding.String, fetcher func(string) (map[string]string, error)) fyne.CanvasObject {
-	var items []string
-	selectedItems := make(map[string]bool)
-
-	list := widget.NewList(
-		func() int { return len(items) },
-		func() fyne.CanvasObject {
-			return container.NewHBox(widget.NewCheck("", nil), widget.NewLabel("key = value"))
-		},
-		func(i widget.ListItemID, o fyne.CanvasObject) {
-			if i < 0 || i >= len(items) {
-				return
-			}
-			item := items[i]
-			box := o.(*fyne.Container)
-			check := box.Objects[0].(*widget.Check)
-			label := box.Objects[1].(*widget.Label)
-			label.SetText(item)
-			check.SetChecked(selectedItems[item])
-			check.OnChanged = func(checked bool) {
-				selectedItems[item] = checked
-			}
-		},
-	)
-
-	doRefresh := func() {
-		serial, _ := selectedSerialBind.Get()
-		if serial == "" {
-			items = []string{T("select_device")}
-			list.Refresh()
-			return
-		}
-		go func() {
-			data, err := fetcher(serial)
-			fyne.Do(func() {
-				if err != nil {
-					items = []string{T("error") + ": " + err.Error()}
-					list.Refresh()
-					return
-				}
-				var lines []string
-				for k, v := range data {
-					lines = append(lines, fmt.Sprintf("%s = %s", k, v))
-				}
-				sort.Strings(lines)
-				items = lines
-				if len(items) == 0 {
-					items = []string{T("no_data")}
-				}
-				selectedItems = make(map[string]bool)
-				list.Refresh()
-			})
-		}()
-	}
-
-	refreshBtn := widget.NewButton(T("refresh"), doRefresh)
-	copyBtn := widget.NewButton(T("copy_selected"), func() {
-		var toCopy []string
-		for _, item := range items {
-			if selectedItems[item] {
-				toCopy = append(toCopy, item)
-			}
-		}
-		if len(toCopy) > 0 {
-			w.Clipboard().SetContent(strings.Join(toCopy, "\n"))
-		}
-	})
-	selectAllBtn := widget.NewButton(T("select_all"), func() {
-		for _, item := range items {
-			selectedItems[item] = true
-		}
-		list.Refresh()
-	})
-	deselectAllBtn := widget.NewButton(T("deselect_all"), func() {
-		selectedItems = make(map[string]bool)
-		list.Refresh()
-	})
-
-	selectedSerialBind.AddListener(binding.NewDataListener(doRefresh))
-
-	return container.NewBorder(
-		container.NewHBox(widget.NewLabel(title), refreshBtn, copyBtn, selectAllBtn, deselectAllBtn),
-		nil, nil, nil, list,
-	)
+	return container.NewBorder(top, undoBar, nil, nil, body), onDrop
 }
 
 // Commands tab: basic device actions (reboot etc.)
-func buildCommandsTab(w fyne.Window, mgr *adb.Manager, selectedSerialBind binding.String) fyne.CanvasObject {
+func buildCommandsTab(w fyne.Window, mgr *adb.Manager, selectedSerialBind binding.String, cfg *config.Config) fyne.CanvasObject {
 	// ADB Commands
 	btnReboot := widget.NewButton(T("reboot"), func() {
 		go func() {
@@ -1699,113 +2025,119 @@ func buildCommandsTab(w fyne.Window, mgr *adb.Manager, selectedSerialBind bindin
 		}()
 	})
 
+	btnBugreport := widget.NewButton(T("bugreport"), func() {
+		serial := mustGet(selectedSerialBind)
+		if serial == "" {
+			dialog.ShowInformation(T("no_device"), T("please_select_device"), w)
+			return
+		}
+		showBugreportCapture(w, mgr, cfg, serial)
+	})
+	btnOpenBugreport := widget.NewButton(T("bugreport_open"), func() {
+		showBugreportOpen(w, cfg)
+	})
+
 	return container.NewVBox(
 		widget.NewLabelWithStyle(T("adb_commands"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
 		container.NewHBox(btnReboot, btnRebootBootloader, btnRebootRecovery),
 		container.NewHBox(btnSideload, fileSideload),
 		btnStartShizuku,
+		widget.NewSeparator(),
+		widget.NewLabelWithStyle(T("bugreport"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		container.NewHBox(btnBugreport, btnOpenBugreport),
 	)
 }
 
 func buildFastbootTab(w fyne.Window, mgr *adb.Manager, selectedSerialBind binding.String) fyne.CanvasObject {
 	// Fastboot Commands
-	btnFbReboot := widget.NewButton(T("reboot"), func() {
-		go func() {
-			out, err := mgr.ExecFastboot(mustGet(selectedSerialBind), "reboot")
-			showCmdResult(T("fastboot_reboot"), out, err, w)
-		}()
-	})
-	btnFbRebootBootloader := widget.NewButton(T("reboot_bootloader"), func() {
-		go func() {
-			out, err := mgr.ExecFastboot(mustGet(selectedSerialBind), "reboot-bootloader")
-			showCmdResult(T("fastboot_reboot_bootloader"), out, err, w)
-		}()
-	})
-	btnFbContinue := widget.NewButton(T("continue"), func() {
-		go func() {
-			out, err := mgr.ExecFastboot(mustGet(selectedSerialBind), "continue")
-			showCmdResult(T("fastboot_continue"), out, err, w)
-		}()
-	})
-	btnFbUnlock := widget.NewButton(T("oem_unlock"), func() {
-		go func() {
-			out, err := mgr.ExecFastboot(mustGet(selectedSerialBind), "oem", "unlock")
-			showCmdResult(T("fastboot_oem_unlock"), out, err, w)
-		}()
-	})
-	btnFbFlashingUnlock := widget.NewButton(T("flashing_unlock"), func() {
-		go func() {
-			out, err := mgr.ExecFastboot(mustGet(selectedSerialBind), "flashing", "unlock")
-			showCmdResult(T("fastboot_flashing_unlock"), out, err, w)
-		}()
-	})
-	fileFlash := widget.NewLabel("")
-	btnFbFlash := widget.NewButton(T("flash_partition"), func() {
-		partitionEntry := widget.NewEntry()
-		partitionEntry.PlaceHolder = T("partition_placeholder")
-		dialog.ShowForm(T("flash_partition"), T("flash"), T("cancel"), []*widget.FormItem{
-			widget.NewFormItem(T("partition_name"), partitionEntry),
-		}, func(ok bool) {
-			if !ok {
-				return
-			}
-			partition := partitionEntry.Text
-			if partition == "" {
-				return
-			}
-			dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
-				if err != nil || reader == nil {
-					return
-				}
-				path := reader.URI().Path()
-				fileFlash.SetText(fmt.Sprintf("%s: %s", partition, path))
-				go func() {
-					out, err := mgr.ExecFastboot(mustGet(selectedSerialBind), "flash", partition, path)
-					showCmdResult(T("fastboot_flash"), out, err, w)
-				}()
-			}, w)
-		}, w)
-	})
-	fileUpdate := widget.NewLabel("")
-	btnFbUpdate := widget.NewButton(T("update_from_zip"), func() {
-		dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
-			if err != nil || reader == nil {
+	// command runs a fastboot command on the selected device; ExecFastboot
+	// refuses serials that are not in fastboot mode.
+	command := func(title string, args ...string) func() {
+		return func() {
+			serial := mustGet(selectedSerialBind)
+			if serial == "" {
+				dialog.ShowInformation(T("no_device"), T("please_select_device"), w)
 				return
 			}
-			path := reader.URI().Path()
-			fileUpdate.SetText(path)
 			go func() {
-				out, err := mgr.ExecFastboot(mustGet(selectedSerialBind), "update", path)
-				showCmdResult(T("fastboot_update"), out, err, w)
+				out, err := mgr.ExecFastboot(serial, args...)
+				showCmdResult(title, out, err, w)
 			}()
-		}, w)
+		}
+	}
+	btnFbReboot := widget.NewButton(T("reboot"), command(T("fastboot_reboot"), "reboot"))
+	btnFbRebootBootloader := widget.NewButton(T("reboot_bootloader"), command(T("fastboot_reboot_bootloader"), "reboot-bootloader"))
+	btnFbContinue := widget.NewButton(T("continue"), command(T("fastboot_continue"), "continue"))
+	// guarded runs a destructive fastboot command after checking the target is
+	// in fastboot mode and the user confirmed (typing word, if set).
+	guarded := func(title, message, word string, args ...string) func() {
+		return func() {
+			serial := mustGet(selectedSerialBind)
+			withFastbootTarget(w, mgr, serial, func(info *adb.GetVarInfo) {
+				confirmDestructive(w, title, message, serial, info, word, func() {
+					go func() {
+						out, err := mgr.ExecFastboot(serial, args...)
+						showCmdResult(title, out, err, w)
+					}()
+				})
+			})
+		}
+	}
+	btnFbUnlock := widget.NewButton(T("oem_unlock"),
+		guarded(T("fastboot_oem_unlock"), T("guard_unlock_msg"), "UNLOCK", "oem", "unlock"))
+	btnFbFlashingUnlock := widget.NewButton(T("flashing_unlock"),
+		guarded(T("fastboot_flashing_unlock"), T("guard_unlock_msg"), "UNLOCK", "flashing", "unlock"))
+	slots := newSlotPanel(w, mgr, selectedSerialBind)
+	fileFlash := widget.NewLabel("")
+	btnFbFlash := widget.NewButton(T("flash_partition"), func() {
+		slots.showFlashPartition(fileFlash)
 	})
-	btnFbOemDeviceInfo := widget.NewButton(T("oem_device_info"), func() {
-		go func() {
-			out, err := mgr.ExecFastboot(mustGet(selectedSerialBind), "oem", "device-info")
-			showCmdResult(T("fastboot_oem_device_info"), out, err, w)
-		}()
+	btnFbUpdate := widget.NewButton(T("update_from_zip"), func() {
+		serial := mustGet(selectedSerialBind)
+		if serial == "" {
+			dialog.ShowInformation(T("no_device"), T("please_select_device"), w)
+			return
+		}
+		showFlashPlanner(mgr, serial)
 	})
-	btnFbOemEdl := widget.NewButton(T("oem_edl"), func() {
-		go func() {
-			out, err := mgr.ExecFastboot(mustGet(selectedSerialBind), "oem", "edl")
-			showCmdResult(T("fastboot_oem_edl"), out, err, w)
-		}()
+	btnFbPayload := widget.NewButton(T("payload_title"), func() {
+		showPayloadExtractor(slots.addImages)
+	})
+	btnFbBootPatch := widget.NewButton(T("bootpatch_title"), func() {
+		serial := mustGet(selectedSerialBind)
+		if serial == "" {
+			dialog.ShowInformation(T("no_device"), T("please_select_device"), w)
+			return
+		}
+		showBootPatcher(mgr, serial, slots.images)
 	})
+	btnFbImgTools := widget.NewButton(T("imgtools_title"), func() {
+		showImageTools(func() int64 { return slots.info.MaxDownloadSize() })
+	})
+	btnFbOemDeviceInfo := widget.NewButton(T("oem_device_info"), command(T("fastboot_oem_device_info"), "oem", "device-info"))
+	btnFbOemEdl := widget.NewButton(T("oem_edl"),
+		guarded(T("fastboot_oem_edl"), T("guard_edl_msg"), "", "oem", "edl"))
 
 	return container.NewVBox(
 		widget.NewLabelWithStyle(T("fastboot_commands"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
 		container.NewHBox(btnFbReboot, btnFbRebootBootloader, btnFbContinue),
 		container.NewHBox(btnFbUnlock, btnFbFlashingUnlock),
 		container.NewHBox(btnFbFlash, fileFlash),
-		container.NewHBox(btnFbUpdate, fileUpdate),
+		container.NewHBox(btnFbUpdate, btnFbPayload, btnFbBootPatch, btnFbImgTools),
 		container.NewHBox(btnFbOemDeviceInfo, btnFbOemEdl),
+		widget.NewSeparator(),
+		slots.content,
 	)
 }
 
 // showCmdResult displays the output of a command in a dialog.
 func showCmdResult(title, out string, err error, w fyne.Window) {
 	fyne.Do(func() {
+		var nf *adb.NotInFastbootError
+		if errors.As(err, &nf) {
+			dialog.ShowInformation(T("guard_title"), fmt.Sprintf(T("guard_not_fastboot"), nf.Serial), w)
+			return
+		}
 		if err != nil {
 			dialog.ShowError(err, w)
 			return
@@ -1865,6 +2197,20 @@ func openSettingsDialog(w fyne.Window, mgr *adb.Manager, cfg *config.Config) {
 		languageSelect.SetSelected(T("auto"))
 	}
 
+	safeDeleteCheck := widget.NewCheck(T("safe_delete"), nil)
+	safeDeleteCheck.SetChecked(cfg.SafeDelete)
+
+	mediaDirEntry := widget.NewEntry()
+	mediaDirEntry.SetText(cfg.MediaDir)
+	mediaDirEntry.SetPlaceHolder(cfg.MediaOutputDir())
+	mediaDirBrowse := widget.NewButton(T("browse"), func() {
+		dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err == nil && uri != nil {
+				mediaDirEntry.SetText(uri.Path())
+			}
+		}, w)
+	})
+
 	detectBtn := widget.NewButton(T("detect"), func() {
 		p := adb.AutoDetect()
 		if p == "" {
@@ -1924,6 +2270,8 @@ func openSettingsDialog(w fyne.Window, mgr *adb.Manager, cfg *config.Config) {
 		cfg.ADBPath = valid
 		cfg.ThemeMode = mode
 		cfg.Language = lang
+		cfg.SafeDelete = safeDeleteCheck.Checked
+		cfg.MediaDir = strings.TrimSpace(mediaDirEntry.Text)
 		if err := config.Save(cfg); err != nil {
 			dialog.ShowError(err, w)
 			return
@@ -1938,6 +2286,8 @@ func openSettingsDialog(w fyne.Window, mgr *adb.Manager, cfg *config.Config) {
 		widget.NewFormItem(T("adb_path"), pathEntry),
 		widget.NewFormItem(T("theme_mode"), themeSelect),
 		widget.NewFormItem(T("language"), languageSelect),
+		widget.NewFormItem(T("trash"), safeDeleteCheck),
+		widget.NewFormItem(T("media_folder"), container.NewBorder(nil, nil, nil, mediaDirBrowse, mediaDirEntry)),
 	)
 	actions := container.NewHBox(detectBtn, browseBtn, saveBtn)
 	content := container.NewVBox(form, actions)
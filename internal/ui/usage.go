@@ -0,0 +1,289 @@
+package ui
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"adb-gui/internal/adb"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// showUsageDialog shows volume totals (df), a directory size tree (du)
+// starting at startDir and per-app storage (dumpsys diskstats). open navigates
+// the Storage tab's file browser to a directory.
+func showUsageDialog(w fyne.Window, mgr *adb.Manager, serial, startDir string, open func(dir string)) {
+	var d dialog.Dialog
+
+	// --- Volumes ---
+	volumesBox := container.NewVBox(widget.NewLabel(T("loading")))
+	go func() {
+		vols, out, err := mgr.DiskFree(serial)
+		fyne.Do(func() {
+			volumesBox.RemoveAll()
+			if err != nil {
+				volumesBox.Add(widget.NewLabel(fmt.Sprintf("%s: %v %s", T("error"), err, out)))
+				return
+			}
+			for _, v := range vols {
+				bar := widget.NewProgressBar()
+				bar.SetValue(float64(v.Used) / float64(v.Total))
+				lbl := widget.NewLabel(fmt.Sprintf("%s  %s / %s  (%s %s)", v.MountPoint,
+					formatFileSize(v.Used), formatFileSize(v.Total), formatFileSize(v.Free), T("usage_free")))
+				volumesBox.Add(container.NewGridWithColumns(2, lbl, bar))
+			}
+		})
+	}()
+
+	// --- Directories ---
+	// The tree is rooted at curDir; children of a directory are loaded with du
+	// when its branch is first opened. loadGen drops results from an earlier
+	// root so quick Up/drill-down clicks cannot show another directory's sizes.
+	children := map[string][]adb.DirUsage{}
+	entryByPath := map[string]adb.DirUsage{}
+	loading := map[string]bool{}
+	loadGen := 0
+	var total int64
+	curDir := path.Clean(startDir)
+	dirLabel := widget.NewLabel(curDir)
+	dirStatus := widget.NewLabel("")
+	sortSelect := widget.NewSelect([]string{T("sort_size_large_to_small"), T("sort_size_small_to_large"), T("sort_alphabetical")}, nil)
+	sortSelect.SetSelected(T("sort_size_large_to_small"))
+	selected := ""
+
+	sortEntries := func(entries []adb.DirUsage) {
+		switch sortSelect.Selected {
+		case T("sort_size_small_to_large"):
+			sort.SliceStable(entries, func(i, j int) bool { return entries[i].Size < entries[j].Size })
+		case T("sort_alphabetical"):
+			sort.SliceStable(entries, func(i, j int) bool {
+				return strings.ToLower(entries[i].Path) < strings.ToLower(entries[j].Path)
+			})
+		default:
+			sort.SliceStable(entries, func(i, j int) bool { return entries[i].Size > entries[j].Size })
+		}
+	}
+
+	var dirTree *widget.Tree
+	// load fetches the direct children of dir; the root's result sets the total.
+	load := func(dir string) {
+		if loading[dir] {
+			return
+		}
+		loading[dir] = true
+		gen := loadGen
+		root := dir == curDir
+		go func() {
+			list, out, err := mgr.DirSizes(serial, dir)
+			fyne.Do(func() {
+				if gen != loadGen {
+					return
+				}
+				delete(loading, dir)
+				if err != nil {
+					if root {
+						dirStatus.SetText(fmt.Sprintf("%s: %v %s", T("error"), err, firstLine(out)))
+					}
+					return
+				}
+				sortEntries(list)
+				children[dir] = list
+				for _, e := range list {
+					entryByPath[e.Path] = e
+				}
+				if root {
+					total = 0
+					for _, e := range list {
+						total += e.Size
+					}
+					dirStatus.SetText(fmt.Sprintf("%s: %s", T("usage_total"), formatFileSize(total)))
+				}
+				dirTree.Refresh()
+			})
+		}()
+	}
+
+	dirTree = &widget.Tree{
+		ChildUIDs: func(uid widget.TreeNodeID) []widget.TreeNodeID {
+			dir := uid
+			if uid == "" {
+				dir = curDir
+			}
+			var ids []widget.TreeNodeID
+			for _, e := range children[dir] {
+				ids = append(ids, e.Path)
+			}
+			return ids
+		},
+		IsBranch: func(uid widget.TreeNodeID) bool {
+			return uid == "" || entryByPath[uid].IsDir
+		},
+		CreateNode: func(branch bool) fyne.CanvasObject {
+			name := widget.NewLabel("name")
+			name.Truncation = fyne.TextTruncateEllipsis
+			bar := widget.NewProgressBar()
+			return container.NewGridWithColumns(2, name, bar)
+		},
+		UpdateNode: func(uid widget.TreeNodeID, branch bool, o fyne.CanvasObject) {
+			e, ok := entryByPath[uid]
+			if !ok {
+				return
+			}
+			row := o.(*fyne.Container)
+			name := row.Objects[0].(*widget.Label)
+			bar := row.Objects[1].(*widget.ProgressBar)
+			label := path.Base(e.Path)
+			if e.IsDir {
+				label += "/"
+			}
+			name.SetText(fmt.Sprintf("%s  (%s)", label, formatFileSize(e.Size)))
+			if e.IsDir {
+				name.TextStyle = fyne.TextStyle{Bold: true}
+			} else {
+				name.TextStyle = fyne.TextStyle{}
+			}
+			name.Refresh()
+			if total > 0 {
+				bar.SetValue(float64(e.Size) / float64(total))
+			} else {
+				bar.SetValue(0)
+			}
+		},
+	}
+	dirTree.ExtendBaseWidget(dirTree)
+	dirTree.OnBranchOpened = func(uid widget.TreeNodeID) {
+		if _, ok := children[uid]; !ok {
+			load(uid)
+		}
+	}
+	dirTree.OnSelected = func(uid widget.TreeNodeID) {
+		selected = uid
+	}
+
+	analyze := func(dir string) {
+		loadGen++
+		curDir = path.Clean(dir)
+		dirLabel.SetText(curDir)
+		dirStatus.SetText(T("loading"))
+		children = map[string][]adb.DirUsage{}
+		entryByPath = map[string]adb.DirUsage{}
+		loading = map[string]bool{}
+		total = 0
+		selected = ""
+		dirTree.UnselectAll()
+		dirTree.CloseAllBranches()
+		dirTree.Refresh()
+		load(curDir)
+	}
+	sortSelect.OnChanged = func(string) {
+		for _, list := range children {
+			sortEntries(list)
+		}
+		dirTree.Refresh()
+	}
+
+	btnUp := widget.NewButton(T("up"), func() {
+		analyze(path.Dir(curDir))
+	})
+	btnDrill := widget.NewButton(T("usage_drill_down"), func() {
+		if e, ok := entryByPath[selected]; ok && e.IsDir {
+			analyze(e.Path)
+		}
+	})
+	btnBrowse := widget.NewButton(T("usage_open_in_browser"), func() {
+		target := curDir
+		if e, ok := entryByPath[selected]; ok {
+			target = e.Path
+			if !e.IsDir {
+				target = path.Dir(e.Path)
+			}
+		}
+		d.Hide()
+		open(target)
+	})
+	dirTop := container.NewVBox(
+		container.NewHBox(btnUp, btnDrill, btnBrowse, sortSelect, dirStatus),
+		dirLabel,
+	)
+	dirsView := container.NewBorder(dirTop, nil, nil, nil, dirTree)
+
+	// --- Apps ---
+	var apps []adb.AppStorage
+	appStatus := widget.NewLabel(T("loading"))
+	appSort := widget.NewSelect([]string{T("usage_total"), T("usage_code"), T("usage_data"), T("usage_cache")}, nil)
+	appTable := widget.NewTable(
+		func() (int, int) { return len(apps) + 1, 5 },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.TableCellID, o fyne.CanvasObject) {
+			lbl := o.(*widget.Label)
+			if id.Row == 0 {
+				lbl.TextStyle = fyne.TextStyle{Bold: true}
+				lbl.SetText([]string{T("package"), T("usage_code"), T("usage_data"), T("usage_cache"), T("usage_total")}[id.Col])
+				return
+			}
+			lbl.TextStyle = fyne.TextStyle{}
+			if id.Row-1 >= len(apps) {
+				lbl.SetText("")
+				return
+			}
+			a := apps[id.Row-1]
+			switch id.Col {
+			case 0:
+				lbl.SetText(a.Package)
+			case 1:
+				lbl.SetText(formatFileSize(a.Code))
+			case 2:
+				lbl.SetText(formatFileSize(a.Data))
+			case 3:
+				lbl.SetText(formatFileSize(a.Cache))
+			case 4:
+				lbl.SetText(formatFileSize(a.Total()))
+			}
+		},
+	)
+	appTable.SetColumnWidth(0, 320)
+	for c := 1; c < 5; c++ {
+		appTable.SetColumnWidth(c, 100)
+	}
+	appSort.OnChanged = func(s string) {
+		key := func(a adb.AppStorage) int64 { return a.Total() }
+		switch s {
+		case T("usage_code"):
+			key = func(a adb.AppStorage) int64 { return a.Code }
+		case T("usage_data"):
+			key = func(a adb.AppStorage) int64 { return a.Data }
+		case T("usage_cache"):
+			key = func(a adb.AppStorage) int64 { return a.Cache }
+		}
+		sort.SliceStable(apps, func(i, j int) bool { return key(apps[i]) > key(apps[j]) })
+		appTable.Refresh()
+	}
+	go func() {
+		list, out, err := mgr.AppStorageStats(serial)
+		fyne.Do(func() {
+			if err != nil {
+				appStatus.SetText(fmt.Sprintf("%s: %v %s", T("error"), err, firstLine(out)))
+				return
+			}
+			apps = list
+			appStatus.SetText(fmt.Sprintf(T("packages_count")+": %d", len(apps)))
+			appSort.SetSelected(T("usage_total"))
+		})
+	}()
+	appsView := container.NewBorder(container.NewHBox(widget.NewLabel(T("sort")), appSort, appStatus), nil, nil, nil, appTable)
+
+	tabs := container.NewAppTabs(
+		container.NewTabItem(T("usage_directories"), dirsView),
+		container.NewTabItem(T("applications"), appsView),
+	)
+	content := container.NewBorder(volumesBox, nil, nil, nil, tabs)
+	d = dialog.NewCustom(T("usage"), T("close"), content, w)
+	d.Resize(fyne.NewSize(950, 650))
+	d.Show()
+	analyze(curDir)
+}